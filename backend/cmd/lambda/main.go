@@ -4,6 +4,7 @@
 //   - "api" (default): Handles HTTP requests via API Gateway using Gin router
 //   - "ingestion": Performs scheduled data ingestion from external APIs
 //   - "scheduler": Executes maintenance and cleanup tasks
+//   - "enrichment": Refreshes enriched data for a bounded set of tickers
 //
 // The function is designed to be deployed as a single Lambda with different configurations
 // for different use cases, allowing for cost optimization and simplified deployment.
@@ -26,6 +27,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
@@ -41,7 +43,9 @@ import (
 	"stock-analyzer/internal/ingestion"
 	"stock-analyzer/internal/recommendation"
 	"stock-analyzer/internal/storage"
+	"stock-analyzer/pkg/clock"
 	"stock-analyzer/pkg/config"
+	"stock-analyzer/pkg/logging"
 )
 
 var (
@@ -56,49 +60,108 @@ var (
 	ingestionSvc      domain.IngestionService
 	recommendationSvc domain.RecommendationService
 	alpacaSvc         domain.AlpacaService
+
+	// maxEnrichmentTickers bounds how many tickers each ingestion or enrichment run enriches
+	maxEnrichmentTickers = config.DefaultEnrichmentMaxTickers
+
+	// schedulerClock supplies the scheduler's retention cutoff
+	schedulerClock clock.Clock = clock.Real{}
 )
 
-// init performs one-time initialization during Lambda cold start.
+// setup performs one-time initialization during Lambda cold start.
 // This includes database connection setup, service initialization,
 // and router configuration. The initialization is expensive but only
-// happens once per Lambda container lifecycle.
-func init() {
+// happens once per Lambda container lifecycle. It runs from main rather
+// than init so tests can exercise the handlers with their own services.
+func setup() {
 	// Set Gin to release mode in Lambda to reduce log verbosity
 	gin.SetMode(gin.ReleaseMode)
 
 	// Load configuration from environment variables
 	cfg := config.Load()
 
-	// Initialize database connection pool
-	// The connection will be reused across Lambda invocations
-	var err error
-	db, err = sql.Open("postgres", cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Every function type reads or writes ratings, but only the API serves Alpaca
+	// market data, so background functions may run without Alpaca keys
+	if err := cfg.ValidateRequired(true, functionNeedsAlpaca(os.Getenv("FUNCTION_TYPE"))); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
 
-	// Test database connectivity during initialization
-	// This ensures we fail fast if database is unreachable
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	if cfg.EnrichmentMaxTickers > 0 {
+		maxEnrichmentTickers = cfg.EnrichmentMaxTickers
 	}
 
-	// Initialize repositories with database connection
-	stockRepo = storage.NewPostgresRepository(db)
+	// Configure structured logging for all services
+	slog.SetDefault(logging.New(cfg.LogLevel, os.Stdout))
+
+	if cfg.UsesDatabase() {
+		// Initialize database connection pool
+		// The connection will be reused across Lambda invocations
+		var err error
+		db, err = sql.Open("postgres", cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+
+		// Test database connectivity during initialization
+		// This ensures we fail fast if database is unreachable
+		if err := db.Ping(); err != nil {
+			log.Fatalf("Failed to ping database: %v", err)
+		}
+
+		// Initialize repositories with database connection
+		postgresRepo := storage.NewPostgresRepository(db)
+		postgresRepo.SetMaxWorkers(cfg.MaxWorkers)
+		postgresRepo.SetSourcePriorities(cfg.SourcePriorities)
+		stockRepo = postgresRepo
+	} else {
+		// In-memory data only lives as long as this execution environment
+		memoryRepo := storage.NewMemoryRepository()
+		memoryRepo.SetSourcePriorities(cfg.SourcePriorities)
+		stockRepo = memoryRepo
+	}
 
 	// Initialize business services with their dependencies
-	ingestionSvc = ingestion.NewService(stockRepo, cfg.StockAPIURL, cfg.StockAPIToken)
-	recommendationSvc = recommendation.NewService(stockRepo)
-	alpacaSvc = alpaca.NewAdapter(cfg.AlpacaAPIKey, cfg.AlpacaAPISecret)
+	ingestionService := ingestion.NewService(stockRepo, cfg.StockAPIURL, cfg.StockAPIToken)
+	ingestionService.SetEnrichmentFreshness(time.Duration(cfg.EnrichmentFreshnessHours) * time.Hour)
+	ingestionService.SetPageDelay(time.Duration(cfg.IngestPageDelayMS) * time.Millisecond)
+	ingestionService.SetRetryPolicy(cfg.IngestionMaxRetries, time.Duration(cfg.IngestionBackoffBaseMs)*time.Millisecond)
+	ingestionService.SetTimeBudget(time.Duration(cfg.IngestionBudgetSeconds) * time.Second)
+	ingestionService.SetBatchSize(cfg.IngestionBatchSize)
+	ingestionService.SetMaxPageTokenLength(cfg.IngestionPageTokenMaxLen)
+	ingestionService.SetAutoEnrichNewTickers(cfg.AutoEnrichNewTickers, cfg.MaxWorkers)
+	ingestionService.SetSource(cfg.IngestionSource)
+	ingestionSvc = ingestionService
+	recommendationService := recommendation.NewService(stockRepo, cfg.CacheEnabled)
+	recommendationService.SetTargetCurrency(cfg.RatingsCurrency)
+	recommendationService.SetScoreNormalization(cfg.NormalizeScores)
+	alpacaAdapter := alpaca.NewAdapter(cfg.AlpacaAPIKey, cfg.AlpacaAPISecret, cfg.AlpacaBaseURL, cfg.AlpacaFeed)
+	alpacaAdapter.SetSnapshotTTL(time.Duration(cfg.SnapshotCacheTTLSeconds) * time.Second)
+	alpacaSvc = alpacaAdapter
+	// Ingestion-only functions run without Alpaca credentials; score from ratings alone there
+	if cfg.AlpacaAPIKey != "" {
+		recommendationService.SetAlpacaService(alpacaAdapter)
+	}
+	recommendationSvc = recommendationService
 
 	// Setup HTTP router with all handlers and middleware
-	router := api.SetupRouter(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc)
+	router := api.SetupRouter(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc, cfg, nil)
 
 	// Create Lambda adapter for Gin router
 	// This allows the Gin application to handle Lambda events
 	ginLambda = ginadapter.New(router)
 }
 
+// functionNeedsAlpaca reports whether a FUNCTION_TYPE calls the Alpaca market data
+// API. Unknown types fall through to the API handler, so they need it too.
+func functionNeedsAlpaca(functionType string) bool {
+	switch functionType {
+	case "ingestion", "scheduler", "enrichment":
+		return false
+	default:
+		return true
+	}
+}
+
 // Handler is the main AWS Lambda function handler.
 // It routes requests to different handlers based on the FUNCTION_TYPE environment variable.
 // This allows a single Lambda deployment to serve multiple purposes with different configurations.
@@ -107,6 +170,7 @@ func init() {
 //   - "api": Handles HTTP API requests via API Gateway (default)
 //   - "ingestion": Performs data ingestion from external APIs
 //   - "scheduler": Executes scheduled maintenance tasks
+//   - "enrichment": Enriches stock data for up to maxEnrichmentTickers tickers
 //
 // The handler implements the standard AWS Lambda signature and returns
 // API Gateway-compatible responses for HTTP functions.
@@ -120,6 +184,8 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 		return handleIngestion(ctx)
 	case "scheduler":
 		return handleScheduler(ctx)
+	case "enrichment":
+		return handleEnrichment(ctx)
 	default:
 		// Default to API handler for HTTP requests
 		return ginLambda.ProxyWithContext(ctx, req)
@@ -133,7 +199,8 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 // The function performs the following operations:
 //  1. Initializes ingestion service with current configuration
 //  2. Executes complete data ingestion cycle with error handling
-//  3. Returns success/failure status for monitoring
+//  3. Enriches up to maxEnrichmentTickers tickers through the ingestion service
+//  4. Returns success/failure status for monitoring
 //
 // Expected Trigger: EventBridge scheduled event
 // Timeout: 15 minutes (configurable via Lambda settings)
@@ -143,24 +210,87 @@ func handleIngestion(ctx context.Context) (events.APIGatewayProxyResponse, error
 
 	// Perform complete data ingestion cycle
 	// This includes fetching, transforming, and storing data
-	if err := ingestionSvc.IngestAllData(ctx); err != nil {
+	// A run cut short by its time budget still returns what it stored
+	result, err := ingestionSvc.IngestAllData(ctx)
+	message := "Ingestion completed successfully"
+	if err != nil && result == nil {
 		log.Printf("Ingestion failed: %v", err)
 		return api.NewErrorResponse(500, "Ingestion failed"), nil
 	}
+	if err != nil {
+		log.Printf("Ingestion stopped early: %v", err)
+		message = "Ingestion stopped early; partial results were stored"
+	} else {
+		log.Printf("Data ingestion completed successfully: fetched %d, inserted %d, skipped %d duplicates",
+			result.TotalFetched, result.Inserted, result.SkippedDuplicates)
+	}
+
+	// Enrich a bounded set of tickers; enrichment failures don't fail the ingestion run
+	tickers, err := stockRepo.GetUniqueTickers(ctx)
+	if err != nil {
+		log.Printf("Failed to load tickers for enrichment: %v", err)
+	} else if len(tickers) > 0 {
+		if len(tickers) > maxEnrichmentTickers {
+			tickers = tickers[:maxEnrichmentTickers]
+		}
+		if refreshed, err := ingestionSvc.EnrichStockData(ctx, tickers); err != nil {
+			log.Printf("Data enrichment failed: %v", err)
+		} else {
+			log.Printf("Enriched data for %d of %d tickers", refreshed, len(tickers))
+		}
+	}
+
+	return api.NewSuccessResponse(200, map[string]interface{}{
+		"message":            message,
+		"total_fetched":      result.TotalFetched,
+		"inserted":           result.Inserted,
+		"skipped_duplicates": result.SkippedDuplicates,
+	}), nil
+}
+
+// handleEnrichment refreshes enriched data for up to maxEnrichmentTickers tickers.
+// Unlike the enrichment step after ingestion, failures here fail the run so
+// the schedule's monitoring sees them.
+//
+// Expected Trigger: EventBridge scheduled event
+func handleEnrichment(ctx context.Context) (events.APIGatewayProxyResponse, error) {
+	log.Println("Starting data enrichment...")
+
+	tickers, err := stockRepo.GetUniqueTickers(ctx)
+	if err != nil {
+		log.Printf("Failed to load tickers for enrichment: %v", err)
+		return api.NewErrorResponse(500, "Enrichment failed while loading tickers"), nil
+	}
 
-	log.Println("Data ingestion completed successfully")
-	return api.NewSuccessResponse(200, map[string]string{"message": "Ingestion completed successfully"}), nil
+	if len(tickers) > maxEnrichmentTickers {
+		tickers = tickers[:maxEnrichmentTickers]
+	}
+
+	refreshed := 0
+	if len(tickers) > 0 {
+		refreshed, err = ingestionSvc.EnrichStockData(ctx, tickers)
+		if err != nil {
+			log.Printf("Data enrichment failed: %v", err)
+			return api.NewErrorResponse(500, "Enrichment failed"), nil
+		}
+	}
+
+	log.Printf("Enriched data for %d of %d tickers", refreshed, len(tickers))
+	response := map[string]interface{}{
+		"message":          "Enrichment completed successfully",
+		"tickers_enriched": refreshed,
+	}
+
+	return api.NewSuccessResponse(200, response), nil
 }
 
 // handleScheduler processes scheduled maintenance and cleanup tasks.
 // This function runs daily to perform housekeeping operations that
 // keep the system running efficiently.
 //
-// Potential tasks include:
-//   - Cleaning up old data beyond retention period
-//   - Generating daily reports and analytics
-//   - Updating cached recommendation data
-//   - Database maintenance and optimization
+// Tasks, in order:
+//   - Cleaning up enriched data beyond the 30-day retention period
+//   - Regenerating and caching recommendations so the next request is fast
 //
 // Expected Trigger: EventBridge scheduled event (daily)
 // Timeout: 5 minutes
@@ -168,7 +298,7 @@ func handleIngestion(ctx context.Context) (events.APIGatewayProxyResponse, error
 func handleScheduler(ctx context.Context) (events.APIGatewayProxyResponse, error) {
 	log.Println("Running scheduled tasks...")
 
-	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
+	thirtyDaysAgo := schedulerClock.Now().AddDate(0, 0, -30)
 
 	deletedCount, err := stockRepo.DeleteOldEnrichedData(ctx, thirtyDaysAgo)
 	if err != nil {
@@ -177,9 +307,19 @@ func handleScheduler(ctx context.Context) (events.APIGatewayProxyResponse, error
 	}
 
 	log.Printf("Scheduler successfully cleaned up %d old enriched data records.", deletedCount)
+
+	// Pre-warm the recommendations cache so the first request after a deploy is fast
+	recommendations, err := recommendationSvc.RefreshRecommendations(ctx)
+	if err != nil {
+		log.Printf("Scheduler failed to pre-warm recommendations: %v", err)
+		return api.NewErrorResponse(500, "Scheduler task failed during recommendations pre-warm"), nil
+	}
+
+	log.Printf("Scheduler pre-warmed %d recommendations.", len(recommendations))
 	response := map[string]interface{}{
-		"message":         "Scheduled tasks completed successfully",
-		"cleaned_records": deletedCount,
+		"message":                   "Scheduled tasks completed successfully",
+		"cleaned_records":           deletedCount,
+		"recommendations_generated": len(recommendations),
 	}
 
 	return api.NewSuccessResponse(200, response), nil
@@ -187,8 +327,9 @@ func handleScheduler(ctx context.Context) (events.APIGatewayProxyResponse, error
 
 // main is the Lambda entry point that starts the AWS Lambda runtime.
 // This function is called by the AWS Lambda service when the function is invoked.
-// It registers our Handler function with the Lambda runtime and begins
-// processing incoming events.
+// It initializes services, registers our Handler function with the Lambda
+// runtime, and begins processing incoming events.
 func main() {
+	setup()
 	lambda.Start(Handler)
 }
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStockRepository mocks the repository methods the Lambda handlers use;
+// calling any other method panics through the nil embedded interface
+type MockStockRepository struct {
+	domain.StockRepository
+	mock.Mock
+}
+
+func (m *MockStockRepository) DeleteOldEnrichedData(ctx context.Context, olderThan time.Time) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStockRepository) GetUniqueTickers(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockIngestionService mocks the ingestion methods the Lambda handlers use
+type MockIngestionService struct {
+	domain.IngestionService
+	mock.Mock
+}
+
+func (m *MockIngestionService) IngestAllData(ctx context.Context) (*domain.IngestionResult, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IngestionResult), args.Error(1)
+}
+
+func (m *MockIngestionService) EnrichStockData(ctx context.Context, tickers []string) (int, error) {
+	args := m.Called(ctx, tickers)
+	return args.Int(0), args.Error(1)
+}
+
+// MockRecommendationService mocks the recommendation methods the scheduler uses
+type MockRecommendationService struct {
+	domain.RecommendationService
+	mock.Mock
+}
+
+func (m *MockRecommendationService) RefreshRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
+}
+
+// useServices swaps the package-level services for the duration of a test
+func useServices(t *testing.T, repo domain.StockRepository, recSvc domain.RecommendationService) {
+	previousRepo, previousRecSvc := stockRepo, recommendationSvc
+	stockRepo, recommendationSvc = repo, recSvc
+	t.Cleanup(func() {
+		stockRepo, recommendationSvc = previousRepo, previousRecSvc
+	})
+}
+
+func TestHandleScheduler_CleansUpAndPrewarms(t *testing.T) {
+	t.Log("Testing handleScheduler: runs cleanup then pre-warms recommendations")
+	repo := &MockStockRepository{}
+	recSvc := &MockRecommendationService{}
+	useServices(t, repo, recSvc)
+
+	previousClock := schedulerClock
+	schedulerClock = clock.NewFake(time.Date(2025, 3, 31, 6, 0, 0, 0, time.UTC))
+	t.Cleanup(func() { schedulerClock = previousClock })
+
+	repo.On("DeleteOldEnrichedData", mock.Anything, time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)).Return(int64(7), nil)
+	recSvc.On("RefreshRecommendations", mock.Anything).Return([]domain.StockRecommendation{
+		{Ticker: "AAPL"}, {Ticker: "MSFT"}, {Ticker: "NVDA"},
+	}, nil)
+
+	resp, err := handleScheduler(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+	assert.Equal(t, 7.0, body["cleaned_records"])
+	assert.Equal(t, 3.0, body["recommendations_generated"])
+
+	repo.AssertExpectations(t)
+	recSvc.AssertExpectations(t)
+}
+
+func TestHandleScheduler_CleanupFailureSkipsPrewarm(t *testing.T) {
+	t.Log("Testing handleScheduler: a cleanup failure stops before pre-warming")
+	repo := &MockStockRepository{}
+	recSvc := &MockRecommendationService{}
+	useServices(t, repo, recSvc)
+
+	repo.On("DeleteOldEnrichedData", mock.Anything, mock.Anything).Return(int64(0), errors.New("database down"))
+
+	resp, err := handleScheduler(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	recSvc.AssertNotCalled(t, "RefreshRecommendations", mock.Anything)
+}
+
+func TestHandleScheduler_PrewarmFailure(t *testing.T) {
+	t.Log("Testing handleScheduler: a pre-warm failure is reported as a failed run")
+	repo := &MockStockRepository{}
+	recSvc := &MockRecommendationService{}
+	useServices(t, repo, recSvc)
+
+	repo.On("DeleteOldEnrichedData", mock.Anything, mock.Anything).Return(int64(2), nil)
+	recSvc.On("RefreshRecommendations", mock.Anything).Return(nil, errors.New("generation failed"))
+
+	resp, err := handleScheduler(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, resp.Body, "pre-warm")
+	repo.AssertExpectations(t)
+}
+
+func TestHandler_EnrichmentCapsTickers(t *testing.T) {
+	t.Log("Testing Handler: enrichment function enriches at most maxEnrichmentTickers tickers")
+	t.Setenv("FUNCTION_TYPE", "enrichment")
+	repo := &MockStockRepository{}
+	ingestion := &MockIngestionService{}
+	useServices(t, repo, nil)
+
+	previousIngestion, previousMax := ingestionSvc, maxEnrichmentTickers
+	ingestionSvc, maxEnrichmentTickers = ingestion, 2
+	t.Cleanup(func() { ingestionSvc, maxEnrichmentTickers = previousIngestion, previousMax })
+
+	repo.On("GetUniqueTickers", mock.Anything).Return([]string{"AAPL", "MSFT", "NVDA"}, nil)
+	ingestion.On("EnrichStockData", mock.Anything, []string{"AAPL", "MSFT"}).Return(1, nil)
+
+	resp, err := Handler(context.Background(), events.APIGatewayProxyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+	assert.Equal(t, 1.0, body["tickers_enriched"], "only refreshed tickers are reported")
+
+	repo.AssertExpectations(t)
+	ingestion.AssertExpectations(t)
+}
+
+func TestHandler_EnrichmentFailure(t *testing.T) {
+	t.Log("Testing Handler: enrichment errors fail the enrichment run")
+	t.Setenv("FUNCTION_TYPE", "enrichment")
+	repo := &MockStockRepository{}
+	ingestion := &MockIngestionService{}
+	useServices(t, repo, nil)
+
+	previousIngestion := ingestionSvc
+	ingestionSvc = ingestion
+	t.Cleanup(func() { ingestionSvc = previousIngestion })
+
+	repo.On("GetUniqueTickers", mock.Anything).Return([]string{"AAPL"}, nil)
+	ingestion.On("EnrichStockData", mock.Anything, []string{"AAPL"}).Return(0, errors.New("store unavailable"))
+
+	resp, err := Handler(context.Background(), events.APIGatewayProxyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	ingestion.AssertExpectations(t)
+}
+
+func TestHandleIngestion_ReportsCounts(t *testing.T) {
+	t.Log("Testing handleIngestion: response includes fetched, inserted and skipped counts")
+	repo := &MockStockRepository{}
+	ingestion := &MockIngestionService{}
+	useServices(t, repo, nil)
+
+	previousIngestion := ingestionSvc
+	ingestionSvc = ingestion
+	t.Cleanup(func() { ingestionSvc = previousIngestion })
+
+	ingestion.On("IngestAllData", mock.Anything).Return(&domain.IngestionResult{
+		TotalFetched:      120,
+		Inserted:          95,
+		SkippedDuplicates: 25,
+	}, nil)
+	repo.On("GetUniqueTickers", mock.Anything).Return([]string{}, nil)
+
+	resp, err := handleIngestion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+	assert.Equal(t, 120.0, body["total_fetched"])
+	assert.Equal(t, 95.0, body["inserted"])
+	assert.Equal(t, 25.0, body["skipped_duplicates"])
+	ingestion.AssertExpectations(t)
+}
@@ -36,6 +36,10 @@ func main() {
 	log.Println("Database setup completed successfully!")
 }
 
+// schemaVersion is the number of the latest file in migrations/; bump it with
+// every new migration so GET /api/v1/admin/schema-version reports it
+const schemaVersion = 7
+
 func runMigrations(db *sql.DB) error {
 	migrations := []string{
 		`-- Create stock_ratings table with UUID primary key to prevent hotspots
@@ -67,6 +71,40 @@ func runMigrations(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_stock_ratings_time ON stock_ratings(time DESC)`,
 
 		`CREATE INDEX IF NOT EXISTS idx_stock_ratings_ticker_time ON stock_ratings(ticker, time DESC)`,
+
+		`-- Create audit_log table for admin actions
+		CREATE TABLE IF NOT EXISTS audit_log (
+			audit_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			actor VARCHAR(255) NOT NULL,
+			action VARCHAR(100) NOT NULL,
+			target VARCHAR(500) NOT NULL,
+			status INT NOT NULL,
+			request_id VARCHAR(100),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, created_at DESC)`,
+
+		`-- Create watchlists table for per-user watched tickers
+		CREATE TABLE IF NOT EXISTS watchlists (
+			user_id VARCHAR(255) NOT NULL,
+			ticker VARCHAR(10) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (user_id, ticker)
+		)`,
+
+		`-- Record each rating's ingestion source and its priority for conflict resolution
+		ALTER TABLE stock_ratings ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT ''`,
+
+		`ALTER TABLE stock_ratings ADD COLUMN IF NOT EXISTS source_priority INT NOT NULL DEFAULT 0`,
+
+		`-- Create schema_migrations table to record the applied schema version
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
 	}
 
 	for i, migration := range migrations {
@@ -76,5 +114,13 @@ func runMigrations(db *sql.DB) error {
 		}
 	}
 
+	if _, err := db.Exec(
+		"INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT (version) DO NOTHING",
+		schemaVersion,
+	); err != nil {
+		return fmt.Errorf("failed to record schema version %d: %w", schemaVersion, err)
+	}
+	log.Printf("Schema is at version %d", schemaVersion)
+
 	return nil
 }
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"stock-analyzer/internal/alpaca"
+	"stock-analyzer/internal/api"
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/internal/ingestion"
+	"stock-analyzer/internal/recommendation"
+	"stock-analyzer/internal/storage"
+	"stock-analyzer/pkg/config"
+	"stock-analyzer/pkg/logging"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// Load configuration
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Configure structured logging for all services
+	slog.SetDefault(logging.New(cfg.LogLevel, os.Stdout))
+
+	// Initialize repositories and services using dependency injection
+	var stockRepo domain.StockRepository
+	if cfg.UsesDatabase() {
+		db, err := setupDatabase(cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		postgresRepo := storage.NewPostgresRepository(db)
+		postgresRepo.SetMaxWorkers(cfg.MaxWorkers)
+		postgresRepo.SetSourcePriorities(cfg.SourcePriorities)
+		stockRepo = postgresRepo
+	} else {
+		log.Println("Using in-memory storage; data is lost when the server stops")
+		memoryRepo := storage.NewMemoryRepository()
+		memoryRepo.SetSourcePriorities(cfg.SourcePriorities)
+		stockRepo = memoryRepo
+	}
+	ingestionSvc := ingestion.NewService(stockRepo, cfg.StockAPIURL, cfg.StockAPIToken)
+	ingestionSvc.SetEnrichmentFreshness(time.Duration(cfg.EnrichmentFreshnessHours) * time.Hour)
+	ingestionSvc.SetPageDelay(time.Duration(cfg.IngestPageDelayMS) * time.Millisecond)
+	ingestionSvc.SetRetryPolicy(cfg.IngestionMaxRetries, time.Duration(cfg.IngestionBackoffBaseMs)*time.Millisecond)
+	ingestionSvc.SetTimeBudget(time.Duration(cfg.IngestionBudgetSeconds) * time.Second)
+	ingestionSvc.SetBatchSize(cfg.IngestionBatchSize)
+	ingestionSvc.SetMaxPageTokenLength(cfg.IngestionPageTokenMaxLen)
+	ingestionSvc.SetAutoEnrichNewTickers(cfg.AutoEnrichNewTickers, cfg.MaxWorkers)
+	ingestionSvc.SetSource(cfg.IngestionSource)
+	recommendationSvc := recommendation.NewService(stockRepo, cfg.CacheEnabled)
+	recommendationSvc.SetTargetCurrency(cfg.RatingsCurrency)
+	recommendationSvc.SetScoreNormalization(cfg.NormalizeScores)
+
+	// Initialize Alpaca service
+	alpacaSvc := alpaca.NewAdapter(cfg.AlpacaAPIKey, cfg.AlpacaAPISecret, cfg.AlpacaBaseURL, cfg.AlpacaFeed)
+	alpacaSvc.SetSnapshotTTL(time.Duration(cfg.SnapshotCacheTTLSeconds) * time.Second)
+	recommendationSvc.SetAlpacaService(alpacaSvc)
+	log.Printf("Initialized Alpaca service with API key: %s", maskAPIKey(cfg.AlpacaAPIKey))
+
+	// Readiness can wait for the initial ingestion so traffic never hits an empty database
+	readiness := api.NewReadiness(cfg.ReadinessRequireIngest)
+
+	// Setup HTTP router with all services
+	router := api.SetupRouter(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc, cfg, readiness)
+
+	// Configure HTTP server. Handler deadlines are enforced per route by the
+	// router; WriteTimeout is only a backstop slightly above the longest one.
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: cfg.MaxRequestTimeout() + 5*time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	// Start server in a goroutine
+	go func() {
+		log.Printf("Starting server on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Perform initial data ingestion if database is empty
+	go func() {
+		if shouldRunInitialIngestion(stockRepo) {
+			log.Println("Starting initial data ingestion...")
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+
+			if _, err := ingestionSvc.IngestAllDataWithOptions(ctx, ingestion.IngestOptions{Diff: cfg.IngestionDiff}); err != nil {
+				log.Printf("Initial ingestion failed: %v", err)
+			} else {
+				log.Println("Initial data ingestion completed successfully")
+				readiness.MarkIngested()
+
+				// Enrich data for a few popular tickers
+				tickers, _ := stockRepo.GetUniqueTickers(ctx)
+				if len(tickers) > 0 {
+					// Limit to first 10 tickers for enrichment
+					if len(tickers) > 10 {
+						tickers = tickers[:10]
+					}
+					log.Printf("Enriching data for %d tickers...", len(tickers))
+					if refreshed, err := ingestionSvc.EnrichStockData(ctx, tickers); err != nil {
+						log.Printf("Data enrichment failed: %v", err)
+					} else {
+						log.Printf("Data enrichment completed: %d refreshed", refreshed)
+					}
+				}
+			}
+		} else {
+			// Existing data is already servable
+			readiness.MarkIngested()
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+
+	// Give outstanding requests a deadline for completion
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("Server exited")
+}
+
+// maskAPIKey keeps the first four characters of key for logging, hiding short keys entirely
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
+
+// setupDatabase initializes the database connection
+func setupDatabase(databaseURL string) (*sql.DB, error) {
+	if databaseURL == "" {
+		// For development, use a local CockroachDB instance
+		databaseURL = "postgresql://root@localhost:26257/stock_data?sslmode=disable"
+		log.Println("Using default database URL for development")
+	}
+
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Configure connection pool
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	// Test the connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Println("Database connection established successfully")
+	return db, nil
+}
+
+// shouldRunInitialIngestion checks if we need to run initial data ingestion
+func shouldRunInitialIngestion(stockRepo domain.StockRepository) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Check if we have any data in the database
+	filters := domain.FilterOptions{
+		Page:   1,
+		Limit:  1,
+		SortBy: "time",
+		Order:  domain.SortDescending,
+		Search: "",
+	}
+
+	response, err := stockRepo.GetStockRatings(ctx, filters)
+	if err != nil {
+		log.Printf("Error checking for existing data: %v", err)
+		return false
+	}
+
+	// If no data exists, run initial ingestion
+	return len(response.Data) == 0
+}
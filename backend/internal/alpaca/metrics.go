@@ -0,0 +1,24 @@
+package alpaca
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// rateLimitWaitsTotal counts calls that RateLimiter.Wait had to delay
+	rateLimitWaitsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alpaca_rate_limit_waits_total",
+			Help: "Total number of Alpaca requests delayed by the client-side rate limiter.",
+		},
+	)
+
+	// rateLimitWaitSeconds accumulates the time spent sleeping in RateLimiter.Wait
+	rateLimitWaitSeconds = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alpaca_rate_limit_wait_seconds_total",
+			Help: "Cumulative time in seconds Alpaca requests spent waiting on the client-side rate limiter.",
+		},
+	)
+)
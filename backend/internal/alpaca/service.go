@@ -2,15 +2,27 @@ package alpaca
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	_ "time/tzdata" // market hours are evaluated in America/New_York on any host
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	"stock-analyzer/pkg/config"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"golang.org/x/time/rate"
 )
 
+// ErrNoBars is returned when Alpaca has no bars for the requested window and the
+// service is not configured to treat empty windows as success
+var ErrNoBars = errors.New("no bars found")
+
 // PriceBar represents a normalized price bar for our API
 type PriceBar struct {
 	Timestamp string  `json:"timestamp"`
@@ -31,6 +43,15 @@ type Snapshot struct {
 	PrevDailyBar *PriceBar `json:"prev_daily_bar,omitempty"`
 }
 
+// CurrentPrice returns the most recent price in the snapshot with the same
+// precedence as domain.Snapshot.CurrentPrice
+func (s *Snapshot) CurrentPrice() (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	return toDomainSnapshot(s).CurrentPrice()
+}
+
 type Trade struct {
 	Timestamp string  `json:"timestamp"`
 	Price     float64 `json:"price"`
@@ -45,66 +66,153 @@ type Quote struct {
 	AskSize   int64   `json:"ask_size"`
 }
 
-// RateLimiter implements a simple rate limiter for API calls
+// defaultRateLimitBurst is how many calls may go out back to back after the
+// limiter has been idle, before the steady-state rate applies
+const defaultRateLimitBurst = 3
+
+// RateLimiter is a token bucket pacing API calls to one per delay on average,
+// while letting up to burst calls through immediately after an idle period
 type RateLimiter struct {
-	lastCall time.Time
-	mutex    sync.Mutex
-	delay    time.Duration
+	limiter *rate.Limiter
+	delay   time.Duration
+	logger  *slog.Logger
+	// waits and waitNanos are updated atomically so Stats never blocks callers
+	waits     atomic.Int64
+	waitNanos atomic.Int64
+}
+
+// RateLimiterStats reports how often and how long the rate limiter has delayed calls
+type RateLimiterStats struct {
+	Waits     int64         // Calls that had to sleep before proceeding
+	TotalWait time.Duration // Cumulative time spent sleeping
 }
 
-// NewRateLimiter creates a new rate limiter with the specified delay between calls
-func NewRateLimiter(delay time.Duration) *RateLimiter {
+// NewRateLimiter creates a rate limiter allowing one call per delay on average
+// with bursts of up to burst calls
+func NewRateLimiter(delay time.Duration, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
 	return &RateLimiter{
-		delay: delay,
+		limiter: rate.NewLimiter(rate.Every(delay), burst),
+		delay:   delay,
+		logger:  slog.Default(),
 	}
 }
 
-// Wait blocks until it's safe to make the next API call
-func (rl *RateLimiter) Wait() {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+// Wait blocks until it's safe to make the next API call. It returns the
+// context's error without consuming a token if ctx is done first.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	reservation := rl.limiter.Reserve()
+	if !reservation.OK() {
+		return errors.New("rate limiter cannot grant a call")
+	}
+
+	waitTime := reservation.Delay()
+	if waitTime <= 0 {
+		return nil
+	}
+
+	rl.logger.Debug("rate limiting alpaca request", "wait", waitTime)
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give the token back so later callers are not delayed by this one
+		reservation.Cancel()
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	rl.waits.Add(1)
+	rl.waitNanos.Add(int64(waitTime))
+	rateLimitWaitsTotal.Inc()
+	rateLimitWaitSeconds.Add(waitTime.Seconds())
+	return nil
+}
 
-	elapsed := time.Since(rl.lastCall)
-	if elapsed < rl.delay {
-		waitTime := rl.delay - elapsed
-		fmt.Printf("⏳ Rate limiting: waiting %v before next API call\n", waitTime)
-		time.Sleep(waitTime)
+// Stats returns the number of delayed calls and the cumulative wait so far
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	return RateLimiterStats{
+		Waits:     rl.waits.Load(),
+		TotalWait: time.Duration(rl.waitNanos.Load()),
 	}
-	rl.lastCall = time.Now()
 }
 
 // Service handles Alpaca API interactions using the official SDK
 type Service struct {
 	client      *marketdata.Client
 	rateLimiter *RateLimiter
+	logger      *slog.Logger
+	// emptyBarsOK returns an empty slice instead of ErrNoBars for windows
+	// without trading data, leaving the caller to decide how to report it
+	emptyBarsOK bool
+	feed        marketdata.Feed
+	clock       clock.Clock
 }
 
-// NewService creates a new Alpaca service with rate limiting
-func NewService(apiKey, apiSecret string) *Service {
+// parseFeed maps a configured feed name to an Alpaca data feed. Unknown names fall
+// back to IEX, which every Alpaca account can access.
+func parseFeed(feed string, logger *slog.Logger) marketdata.Feed {
+	switch strings.ToLower(strings.TrimSpace(feed)) {
+	case "", marketdata.IEX:
+		return marketdata.IEX
+	case marketdata.SIP:
+		return marketdata.SIP
+	default:
+		logger.Warn("unknown alpaca feed, falling back to iex", "feed", feed)
+		return marketdata.IEX
+	}
+}
+
+// NewService creates a new Alpaca service with rate limiting. baseURL points the
+// client at the market data API (empty uses the production endpoint) and feed
+// selects the market data feed ("iex" or "sip").
+func NewService(apiKey, apiSecret, baseURL, feed string) *Service {
+	if baseURL == "" {
+		baseURL = config.DefaultAlpacaBaseURL
+	}
+
 	// Create Alpaca client using official SDK
 	alpacaClient := marketdata.NewClient(marketdata.ClientOpts{
 		APIKey:    apiKey,
 		APISecret: apiSecret,
-		BaseURL:   "https://data.alpaca.markets",
+		BaseURL:   baseURL,
 	})
 
 	return &Service{
 		client:      alpacaClient,
-		rateLimiter: NewRateLimiter(250 * time.Millisecond), // 4 requests per second max
+		rateLimiter: NewRateLimiter(250*time.Millisecond, defaultRateLimitBurst), // 4 requests per second on average
+		logger:      slog.Default(),
+		feed:        parseFeed(feed, slog.Default()),
+		clock:       clock.Real{},
 	}
 }
 
+// SetClock replaces the clock used for market hours and recent-bar windows
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetLogger replaces the logger used by the service and its rate limiter
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+	s.rateLimiter.logger = logger
+}
+
+// SetEmptyBarsAsSuccess controls whether a window without bars returns an empty
+// slice (true) or ErrNoBars (false, the default)
+func (s *Service) SetEmptyBarsAsSuccess(enabled bool) {
+	s.emptyBarsOK = enabled
+}
+
 // newTestService creates a new service instance for testing purposes, allowing
 // the API base URL to be overridden to point to a mock server.
 func newTestService(baseURL string) *Service {
-	client := marketdata.NewClient(marketdata.ClientOpts{
-		BaseURL: baseURL,
-	})
-
-	return &Service{
-		client:      client,
-		rateLimiter: NewRateLimiter(1 * time.Millisecond), // Use a very short delay for tests
-	}
+	service := NewService("", "", baseURL, string(marketdata.IEX))
+	service.rateLimiter = NewRateLimiter(1*time.Millisecond, defaultRateLimitBurst) // Use a very short delay for tests
+	return service
 }
 
 // parseTimeFrame converts string timeframe to Alpaca TimeFrame
@@ -134,10 +242,12 @@ func (s *Service) parseTimeFrame(timeframe string) marketdata.TimeFrame {
 // GetHistoricalBars fetches historical price data from Alpaca API with rate limiting
 func (s *Service) GetHistoricalBars(ctx context.Context, symbol string, timeframe string, start, end time.Time) ([]PriceBar, error) {
 	// Apply rate limiting
-	s.rateLimiter.Wait()
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for alpaca rate limiter: %w", err)
+	}
 
-	fmt.Printf("🔸 ALPACA SERVICE: GetHistoricalBars called for %s (%s) from %s to %s (%.1f hours) - WITH RATE LIMITING\n",
-		symbol, timeframe, start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), end.Sub(start).Hours())
+	s.logger.Debug("fetching historical bars",
+		"symbol", symbol, "timeframe", timeframe, "start", start, "end", end)
 	return s.getAlpacaBars(ctx, symbol, timeframe, start, end)
 }
 
@@ -151,23 +261,26 @@ func (s *Service) getAlpacaBars(ctx context.Context, symbol string, timeframe st
 		TimeFrame: tf,
 		Start:     start,
 		End:       end,
-		Feed:      marketdata.IEX, // Use IEX feed for better reliability
+		Feed:      s.feed,
 	}
 
-	fmt.Printf("🔸 ALPACA API: Making %s request for %s from %s to %s (%.1f hours)\n",
-		timeframe, symbol, start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), end.Sub(start).Hours())
-
 	// Get bars using official SDK (single symbol)
 	bars, err := s.client.GetBars(symbol, req)
 	if err != nil {
-		fmt.Printf("🔴 Alpaca API error for %s (%s): %v\n", symbol, timeframe, err)
+		s.logger.Error("alpaca bars request failed", "symbol", symbol, "timeframe", timeframe, "error", err)
 		return nil, fmt.Errorf("failed to get bars from Alpaca: %w", err)
 	}
 
 	if len(bars) == 0 {
-		fmt.Printf("No %s bars returned from Alpaca for %s between %s and %s\n",
-			timeframe, symbol, start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"))
-		return []PriceBar{}, fmt.Errorf("no bars found for symbol %s in date range", symbol)
+		if s.emptyBarsOK {
+			s.logger.Debug("alpaca returned no bars",
+				"symbol", symbol, "timeframe", timeframe, "start", start, "end", end)
+			return []PriceBar{}, nil
+		}
+
+		s.logger.Warn("alpaca returned no bars",
+			"symbol", symbol, "timeframe", timeframe, "start", start, "end", end)
+		return []PriceBar{}, fmt.Errorf("%w for symbol %s in date range", ErrNoBars, symbol)
 	}
 
 	// Convert to our format
@@ -183,20 +296,22 @@ func (s *Service) getAlpacaBars(ctx context.Context, symbol string, timeframe st
 		}
 	}
 
-	fmt.Printf("✅ Alpaca SUCCESS: returned %d %s bars for %s (requested %s to %s, %.1f hours)\n",
-		len(priceBars), timeframe, symbol, start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), end.Sub(start).Hours())
+	s.logger.Info("fetched alpaca bars",
+		"symbol", symbol, "timeframe", timeframe, "count", len(priceBars), "start", start, "end", end)
 	return priceBars, nil
 }
 
 // GetSnapshot fetches current market snapshot for a symbol
 func (s *Service) GetSnapshot(ctx context.Context, symbol string) (*Snapshot, error) {
 	// Apply rate limiting
-	s.rateLimiter.Wait()
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for alpaca rate limiter: %w", err)
+	}
 
-	fmt.Printf("🔸 ALPACA SERVICE: GetSnapshot called for %s\n", symbol)
+	s.logger.Debug("fetching snapshot", "symbol", symbol)
 
 	req := marketdata.GetSnapshotRequest{
-		Feed: marketdata.IEX,
+		Feed: s.feed,
 	}
 
 	snapshot, err := s.client.GetSnapshot(symbol, req)
@@ -208,7 +323,45 @@ func (s *Service) GetSnapshot(ctx context.Context, symbol string) (*Snapshot, er
 		return nil, fmt.Errorf("no snapshot data available for symbol %s", symbol)
 	}
 
-	// Convert to our format
+	return convertSnapshot(symbol, snapshot), nil
+}
+
+// GetSnapshots fetches current market snapshots for several symbols in a single
+// request. Symbols without snapshot data are omitted from the result.
+func (s *Service) GetSnapshots(ctx context.Context, symbols []string) (map[string]*Snapshot, error) {
+	if len(symbols) == 0 {
+		return map[string]*Snapshot{}, nil
+	}
+
+	// Apply rate limiting
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for alpaca rate limiter: %w", err)
+	}
+
+	s.logger.Debug("fetching snapshots", "symbols", symbols)
+
+	req := marketdata.GetSnapshotRequest{
+		Feed: s.feed,
+	}
+
+	snapshots, err := s.client.GetSnapshots(symbols, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots from Alpaca: %w", err)
+	}
+
+	result := make(map[string]*Snapshot, len(snapshots))
+	for symbol, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+		result[symbol] = convertSnapshot(symbol, snapshot)
+	}
+
+	return result, nil
+}
+
+// convertSnapshot converts an SDK snapshot to our format
+func convertSnapshot(symbol string, snapshot *marketdata.Snapshot) *Snapshot {
 	result := &Snapshot{
 		Symbol: symbol,
 	}
@@ -234,70 +387,155 @@ func (s *Service) GetSnapshot(ctx context.Context, symbol string) (*Snapshot, er
 	}
 
 	// Convert bars if available
-	if snapshot.MinuteBar != nil {
-		result.MinuteBar = &PriceBar{
-			Timestamp: snapshot.MinuteBar.Timestamp.Format(time.RFC3339),
-			Open:      snapshot.MinuteBar.Open,
-			High:      snapshot.MinuteBar.High,
-			Low:       snapshot.MinuteBar.Low,
-			Close:     snapshot.MinuteBar.Close,
-			Volume:    int64(snapshot.MinuteBar.Volume),
-		}
-	}
+	result.MinuteBar = convertBar(snapshot.MinuteBar)
+	result.DailyBar = convertBar(snapshot.DailyBar)
+	result.PrevDailyBar = convertBar(snapshot.PrevDailyBar)
 
-	if snapshot.DailyBar != nil {
-		result.DailyBar = &PriceBar{
-			Timestamp: snapshot.DailyBar.Timestamp.Format(time.RFC3339),
-			Open:      snapshot.DailyBar.Open,
-			High:      snapshot.DailyBar.High,
-			Low:       snapshot.DailyBar.Low,
-			Close:     snapshot.DailyBar.Close,
-			Volume:    int64(snapshot.DailyBar.Volume),
-		}
-	}
+	return result
+}
 
-	if snapshot.PrevDailyBar != nil {
-		result.PrevDailyBar = &PriceBar{
-			Timestamp: snapshot.PrevDailyBar.Timestamp.Format(time.RFC3339),
-			Open:      snapshot.PrevDailyBar.Open,
-			High:      snapshot.PrevDailyBar.High,
-			Low:       snapshot.PrevDailyBar.Low,
-			Close:     snapshot.PrevDailyBar.Close,
-			Volume:    int64(snapshot.PrevDailyBar.Volume),
-		}
+// convertBar converts an SDK bar to our format, or returns nil if bar is nil
+func convertBar(bar *marketdata.Bar) *PriceBar {
+	if bar == nil {
+		return nil
 	}
 
-	return result, nil
+	return &PriceBar{
+		Timestamp: bar.Timestamp.Format(time.RFC3339),
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    int64(bar.Volume),
+	}
 }
 
 // GetRecentBars fetches the most recent bars for a symbol (convenience method)
 func (s *Service) GetRecentBars(ctx context.Context, symbol string) ([]PriceBar, error) {
-	end := time.Now()
+	end := s.clock.Now()
 	start := end.Add(-24 * time.Hour)
 	return s.GetHistoricalBars(ctx, symbol, "1Hour", start, end)
 }
 
-// IsMarketHours checks if the current time is during market hours
+// marketLocation is the time zone US market hours are defined in
+var marketLocation = mustLoadLocation("America/New_York")
+
+// mustLoadLocation loads a time zone from the embedded tzdata
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("loading time zone %s: %v", name, err))
+	}
+	return location
+}
+
+// IsMarketHours checks if the current time is during regular US market hours
+// (9:30 AM - 4:00 PM ET, Monday-Friday). Market holidays are not considered.
 func (s *Service) IsMarketHours() bool {
-	now := time.Now()
-	// Simple US market hours check (9:30 AM - 4:00 PM ET, Monday-Friday)
-	// This is a simplified version; production code might use more sophisticated timezone handling
-	hour := now.Hour()
+	now := s.clock.Now().In(marketLocation)
 	weekday := now.Weekday()
+	if weekday < time.Monday || weekday > time.Friday {
+		return false
+	}
 
-	return weekday >= time.Monday && weekday <= time.Friday && hour >= 9 && hour < 16
+	minutes := now.Hour()*60 + now.Minute()
+	return minutes >= 9*60+30 && minutes < 16*60
 }
 
+// defaultSnapshotTTL is how long the adapter serves a cached snapshot
+const defaultSnapshotTTL = time.Minute
+
 // Adapter implements domain.AlpacaService interface
 type Adapter struct {
-	service *Service
+	service   *Service
+	snapshots *snapshotCache
+}
+
+// snapshotCache holds recently fetched snapshots so repeated reads skip the API
+type snapshotCache struct {
+	entries map[string]cachedSnapshot
+	mutex   sync.RWMutex
+	ttl     time.Duration
+}
+
+// cachedSnapshot is a snapshot and the time it was fetched
+type cachedSnapshot struct {
+	snapshot  *domain.Snapshot
+	fetchedAt time.Time
 }
 
-// NewAdapter creates a new adapter that implements domain.AlpacaService
-func NewAdapter(apiKey, apiSecret string) *Adapter {
+// NewAdapter creates a new adapter that implements domain.AlpacaService. Empty bar
+// windows are returned as empty slices so handlers decide between 404 and 200.
+func NewAdapter(apiKey, apiSecret, baseURL, feed string) *Adapter {
+	service := NewService(apiKey, apiSecret, baseURL, feed)
+	service.SetEmptyBarsAsSuccess(true)
+	return newAdapter(service)
+}
+
+// newAdapter wraps an existing service, allowing tests to supply one pointed at a mock server
+func newAdapter(service *Service) *Adapter {
 	return &Adapter{
-		service: NewService(apiKey, apiSecret),
+		service: service,
+		snapshots: &snapshotCache{
+			entries: make(map[string]cachedSnapshot),
+			ttl:     defaultSnapshotTTL,
+		},
+	}
+}
+
+// SetClock replaces the clock used by the underlying service
+func (a *Adapter) SetClock(c clock.Clock) {
+	a.service.SetClock(c)
+}
+
+// SetLogger replaces the logger used by the underlying service
+func (a *Adapter) SetLogger(logger *slog.Logger) {
+	a.service.SetLogger(logger)
+}
+
+// SetEmptyBarsAsSuccess controls whether windows without bars are returned as
+// empty slices or as ErrNoBars errors
+func (a *Adapter) SetEmptyBarsAsSuccess(enabled bool) {
+	a.service.SetEmptyBarsAsSuccess(enabled)
+}
+
+// SetSnapshotTTL sets how long snapshots are served from cache; zero disables caching
+func (a *Adapter) SetSnapshotTTL(ttl time.Duration) {
+	a.snapshots.mutex.Lock()
+	defer a.snapshots.mutex.Unlock()
+	a.snapshots.ttl = ttl
+}
+
+// snapshotTTL returns the configured cache TTL
+func (a *Adapter) snapshotTTL() time.Duration {
+	a.snapshots.mutex.RLock()
+	defer a.snapshots.mutex.RUnlock()
+	return a.snapshots.ttl
+}
+
+// get returns a cached snapshot if it is younger than both the TTL and maxAge
+func (c *snapshotCache) get(symbol string, maxAge time.Duration) (*domain.Snapshot, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[symbol]
+	if !exists || time.Since(entry.fetchedAt) >= min(c.ttl, maxAge) {
+		return nil, false
 	}
+
+	return entry.snapshot, true
+}
+
+// put stores a freshly fetched snapshot
+func (c *snapshotCache) put(symbol string, snapshot *domain.Snapshot) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.entries[symbol] = cachedSnapshot{snapshot: snapshot, fetchedAt: time.Now()}
 }
 
 // GetHistoricalBars implements domain.AlpacaService
@@ -322,8 +560,18 @@ func (a *Adapter) GetHistoricalBars(ctx context.Context, symbol string, timefram
 	return domainBars, nil
 }
 
-// GetSnapshot implements domain.AlpacaService
+// GetSnapshot implements domain.AlpacaService, serving recently fetched snapshots from
+// cache. A domain.WithMaxSnapshotAge bound on ctx shortens how long a cached entry is used.
 func (a *Adapter) GetSnapshot(ctx context.Context, symbol string) (*domain.Snapshot, error) {
+	maxAge, bounded := domain.MaxSnapshotAge(ctx)
+	if !bounded {
+		maxAge = a.snapshotTTL()
+	}
+
+	if cached, ok := a.snapshots.get(symbol, maxAge); ok {
+		return cached, nil
+	}
+
 	snapshot, err := a.service.GetSnapshot(ctx, symbol)
 	if err != nil {
 		return nil, err
@@ -333,6 +581,32 @@ func (a *Adapter) GetSnapshot(ctx context.Context, symbol string) (*domain.Snaps
 		return nil, nil
 	}
 
+	domainSnapshot := toDomainSnapshot(snapshot)
+	a.snapshots.put(symbol, domainSnapshot)
+
+	return domainSnapshot, nil
+}
+
+// GetSnapshots implements domain.AlpacaService. Every fetched snapshot is cached,
+// so this also warms the cache for subsequent GetSnapshot calls.
+func (a *Adapter) GetSnapshots(ctx context.Context, symbols []string) (map[string]*domain.Snapshot, error) {
+	snapshots, err := a.service.GetSnapshots(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*domain.Snapshot, len(snapshots))
+	for symbol, snapshot := range snapshots {
+		domainSnapshot := toDomainSnapshot(snapshot)
+		a.snapshots.put(symbol, domainSnapshot)
+		result[symbol] = domainSnapshot
+	}
+
+	return result, nil
+}
+
+// toDomainSnapshot converts a service snapshot to the domain type
+func toDomainSnapshot(snapshot *Snapshot) *domain.Snapshot {
 	domainSnapshot := &domain.Snapshot{
 		Symbol: snapshot.Symbol,
 	}
@@ -355,40 +629,27 @@ func (a *Adapter) GetSnapshot(ctx context.Context, symbol string) (*domain.Snaps
 		}
 	}
 
-	if snapshot.MinuteBar != nil {
-		domainSnapshot.MinuteBar = &domain.PriceBar{
-			Timestamp: snapshot.MinuteBar.Timestamp,
-			Open:      snapshot.MinuteBar.Open,
-			High:      snapshot.MinuteBar.High,
-			Low:       snapshot.MinuteBar.Low,
-			Close:     snapshot.MinuteBar.Close,
-			Volume:    snapshot.MinuteBar.Volume,
-		}
-	}
+	domainSnapshot.MinuteBar = toDomainBar(snapshot.MinuteBar)
+	domainSnapshot.DailyBar = toDomainBar(snapshot.DailyBar)
+	domainSnapshot.PrevDailyBar = toDomainBar(snapshot.PrevDailyBar)
 
-	if snapshot.DailyBar != nil {
-		domainSnapshot.DailyBar = &domain.PriceBar{
-			Timestamp: snapshot.DailyBar.Timestamp,
-			Open:      snapshot.DailyBar.Open,
-			High:      snapshot.DailyBar.High,
-			Low:       snapshot.DailyBar.Low,
-			Close:     snapshot.DailyBar.Close,
-			Volume:    snapshot.DailyBar.Volume,
-		}
-	}
+	return domainSnapshot
+}
 
-	if snapshot.PrevDailyBar != nil {
-		domainSnapshot.PrevDailyBar = &domain.PriceBar{
-			Timestamp: snapshot.PrevDailyBar.Timestamp,
-			Open:      snapshot.PrevDailyBar.Open,
-			High:      snapshot.PrevDailyBar.High,
-			Low:       snapshot.PrevDailyBar.Low,
-			Close:     snapshot.PrevDailyBar.Close,
-			Volume:    snapshot.PrevDailyBar.Volume,
-		}
+// toDomainBar converts a service bar to the domain type, or returns nil if bar is nil
+func toDomainBar(bar *PriceBar) *domain.PriceBar {
+	if bar == nil {
+		return nil
 	}
 
-	return domainSnapshot, nil
+	return &domain.PriceBar{
+		Timestamp: bar.Timestamp,
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    bar.Volume,
+	}
 }
 
 // GetRecentBars implements domain.AlpacaService
@@ -1,13 +1,21 @@
 package alpaca
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,32 +31,196 @@ func setupTestServer(t *testing.T, handler http.HandlerFunc) (*Service, *httptes
 
 func TestNewService(t *testing.T) {
 	t.Log("Testing NewService: initialization")
-	service := NewService("test-key", "test-secret")
+	service := NewService("test-key", "test-secret", "", "iex")
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.client)
 	assert.NotNil(t, service.rateLimiter)
 	assert.Equal(t, 250*time.Millisecond, service.rateLimiter.delay)
+	assert.Equal(t, marketdata.IEX, service.feed)
+}
+
+func TestNewService_ConfiguredBaseURL(t *testing.T) {
+	t.Log("Testing NewService: requests go to the configured base URL with credentials")
+
+	var requestPath, keyHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		keyHeader = r.Header.Get("APCA-API-KEY-ID")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"AAPL": {"latestTrade": {"t": "2024-01-01T15:00:00Z", "p": 150.5, "s": 100}}}`)
+	}))
+	defer server.Close()
+
+	service := NewService("sandbox-key", "sandbox-secret", server.URL, "iex")
+	service.rateLimiter = NewRateLimiter(1*time.Millisecond, 1)
+
+	snapshot, err := service.GetSnapshot(context.Background(), "AAPL")
+	require.NoError(t, err)
+	require.NotNil(t, snapshot.LatestTrade)
+	assert.Equal(t, 150.5, snapshot.LatestTrade.Price)
+	assert.Equal(t, "/v2/stocks/snapshots", requestPath)
+	assert.Equal(t, "sandbox-key", keyHeader)
+}
+
+func TestParseFeed(t *testing.T) {
+	t.Log("Testing parseFeed: known feeds map directly, unknown ones fall back to IEX")
+
+	assert.Equal(t, marketdata.IEX, parseFeed("", slog.Default()))
+	assert.Equal(t, marketdata.IEX, parseFeed("iex", slog.Default()))
+	assert.Equal(t, marketdata.SIP, parseFeed("SIP", slog.Default()))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	assert.Equal(t, marketdata.IEX, parseFeed("otc", logger))
+	assert.Contains(t, buf.String(), "unknown alpaca feed")
+}
+
+func TestGetHistoricalBars_ConfiguredFeed(t *testing.T) {
+	t.Log("Testing GetHistoricalBars and GetSnapshot: requests carry the configured feed")
+
+	var feeds []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feeds = append(feeds, r.URL.Query().Get("feed"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v2/stocks/snapshots" {
+			fmt.Fprint(w, `{"AAPL": {"latestTrade": {"t": "2023-01-01T10:00:00Z", "p": 150.0, "s": 100}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"bars": {"AAPL": [{"t": "2023-01-01T00:00:00Z", "o": 1, "h": 1, "l": 1, "c": 1, "v": 1}]}, "next_page_token": null}`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	service.feed = parseFeed("sip", service.logger)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.GetHistoricalBars(context.Background(), "AAPL", "1Day", start, end)
+	require.NoError(t, err)
+	_, err = service.GetSnapshot(context.Background(), "AAPL")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sip", "sip"}, feeds)
+}
+
+func TestRateLimiter_Burst(t *testing.T) {
+	t.Log("Testing RateLimiter: an idle limiter lets a burst through, then delays")
+	delay := 100 * time.Millisecond
+	rateLimiter := NewRateLimiter(delay, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rateLimiter.Wait(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, int64(0), rateLimiter.Stats().Waits)
+
+	// The bucket is empty, so the next call waits for a token
+	start = time.Now()
+	require.NoError(t, rateLimiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), delay-20*time.Millisecond)
+	assert.Equal(t, int64(1), rateLimiter.Stats().Waits)
 }
 
-func TestRateLimiter_Wait(t *testing.T) {
-	t.Log("Testing RateLimiter: ensures delay between calls")
-	delay := 50 * time.Millisecond
-	rateLimiter := NewRateLimiter(delay)
+func TestRateLimiter_SteadyStateRate(t *testing.T) {
+	t.Log("Testing RateLimiter: after the burst, calls are spaced by the delay")
+	delay := 20 * time.Millisecond
+	rateLimiter := NewRateLimiter(delay, 2)
 
-	// First call should have no delay
-	start1 := time.Now()
-	rateLimiter.Wait()
-	assert.Less(t, time.Since(start1), 10*time.Millisecond)
+	// Drain the burst
+	require.NoError(t, rateLimiter.Wait(context.Background()))
+	require.NoError(t, rateLimiter.Wait(context.Background()))
 
-	// Second call should be delayed
-	start2 := time.Now()
-	rateLimiter.Wait()
-	assert.GreaterOrEqual(t, time.Since(start2), delay-10*time.Millisecond)
+	const calls = 5
+	start := time.Now()
+	for i := 0; i < calls; i++ {
+		require.NoError(t, rateLimiter.Wait(context.Background()))
+	}
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, calls*delay-10*time.Millisecond)
+	assert.Less(t, elapsed, calls*delay+100*time.Millisecond)
+}
+
+func TestRateLimiter_ContextCancelled(t *testing.T) {
+	t.Log("Testing RateLimiter: a cancelled context stops the wait without using a token")
+	rateLimiter := NewRateLimiter(time.Hour, 1)
+	require.NoError(t, rateLimiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := rateLimiter.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int64(0), rateLimiter.Stats().Waits)
+}
+
+func TestGetSnapshot_CancelledDuringRateLimitWait(t *testing.T) {
+	t.Log("Testing GetSnapshot: cancelling the context mid-wait returns promptly without calling Alpaca")
+
+	requests := 0
+	service, server := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"AAPL": {"latestTrade": {"t": "2024-01-01T15:00:00Z", "p": 150.5, "s": 100}}}`)
+	})
+	defer server.Close()
+
+	// One call per minute: the second request has to wait
+	service.rateLimiter = NewRateLimiter(time.Minute, 1)
+	_, err := service.GetSnapshot(context.Background(), "AAPL")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err = service.GetSnapshot(ctx, "AAPL")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, requests)
+}
+
+func TestRateLimiter_StatsUnderConcurrency(t *testing.T) {
+	t.Log("Testing RateLimiter: concurrent callers are counted once per throttled call")
+	delay := 20 * time.Millisecond
+	const burst = 2
+	rateLimiter := NewRateLimiter(delay, burst)
+
+	waitsBefore := testutil.ToFloat64(rateLimitWaitsTotal)
+
+	const callers = 10
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			assert.NoError(t, rateLimiter.Wait(context.Background()))
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// Everyone reserves at once: the burst goes through, the rest are throttled
+	stats := rateLimiter.Stats()
+	assert.Equal(t, int64(callers-burst), stats.Waits)
+	assert.Greater(t, stats.TotalWait, time.Duration(0))
+	assert.Equal(t, waitsBefore+float64(callers-burst), testutil.ToFloat64(rateLimitWaitsTotal))
 }
 
 func TestParseTimeFrame(t *testing.T) {
 	t.Log("Testing utility: parseTimeFrame")
-	service := NewService("test-key", "test-secret")
+	service := NewService("test-key", "test-secret", "", "iex")
 
 	assert.Equal(t, "1Min", service.parseTimeFrame("1Min").String())
 	assert.Equal(t, "1Day", service.parseTimeFrame("1Day").String())
@@ -135,10 +307,60 @@ func TestGetHistoricalBars_NoData(t *testing.T) {
 	bars, err := service.GetHistoricalBars(context.Background(), "AAPL", "1Day", start, end)
 
 	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoBars)
 	assert.Len(t, bars, 0)
 	assert.Contains(t, err.Error(), "no bars found for symbol")
 }
 
+func TestGetHistoricalBars_NoDataAsSuccess(t *testing.T) {
+	t.Log("Testing GetHistoricalBars: empty windows succeed when configured")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"bars": {}, "next_page_token": null}`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	service.SetEmptyBarsAsSuccess(true)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	bars, err := service.GetHistoricalBars(context.Background(), "AAPL", "1Day", start, end)
+
+	require.NoError(t, err)
+	assert.NotNil(t, bars)
+	assert.Empty(t, bars)
+
+	// Domain bars from the adapter are also empty rather than an error
+	domainBars, err := newAdapter(service).GetHistoricalBars(context.Background(), "AAPL", "1Day", start, end)
+	require.NoError(t, err)
+	assert.Empty(t, domainBars)
+}
+
+func TestGetHistoricalBars_APIErrorWithEmptyAsSuccess(t *testing.T) {
+	t.Log("Testing GetHistoricalBars: API failures still error when empty windows succeed")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "forbidden"}`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	service.SetEmptyBarsAsSuccess(true)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.GetHistoricalBars(context.Background(), "AAPL", "1Day", start, end)
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoBars)
+	assert.Contains(t, err.Error(), "failed to get bars from Alpaca")
+}
+
 func TestGetSnapshot_Success(t *testing.T) {
 	t.Log("Testing GetSnapshot: successful retrieval")
 
@@ -170,10 +392,149 @@ func TestGetSnapshot_Success(t *testing.T) {
 	assert.Equal(t, 150.0, snapshot.LatestTrade.Price)
 }
 
+func TestAdapter_WarmSnapshotsPopulatesCache(t *testing.T) {
+	t.Log("Testing Adapter: warming snapshots serves later reads from cache")
+
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v2/stocks/snapshots", r.URL.Path)
+		assert.Equal(t, "AAPL,MSFT", r.URL.Query().Get("symbols"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"AAPL": { "latestTrade": { "t": "2023-01-01T10:00:00Z", "p": 150.0, "s": 100 } },
+			"MSFT": { "latestTrade": { "t": "2023-01-01T10:00:00Z", "p": 350.0, "s": 50 } }
+		}`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	adapter := newAdapter(service)
+
+	snapshots, err := adapter.GetSnapshots(context.Background(), []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, 1, requests)
+
+	cached, ok := adapter.snapshots.get("MSFT", defaultSnapshotTTL)
+	require.True(t, ok)
+	assert.Equal(t, 350.0, cached.LatestTrade.Price)
+
+	// A subsequent single-symbol read must not reach the API
+	snapshot, err := adapter.GetSnapshot(context.Background(), "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, snapshot.LatestTrade.Price)
+	assert.Equal(t, 1, requests)
+}
+
+func TestAdapter_SnapshotCacheExpires(t *testing.T) {
+	t.Log("Testing Adapter: disabled snapshot cache always fetches")
+
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{ "AAPL": { "latestTrade": { "t": "2023-01-01T10:00:00Z", "p": 150.0, "s": 100 } } }`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	adapter := newAdapter(service)
+	adapter.SetSnapshotTTL(0)
+
+	for i := 0; i < 2; i++ {
+		_, err := adapter.GetSnapshot(context.Background(), "AAPL")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, requests)
+}
+
+func TestAdapter_MaxSnapshotAgeBypassesCache(t *testing.T) {
+	t.Log("Testing Adapter: a context age bound refetches snapshots older than the bound")
+
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{ "AAPL": { "latestTrade": { "t": "2023-01-01T10:00:00Z", "p": 150.0, "s": 100 } } }`)
+	})
+
+	service, server := setupTestServer(t, handler)
+	defer server.Close()
+	adapter := newAdapter(service)
+
+	_, err := adapter.GetSnapshot(context.Background(), "AAPL")
+	require.NoError(t, err)
+
+	// Within the TTL but past the bound, the snapshot is fetched again
+	_, err = adapter.GetSnapshot(domain.WithMaxSnapshotAge(context.Background(), 0), "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// A bound longer than the TTL does not extend it; the fresh entry is reused
+	_, err = adapter.GetSnapshot(domain.WithMaxSnapshotAge(context.Background(), time.Hour), "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestSnapshot_CurrentPrice(t *testing.T) {
+	t.Log("Testing Snapshot.CurrentPrice: precedence of trade, minute bar, and daily bar")
+
+	trade := &Trade{Price: 101.5}
+	minuteBar := &PriceBar{Close: 100.25}
+	dailyBar := &PriceBar{Close: 99.75}
+
+	tests := []struct {
+		name     string
+		snapshot *Snapshot
+		price    float64
+		ok       bool
+	}{
+		{"latest trade wins", &Snapshot{LatestTrade: trade, MinuteBar: minuteBar, DailyBar: dailyBar}, 101.5, true},
+		{"minute bar without trade", &Snapshot{MinuteBar: minuteBar, DailyBar: dailyBar}, 100.25, true},
+		{"daily bar only", &Snapshot{DailyBar: dailyBar, PrevDailyBar: &PriceBar{Close: 1}}, 99.75, true},
+		{"nothing set", &Snapshot{Symbol: "AAPL", PrevDailyBar: &PriceBar{Close: 1}}, 0, false},
+		{"nil snapshot", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, ok := tt.snapshot.CurrentPrice()
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.price, price)
+		})
+	}
+}
+
 func TestIsMarketHours(t *testing.T) {
-	t.Log("Testing IsMarketHours: confirms it runs without panic")
-	// This test is basic, just ensuring the method doesn't panic, as it has no side effects.
-	service := NewService("any-key", "any-secret")
-	result := service.IsMarketHours()
-	assert.IsType(t, false, result)
+	t.Log("Testing IsMarketHours: boundaries of the regular session in Eastern time")
+	service := NewService("any-key", "any-secret", "", "iex")
+	fakeClock := clock.NewFake(time.Time{})
+	service.SetClock(fakeClock)
+
+	eastern, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		open bool
+	}{
+		{"before open", time.Date(2025, 3, 12, 9, 29, 59, 0, eastern), false},
+		{"at open", time.Date(2025, 3, 12, 9, 30, 0, 0, eastern), true},
+		{"midday", time.Date(2025, 3, 12, 12, 0, 0, 0, eastern), true},
+		{"last minute", time.Date(2025, 3, 12, 15, 59, 59, 0, eastern), true},
+		{"at close", time.Date(2025, 3, 12, 16, 0, 0, 0, eastern), false},
+		{"saturday", time.Date(2025, 3, 15, 12, 0, 0, 0, eastern), false},
+		{"sunday", time.Date(2025, 3, 16, 12, 0, 0, 0, eastern), false},
+		// 14:30 UTC is 9:30 EST in winter and 10:30 EDT in summer
+		{"winter open in UTC", time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC), true},
+		{"summer pre-open in UTC", time.Date(2025, 7, 15, 13, 29, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		fakeClock.Set(tt.at)
+		assert.Equal(t, tt.open, service.IsMarketHours(), tt.name)
+	}
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"stock-analyzer/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalContextKey is the Gin context key holding the authenticated principal
+const principalContextKey = "principal"
+
+// anonymousActor is recorded when an admin action has no authenticated principal
+const anonymousActor = "anonymous"
+
+// auditWriteTimeout bounds how long persisting an audit entry may delay the response
+const auditWriteTimeout = 5 * time.Second
+
+// AuditLogger records admin actions to the structured log and, when a
+// repository is configured, to the audit_log table
+type AuditLogger struct {
+	repo   domain.AuditRepository
+	logger *slog.Logger
+}
+
+// NewAuditLogger creates an audit logger. repo may be nil to only log entries.
+func NewAuditLogger(repo domain.AuditRepository, logger *slog.Logger) *AuditLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &AuditLogger{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record logs an audit entry and persists it if a repository is configured.
+// Persistence failures are logged rather than returned so auditing never
+// changes the outcome of the audited request. The write is detached from ctx's
+// cancellation, so an action whose client disconnected or timed out is still
+// recorded, and is bounded by auditWriteTimeout instead.
+func (a *AuditLogger) Record(ctx context.Context, entry *domain.AuditEntry) {
+	a.logger.Info("audit",
+		"actor", entry.Actor,
+		"action", entry.Action,
+		"target", entry.Target,
+		"status", entry.Status,
+		"request_id", entry.RequestID,
+		"timestamp", entry.Timestamp,
+	)
+
+	if a.repo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+
+	if err := a.repo.CreateAuditEntry(ctx, entry); err != nil {
+		a.logger.Error("failed to persist audit entry", "action", entry.Action, "error", err)
+	}
+}
+
+// Audit middleware records an audit entry once the handler completes for every route
+// named in actions, keyed by method and route path (e.g. "POST /api/v1/ingest"). Other
+// routes pass through unaudited. Registered ahead of authentication, it also records
+// the admin requests that authentication rejects.
+func Audit(auditor *AuditLogger, actions map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action, audited := actions[c.Request.Method+" "+c.FullPath()]
+		if !audited {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		actor := c.GetString(principalContextKey)
+		if actor == "" {
+			actor = anonymousActor
+		}
+
+		auditor.Record(c.Request.Context(), &domain.AuditEntry{
+			Actor:     actor,
+			Action:    action,
+			Target:    c.Request.URL.RequestURI(),
+			Status:    c.Writer.Status(),
+			RequestID: c.GetString(requestIDContextKey),
+			Timestamp: time.Now().UTC(),
+		})
+	}
+}
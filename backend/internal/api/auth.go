@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log/slog"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the request header carrying the caller's API key
+const APIKeyHeader = "X-Api-Key"
+
+const (
+	// apiKeyPrincipal is the principal of a request authenticated with the shared API key
+	apiKeyPrincipal = "api-key"
+	// unauthenticatedPrincipal is the principal of a write let through because no API key is set
+	unauthenticatedPrincipal = "unauthenticated"
+)
+
+// APIKeyAuth middleware protects write endpoints with a single shared API key,
+// rejecting requests whose X-Api-Key header is missing or wrong with 401. With no
+// key configured, a warning is logged once and every request is let through so
+// local development works without setup. The request principal records which
+// case applied, so audit entries show whether the write was authenticated.
+func APIKeyAuth(key string) gin.HandlerFunc {
+	if key == "" {
+		slog.Warn("API_KEY is not set; write endpoints are unauthenticated")
+		return func(c *gin.Context) {
+			c.Set(principalContextKey, unauthenticatedPrincipal)
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(APIKeyHeader)), []byte(key)) != 1 {
+			HandleError(c, apperrors.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalContextKey, apiKeyPrincipal)
+		c.Next()
+	}
+}
+
+// UserAPIKeyAuth middleware authenticates the caller as one of the configured
+// users (user ID -> API key) and stores the user ID as the request principal.
+// Requests without a matching key are rejected with 401.
+func UserAPIKeyAuth(keys map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := matchAPIKey(keys, c.GetHeader(APIKeyHeader))
+		if !ok {
+			HandleError(c, apperrors.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalContextKey, userID)
+		c.Next()
+	}
+}
+
+// matchAPIKey returns the user owning key. Every configured key is compared in
+// constant time so response timing doesn't reveal how much of a key matched.
+func matchAPIKey(keys map[string]string, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	var matched string
+	for userID, candidate := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			matched = userID
+		}
+	}
+
+	return matched, matched != ""
+}
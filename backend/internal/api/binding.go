@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// queryValidator checks `validate` tags on query structs and reports fields by their form names
+var queryValidator = newQueryValidator()
+
+func newQueryValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		return name
+	})
+	return v
+}
+
+// maxRatingsLimit is the largest page size GetStockRatings accepts
+const maxRatingsLimit = 100
+
+// RatingsQuery holds the query parameters accepted by GetStockRatings
+type RatingsQuery struct {
+	Page   int    `form:"page" validate:"min=1"`
+	Limit  int    `form:"limit" validate:"min=1,max=100"`
+	SortBy string `form:"sort_by"`
+	Order  string `form:"order" validate:"oneof=asc desc"`
+	Search string `form:"search"`
+
+	// StrictPagination lets validation reject out-of-range page and limit values
+	// instead of pulling them into range
+	StrictPagination bool `form:"strict_pagination"`
+}
+
+// PageQuery holds the pagination parameters of endpoints that page an in-memory list
+type PageQuery struct {
+	Page  int `form:"page" validate:"min=1"`
+	Limit int `form:"limit" validate:"min=1,max=100"`
+}
+
+// normalize canonicalizes case-insensitive parameters before validation
+func (q *RatingsQuery) normalize() {
+	q.Order = strings.ToLower(q.Order)
+
+	if !q.StrictPagination {
+		q.Page = max(q.Page, 1)
+		q.Limit = min(max(q.Limit, 1), maxRatingsLimit)
+	}
+}
+
+// queryNormalizer is implemented by query structs that adjust bound values before validation
+type queryNormalizer interface {
+	normalize()
+}
+
+// bindQuery copies query parameters into the `form`-tagged fields of dst and validates
+// the result. Fields whose parameter is absent keep the value already in dst, so callers
+// set defaults before binding.
+func bindQuery(c *gin.Context, dst interface{}) error {
+	value := reflect.ValueOf(dst).Elem()
+	fieldTypes := value.Type()
+
+	for i := 0; i < fieldTypes.NumField(); i++ {
+		name, _, _ := strings.Cut(fieldTypes.Field(i).Tag.Get("form"), ",")
+		if name == "" {
+			continue
+		}
+
+		raw, exists := c.GetQuery(name)
+		if !exists {
+			continue
+		}
+
+		if err := setQueryField(value.Field(i), strings.TrimSpace(raw)); err != nil {
+			return apperrors.ErrValidationFailure.WithDetails(fmt.Sprintf("invalid %s parameter", name))
+		}
+	}
+
+	if normalizer, ok := dst.(queryNormalizer); ok {
+		normalizer.normalize()
+	}
+
+	if err := queryValidator.Struct(dst); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return apperrors.Wrap(err, apperrors.ErrCodeInternal, "failed to validate query parameters")
+		}
+
+		messages := make([]string, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			messages = append(messages, validationMessage(fieldErr))
+		}
+		return apperrors.ErrValidationFailure.WithDetails(strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// setQueryField parses raw into field according to its kind
+func setQueryField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported query field kind %s", field.Kind())
+	}
+
+	return nil
+}
+
+// validationMessage describes a failed constraint in terms of the query parameter
+func validationMessage(fieldErr validator.FieldError) string {
+	name := fieldErr.Field()
+
+	switch fieldErr.Tag() {
+	case "min":
+		return fmt.Sprintf("invalid %s parameter: must be at least %s", name, fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("invalid %s parameter: must be at most %s", name, fieldErr.Param())
+	case "oneof":
+		options := strings.Fields(fieldErr.Param())
+		return fmt.Sprintf("invalid %s parameter: must be one of '%s'", name, strings.Join(options, "', '"))
+	default:
+		return fmt.Sprintf("invalid %s parameter", name)
+	}
+}
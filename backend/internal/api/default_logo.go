@@ -0,0 +1,21 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLogoPath serves the placeholder used when a company logo cannot be resolved
+const defaultLogoPath = "/api/v1/stocks/default-logo"
+
+//go:embed assets/default-logo.svg
+var defaultLogoSVG []byte
+
+// GetDefaultLogo serves the embedded placeholder logo image
+func (h *Handlers) GetDefaultLogo(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("ETag", `"default-logo"`)
+	c.Data(http.StatusOK, "image/svg+xml", defaultLogoSVG)
+}
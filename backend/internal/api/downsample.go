@@ -0,0 +1,43 @@
+package api
+
+import "stock-analyzer/internal/domain"
+
+// downsampleBars merges bars ordered oldest to newest into at most points
+// buckets of near-equal size. Each bucket becomes one OHLC bar: the open and
+// timestamp of its first bar, the close of its last, the extreme high and low,
+// and the summed volume. Bars are returned unchanged when points is not positive
+// or there are already no more than points bars.
+func downsampleBars(bars []domain.PriceBar, points int) []domain.PriceBar {
+	if points <= 0 || len(bars) <= points {
+		return bars
+	}
+
+	sampled := make([]domain.PriceBar, points)
+	for i := range sampled {
+		// Integer bucket edges spread the remainder across buckets, so every
+		// bucket holds floor or ceil of len(bars)/points bars
+		start := i * len(bars) / points
+		end := (i + 1) * len(bars) / points
+		bucket := bars[start:end]
+
+		merged := domain.PriceBar{
+			Timestamp: bucket[0].Timestamp,
+			Open:      bucket[0].Open,
+			High:      bucket[0].High,
+			Low:       bucket[0].Low,
+			Close:     bucket[len(bucket)-1].Close,
+		}
+		for _, bar := range bucket {
+			if bar.High > merged.High {
+				merged.High = bar.High
+			}
+			if bar.Low < merged.Low {
+				merged.Low = bar.Low
+			}
+			merged.Volume += bar.Volume
+		}
+		sampled[i] = merged
+	}
+
+	return sampled
+}
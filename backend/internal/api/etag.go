@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/locale"
+)
+
+// recommendationsETag derives a strong ETag from the generation time of a
+// recommendation set, the query parameters that shape the response, and the
+// request locale, since recommendation reasons are localized.
+func recommendationsETag(ctx context.Context, recommendations []domain.StockRecommendation, query url.Values) string {
+	var generatedAt time.Time
+	for _, recommendation := range recommendations {
+		if recommendation.GeneratedAt.After(generatedAt) {
+			generatedAt = recommendation.GeneratedAt
+		}
+	}
+
+	// url.Values.Encode sorts by key, so equivalent queries hash identically
+	source := fmt.Sprintf("%d|%d|%s|%s", generatedAt.UnixNano(), len(recommendations), query.Encode(), locale.FromContext(ctx).Tag)
+	sum := sha256.Sum256([]byte(source))
+
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:8]))
+}
+
+// etagMatches reports whether an If-None-Match header value matches the given ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
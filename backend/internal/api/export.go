@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ratingsCSVHeader is the header row of the ratings CSV export
+var ratingsCSVHeader = []string{
+	"ticker", "company", "brokerage", "action", "rating_from",
+	"rating_to", "target_from", "target_to", "time",
+}
+
+// ExportStockRatings streams stock ratings matching the list endpoint's search
+// and sort filters as a CSV download. The optional columns parameter selects and
+// orders the exported fields. When nothing matches, the response is 204 No Content,
+// or a header-only CSV if ExportEmptyHeaderOnly is set.
+func (h *Handlers) ExportStockRatings(c *gin.Context) {
+	order, err := parseSortOrder(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	columns, err := parseCSVColumns(c.Query("columns"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	filters := domain.FilterOptions{
+		Search: c.Query("search"),
+		SortBy: c.DefaultQuery("sort_by", "time"),
+		Order:  order,
+	}
+
+	clearWriteDeadline(c)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="ratings.csv"`)
+
+	writer := csv.NewWriter(c.Writer)
+	header := selectCSVColumns(ratingsCSVHeader, columns)
+
+	// The header is written with the first row so an empty export can still become a 204
+	places := h.cfg.JSONDecimalPlaces
+	rows := 0
+	err = h.stockRepo.StreamStockRatings(c.Request.Context(), filters, func(rating domain.StockRating) error {
+		if rows == 0 {
+			if err := writer.Write(header); err != nil {
+				return err
+			}
+		}
+		rows++
+		return writer.Write(selectCSVColumns(ratingCSVRecord(rating, places), columns))
+	})
+
+	if err != nil {
+		if !c.Writer.Written() {
+			// Nothing has reached the client yet, so a normal error response is still possible
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			HandleError(c, err)
+			return
+		}
+
+		// The CSV is already partially sent; all we can do is stop
+		slog.ErrorContext(c.Request.Context(), "ratings export aborted", "error", err)
+		c.Abort()
+		return
+	}
+
+	if rows == 0 {
+		if !h.cfg.ExportEmptyHeaderOnly {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.Status(http.StatusNoContent)
+			return
+		}
+		writer.Write(header)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to flush ratings export", "error", err)
+	}
+}
+
+// parseCSVColumns resolves a comma-separated list of column names to indexes in
+// ratingsCSVHeader. An empty list selects every column in the default order.
+func parseCSVColumns(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	positions := make(map[string]int, len(ratingsCSVHeader))
+	for i, name := range ratingsCSVHeader {
+		positions[name] = i
+	}
+
+	var columns []int
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		index, ok := positions[name]
+		if !ok {
+			return nil, apperrors.ErrValidationFailure.WithDetails(
+				fmt.Sprintf("invalid columns parameter: unknown column %q, must be one of %s", name, strings.Join(ratingsCSVHeader, ", ")))
+		}
+		if seen[name] {
+			return nil, apperrors.ErrValidationFailure.WithDetails(
+				fmt.Sprintf("invalid columns parameter: duplicate column %q", name))
+		}
+		seen[name] = true
+		columns = append(columns, index)
+	}
+	return columns, nil
+}
+
+// selectCSVColumns picks the given column indexes from a full row, returning the
+// row unchanged when no selection was made
+func selectCSVColumns(row []string, columns []int) []string {
+	if columns == nil {
+		return row
+	}
+	selected := make([]string, len(columns))
+	for i, index := range columns {
+		selected[i] = row[index]
+	}
+	return selected
+}
+
+// ratingCSVRecord converts a rating to a CSV row in ratingsCSVHeader order
+func ratingCSVRecord(rating domain.StockRating, places int) []string {
+	return []string{
+		rating.Ticker,
+		rating.Company,
+		rating.Brokerage,
+		rating.Action,
+		optionalString(rating.RatingFrom),
+		rating.RatingTo,
+		optionalFloat(rating.TargetFrom, places),
+		optionalFloat(rating.TargetTo, places),
+		rating.Time.UTC().Format(time.RFC3339),
+	}
+}
+
+// optionalString renders a nullable string as an empty cell when nil
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// optionalFloat renders a nullable number rounded for output, or an empty cell when nil
+func optionalFloat(value *float64, places int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(roundFloat(*value, places), 'f', -1, 64)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseFields reads the comma-separated "fields" query parameter and validates
+// every requested name against the JSON field names of T. A nil slice means
+// the client did not ask for a sparse fieldset.
+func parseFields[T any](c *gin.Context) ([]string, error) {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil, nil
+	}
+
+	allowed := jsonFieldNames(reflect.TypeOf((*T)(nil)).Elem())
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !allowed[field] {
+			return nil, apperrors.ErrValidationFailure.WithDetails(fmt.Sprintf("unknown field %q", field))
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, apperrors.ErrValidationFailure.WithDetails("fields parameter must list at least one field")
+	}
+
+	return fields, nil
+}
+
+// projectFields converts items to maps that only contain the requested JSON fields.
+func projectFields[T any](items []T, fields []string) ([]map[string]interface{}, error) {
+	projected := make([]map[string]interface{}, 0, len(items))
+
+	for _, item := range items {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "failed to encode item for field selection")
+		}
+
+		var full map[string]interface{}
+		if err := json.Unmarshal(encoded, &full); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "failed to decode item for field selection")
+		}
+
+		partial := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, exists := full[field]; exists {
+				partial[field] = value
+			}
+		}
+		projected = append(projected, partial)
+	}
+
+	return projected, nil
+}
+
+// jsonFieldNames returns the set of JSON names exposed by a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+		names[name] = true
+	}
+
+	return names
+}
+
+// respondWithFields writes only the requested fields of each item as the JSON response
+func respondWithFields[T any](c *gin.Context, items []T, fields []string) {
+	projected, err := projectFields(items, fields)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, projected)
+}
@@ -1,22 +1,46 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	"stock-analyzer/pkg/config"
 	apperrors "stock-analyzer/pkg/errors"
+	"stock-analyzer/pkg/logging"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxPriceFallbackWindow is how far back GetStockPrice looks when the requested period is empty
+const maxPriceFallbackWindow = 5 * 365 * 24 * time.Hour
+
+// maxBulkSymbols caps how many symbols a single bulk price request may ask for
+const maxBulkSymbols = 25
+
 // StockPriceResponse represents the price data response
 type StockPriceResponse struct {
-	Symbol string            `json:"symbol"`
-	Bars   []domain.PriceBar `json:"bars"`
+	Symbol  string            `json:"symbol"`
+	Bars    []domain.PriceBar `json:"bars"`
+	Partial bool              `json:"partial,omitempty"` // True when bars come from a wider fallback window
+	Summary *PriceSummary     `json:"summary,omitempty"` // Change summary computed from Bars
+}
+
+// BulkPriceResponse represents the multi-symbol price response. Symbols that
+// failed to load appear in Errors instead of Results.
+type BulkPriceResponse struct {
+	Results map[string][]domain.PriceBar `json:"results"`
+	Errors  map[string]string            `json:"errors"`
 }
 
 // StockLogoResponse represents the logo response
@@ -31,16 +55,88 @@ type Handlers struct {
 	ingestionSvc      domain.IngestionService
 	recommendationSvc domain.RecommendationService
 	alpacaSvc         domain.AlpacaService
+	cfg               *config.Config
+	maintenance       *MaintenanceMode
+	schemaRepo        domain.SchemaRepository
+	healthChecker     domain.HealthChecker
+	health            *healthCache
+	readiness         *Readiness
+	clock             clock.Clock
+	ingestJobs        *ingestionJobs
+	watchlistRepo     domain.WatchlistRepository
+	streams           *snapshotStreams
+	eventHeartbeat    time.Duration
 }
 
 // NewHandlers creates a new handlers instance
-func NewHandlers(stockRepo domain.StockRepository, ingestionSvc domain.IngestionService, recommendationSvc domain.RecommendationService, alpacaSvc domain.AlpacaService) *Handlers {
+func NewHandlers(stockRepo domain.StockRepository, ingestionSvc domain.IngestionService, recommendationSvc domain.RecommendationService, alpacaSvc domain.AlpacaService, cfg *config.Config) *Handlers {
 	return &Handlers{
 		stockRepo:         stockRepo,
 		ingestionSvc:      ingestionSvc,
 		recommendationSvc: recommendationSvc,
 		alpacaSvc:         alpacaSvc,
+		cfg:               cfg,
+		maintenance:       NewMaintenanceMode(false, ""),
+		readiness:         NewReadiness(false),
+		health:            &healthCache{},
+		clock:             clock.Real{},
+		ingestJobs:        newIngestionJobs(),
+		streams:           newSnapshotStreams(DefaultSnapshotStreamInterval, DefaultMaxSnapshotStreams),
+		eventHeartbeat:    DefaultRecommendationHeartbeat,
+	}
+}
+
+// SetMaintenance replaces the maintenance toggle shared with the ReadOnly middleware
+func (h *Handlers) SetMaintenance(mode *MaintenanceMode) {
+	h.maintenance = mode
+}
+
+// SetSchemaRepository sets the repository used to report the database schema version
+func (h *Handlers) SetSchemaRepository(repo domain.SchemaRepository) {
+	h.schemaRepo = repo
+}
+
+// SetWatchlistRepository sets the repository backing the per-user watchlist endpoints
+func (h *Handlers) SetWatchlistRepository(repo domain.WatchlistRepository) {
+	h.watchlistRepo = repo
+}
+
+// SetHealthChecker sets the dependency pinged by the health and readiness checks
+func (h *Handlers) SetHealthChecker(checker domain.HealthChecker) {
+	h.healthChecker = checker
+}
+
+// SetHealthCheckCacheTTL sets how long a database ping result is reused by the
+// health and readiness checks; zero pings on every probe
+func (h *Handlers) SetHealthCheckCacheTTL(ttl time.Duration) {
+	h.health.mutex.Lock()
+	defer h.health.mutex.Unlock()
+	h.health.ttl = ttl
+}
+
+// SetSnapshotStreaming sets how often snapshot streams poll and how many may be
+// open at once; zero or negative values keep the defaults
+func (h *Handlers) SetSnapshotStreaming(interval time.Duration, maxStreams int) {
+	h.streams = newSnapshotStreams(interval, maxStreams)
+}
+
+// SetRecommendationHeartbeat sets how often recommendation streams send heartbeats;
+// zero or negative values keep the default
+func (h *Handlers) SetRecommendationHeartbeat(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRecommendationHeartbeat
 	}
+	h.eventHeartbeat = interval
+}
+
+// SetReadiness replaces the readiness state reported by the readiness probe
+func (h *Handlers) SetReadiness(readiness *Readiness) {
+	h.readiness = readiness
+}
+
+// SetClock replaces the clock used for price windows, cleanup cutoffs, and the health check cache
+func (h *Handlers) SetClock(c clock.Clock) {
+	h.clock = c
 }
 
 // GetStockPrice retrieves historical price data for a stock using Alpaca API
@@ -54,40 +150,42 @@ func (h *Handlers) GetStockPrice(c *gin.Context) {
 	symbol = strings.ToUpper(symbol)
 	period := c.DefaultQuery("period", "1M")
 
-	var timeframe string
-	var start time.Time
-	end := time.Now()
+	fallback, err := strconv.ParseBool(c.DefaultQuery("fallback", "false"))
+	if err != nil {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid fallback parameter"))
+		return
+	}
 
-	switch period {
-	case "1W":
-		start = end.AddDate(0, 0, -7)
-		timeframe = "1Hour"
-	case "1M":
-		start = end.AddDate(0, -1, 0)
-		timeframe = "1Hour"
-	case "3M":
-		start = end.AddDate(0, -3, 0)
-		timeframe = "1Day"
-	case "6M":
-		start = end.AddDate(0, -6, 0)
-		timeframe = "1Day"
-	case "1Y":
-		start = end.AddDate(-1, 0, 0)
-		timeframe = "1Day"
-	case "2Y":
-		start = end.AddDate(-2, 0, 0)
-		timeframe = "1Day"
-	default:
-		start = end.AddDate(0, -1, 0)
-		timeframe = "1Hour"
+	// Zero keeps every bar; otherwise the bars are merged down to about this many points
+	points := 0
+	if raw := c.Query("points"); raw != "" {
+		points, err = strconv.Atoi(raw)
+		if err != nil || points < 1 {
+			HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid points parameter: must be a positive integer"))
+			return
+		}
 	}
 
+	end := h.clock.Now()
+	start, timeframe := periodWindow(period, end)
+
 	alpacaBars, err := h.alpacaSvc.GetHistoricalBars(c.Request.Context(), symbol, timeframe, start, end)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
+	// Widen the window to whatever history exists, e.g. for recent IPOs
+	partial := false
+	if len(alpacaBars) == 0 && fallback {
+		alpacaBars, err = h.alpacaSvc.GetHistoricalBars(c.Request.Context(), symbol, "1Day", end.Add(-maxPriceFallbackWindow), end)
+		if err != nil {
+			HandleError(c, err)
+			return
+		}
+		partial = len(alpacaBars) > 0
+	}
+
 	bars := make([]domain.PriceBar, len(alpacaBars))
 	for i, alpacaBar := range alpacaBars {
 		bars[i] = domain.PriceBar{
@@ -105,9 +203,217 @@ func (h *Handlers) GetStockPrice(c *gin.Context) {
 		return
 	}
 
+	places := h.cfg.JSONDecimalPlaces
+	response := StockPriceResponse{
+		Symbol:  symbol,
+		Bars:    roundBars(downsampleBars(bars, points), places),
+		Partial: partial,
+		Summary: roundSummary(summarizeBars(bars), places),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// periodWindow returns the start time and bar timeframe for a period such as "1M"
+func periodWindow(period string, end time.Time) (time.Time, string) {
+	switch period {
+	case "1W":
+		return end.AddDate(0, 0, -7), "1Hour"
+	case "1M":
+		return end.AddDate(0, -1, 0), "1Hour"
+	case "3M":
+		return end.AddDate(0, -3, 0), "1Day"
+	case "6M":
+		return end.AddDate(0, -6, 0), "1Day"
+	case "1Y":
+		return end.AddDate(-1, 0, 0), "1Day"
+	case "2Y":
+		return end.AddDate(-2, 0, 0), "1Day"
+	default:
+		return end.AddDate(0, -1, 0), "1Hour"
+	}
+}
+
+// GetBulkPrices retrieves price data for several symbols in one request. Symbols
+// are fetched concurrently; the Alpaca service's rate limiter paces the calls.
+func (h *Handlers) GetBulkPrices(c *gin.Context) {
+	symbols, err := normalizeSymbols(strings.Split(c.Query("symbols"), ","), "symbols")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	end := h.clock.Now()
+	start, timeframe := periodWindow(c.DefaultQuery("period", "1M"), end)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	response := BulkPriceResponse{
+		Results: make(map[string][]domain.PriceBar, len(symbols)),
+		Errors:  make(map[string]string),
+	}
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+
+			bars, err := h.alpacaSvc.GetHistoricalBars(c.Request.Context(), symbol, timeframe, start, end)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// A failing symbol shouldn't sink the others
+				response.Errors[symbol] = errorMessage(c.Request.Context(), err)
+				return
+			}
+			if bars == nil {
+				bars = []domain.PriceBar{}
+			}
+			response.Results[symbol] = roundBars(bars, h.cfg.JSONDecimalPlaces)
+		}(symbol)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if len(response.Results) == 0 {
+		status = http.StatusBadGateway
+	}
+
+	c.JSON(status, response)
+}
+
+// normalizeSymbols uppercases, trims, and de-duplicates symbols, and enforces
+// that between one and maxBulkSymbols remain. param names the field in errors.
+func normalizeSymbols(raw []string, param string) ([]string, error) {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, symbol := range raw {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+
+	if len(symbols) == 0 {
+		return nil, apperrors.ErrValidationFailure.WithDetails(fmt.Sprintf("%s parameter is required", param))
+	}
+	if len(symbols) > maxBulkSymbols {
+		return nil, apperrors.ErrValidationFailure.WithDetails(fmt.Sprintf("at most %d %s may be requested at once", maxBulkSymbols, param))
+	}
+
+	return symbols, nil
+}
+
+// GetStockSnapshot retrieves the current market snapshot for a stock
+func (h *Handlers) GetStockSnapshot(c *gin.Context) {
+	symbol := strings.ToUpper(c.Param("symbol"))
+	if symbol == "" {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("symbol parameter is required"))
+		return
+	}
+
+	snapshot, err := h.alpacaSvc.GetSnapshot(c.Request.Context(), symbol)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if snapshot == nil {
+		HandleError(c, apperrors.ErrNotFound.WithDetails(fmt.Sprintf("No snapshot available for %s", symbol)))
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// WarmSnapshotsRequest is the body accepted by WarmSnapshots
+type WarmSnapshotsRequest struct {
+	Symbols []string `json:"symbols"`
+}
+
+// WarmSnapshots pre-fetches snapshots for a set of symbols in one upstream call so
+// subsequent snapshot reads are served from the Alpaca service's cache
+func (h *Handlers) WarmSnapshots(c *gin.Context) {
+	var req WarmSnapshotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("request body must be {\"symbols\": [...]}"))
+		return
+	}
+
+	symbols, err := normalizeSymbols(req.Symbols, "symbols")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	snapshots, err := h.alpacaSvc.GetSnapshots(c.Request.Context(), symbols)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	missing := []string{}
+	for _, symbol := range symbols {
+		if _, exists := snapshots[symbol]; !exists {
+			missing = append(missing, symbol)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"warmed":  len(snapshots),
+		"missing": missing,
+	})
+}
+
+// genericErrorMessage replaces the text of errors that are not AppErrors, which may
+// carry internal detail such as upstream URLs
+const genericErrorMessage = "Internal server error"
+
+// errorMessage returns a client-safe description of err. Errors other than AppErrors
+// are logged and reported with a generic message.
+func errorMessage(ctx context.Context, err error) string {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		if appErr.Details != "" {
+			return appErr.Message + ": " + appErr.Details
+		}
+		return appErr.Message
+	}
+
+	slog.ErrorContext(ctx, "unexpected error", "error", err)
+	return genericErrorMessage
+}
+
+// GetRecentBars retrieves the last 24 hours of hourly price data for a stock
+func (h *Handlers) GetRecentBars(c *gin.Context) {
+	symbol := c.Param("symbol")
+	if symbol == "" {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("symbol parameter is required"))
+		return
+	}
+
+	symbol = strings.ToUpper(symbol)
+
+	bars, err := h.alpacaSvc.GetRecentBars(c.Request.Context(), symbol)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if len(bars) == 0 {
+		HandleError(c, apperrors.ErrNotFound.WithDetails(fmt.Sprintf("No recent price data available for %s", symbol)))
+		return
+	}
+
+	places := h.cfg.JSONDecimalPlaces
 	response := StockPriceResponse{
-		Symbol: symbol,
-		Bars:   bars,
+		Symbol:  symbol,
+		Bars:    roundBars(bars, places),
+		Summary: roundSummary(summarizeBars(bars), places),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -122,59 +428,196 @@ func (h *Handlers) GetStockLogo(c *gin.Context) {
 	}
 
 	symbol = strings.ToUpper(symbol)
-	logoURL := fmt.Sprintf("https://logo.clearbit.com/%s.com", strings.ToLower(symbol))
+
+	size := 0
+	if raw := c.Query("size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || !allowedLogoSizes[parsed] {
+			HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid size parameter: must be one of 32, 64, 128"))
+			return
+		}
+		size = parsed
+	}
+
+	logoURL := applyLogoSize(buildLogoURL(h.cfg.LogoBaseURL, symbol), size)
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+	case "redirect":
+		// A guessed <ticker>.com domain often has no logo, so send the browser to
+		// the placeholder instead of a broken image
+		if strings.Contains(logoURLTemplate(h.cfg.LogoBaseURL), "{domain}") && !hasLogoDomain(symbol) {
+			logoURL = defaultLogoPath
+		}
+		c.Header("Cache-Control", "public, max-age=3600")
+		c.Redirect(http.StatusFound, logoURL)
+		return
+	default:
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid format parameter: must be 'json' or 'redirect'"))
+		return
+	}
 
 	response := StockLogoResponse{
 		Symbol:  symbol,
 		LogoURL: logoURL,
 	}
 
+	etag := symbol
+	if size > 0 {
+		etag = fmt.Sprintf("%s-%d", symbol, size)
+	}
+
 	c.Header("Cache-Control", "public, max-age=3600")
-	c.Header("ETag", fmt.Sprintf(`"%s"`, symbol))
+	c.Header("ETag", fmt.Sprintf(`"%s"`, etag))
 
 	c.JSON(http.StatusOK, response)
 }
 
+// buildLogoURL fills the placeholders of a logo URL template: {domain} becomes the
+// company's web domain and %s the lowercased symbol
+func buildLogoURL(template, symbol string) string {
+	template = logoURLTemplate(template)
+
+	logoURL := strings.ReplaceAll(template, "{domain}", resolveLogoDomain(symbol))
+	return strings.ReplaceAll(logoURL, "%s", strings.ToLower(symbol))
+}
+
+// logoURLTemplate returns the configured logo URL template or the default one
+func logoURLTemplate(template string) string {
+	if template == "" {
+		return config.DefaultLogoBaseURL
+	}
+	return template
+}
+
+// allowedLogoSizes lists the pixel sizes accepted by the logo endpoint's size parameter
+var allowedLogoSizes = map[int]bool{32: true, 64: true, 128: true}
+
+// applyLogoSize adds the requested size to a logo URL. A {size} placeholder is
+// filled in directly; Clearbit URLs get a size query parameter; other providers
+// are left unchanged. Without a size, a {size} placeholder falls back to 128.
+func applyLogoSize(logoURL string, size int) string {
+	if strings.Contains(logoURL, "{size}") {
+		if size == 0 {
+			size = 128
+		}
+		return strings.ReplaceAll(logoURL, "{size}", strconv.Itoa(size))
+	}
+	if size == 0 {
+		return logoURL
+	}
+
+	parsed, err := url.Parse(logoURL)
+	if err != nil || parsed.Host != "logo.clearbit.com" {
+		return logoURL
+	}
+	query := parsed.Query()
+	query.Set("size", strconv.Itoa(size))
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
 // GetStockRatings retrieves paginated stock ratings with optional filtering
 func (h *Handlers) GetStockRatings(c *gin.Context) {
-	page, err := parseIntQuery(c, "page", 1)
+	query := RatingsQuery{
+		Page:             1,
+		Limit:            20,
+		SortBy:           "time",
+		Order:            "desc",
+		StrictPagination: h.cfg.StrictPagination,
+	}
+	if err := bindQuery(c, &query); err != nil {
+		HandleError(c, err)
+		return
+	}
+	order := domain.SortOrder(query.Order)
+
+	fields, err := parseFields[domain.StockRating](c)
 	if err != nil {
-		HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid page parameter"))
+		HandleError(c, err)
 		return
 	}
 
-	limit, err := parseIntQuery(c, "limit", 20)
+	filters := domain.FilterOptions{
+		Page:   query.Page,
+		Limit:  query.Limit,
+		Search: query.Search,
+		SortBy: query.SortBy,
+		Order:  order,
+	}
+
+	response, err := h.stockRepo.GetStockRatings(c.Request.Context(), filters)
 	if err != nil {
-		HandleError(c, apperrors.ErrValidationFailure.WithDetails("invalid limit parameter"))
+		HandleError(c, err)
 		return
 	}
 
-	if page < 1 {
-		page = 1
+	// Copy rather than mutate the repository's response, which may be shared
+	pagination := response.Pagination
+	pagination.Order = string(order)
+	ratings := roundRatings(response.Data, h.cfg.JSONDecimalPlaces)
+
+	if fields != nil {
+		data, err := projectFields(ratings, fields)
+		if err != nil {
+			HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.NewPaginatedResponse(data, pagination))
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+
+	c.JSON(http.StatusOK, domain.NewPaginatedResponse(ratings, pagination))
+}
+
+// parseSortOrder reads the order query parameter, which must be "asc" or "desc"
+func parseSortOrder(c *gin.Context) (domain.SortOrder, error) {
+	order := domain.SortOrder(strings.ToLower(c.DefaultQuery("order", "desc")))
+	if order != domain.SortAscending && order != domain.SortDescending {
+		return "", apperrors.ErrValidationFailure.WithDetails("invalid order parameter: must be 'asc' or 'desc'")
 	}
 
-	sortBy := c.DefaultQuery("sort_by", "time")
-	order := c.DefaultQuery("order", "desc")
-	search := c.Query("search")
+	return order, nil
+}
 
-	filters := domain.FilterOptions{
-		Page:     page,
-		Limit:    limit,
-		Search:   search,
-		SortBy:   sortBy,
-		SortDesc: order == "desc",
+// GetBrokerageLeaderboard returns brokerages ordered by how many ratings they have issued
+func (h *Handlers) GetBrokerageLeaderboard(c *gin.Context) {
+	counts, err := h.stockRepo.GetRatingsCountByBrokerage(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
 	}
 
-	response, err := h.stockRepo.GetStockRatings(c.Request.Context(), filters)
+	leaderboard := make([]domain.BrokerageCount, 0, len(counts))
+	for brokerage, count := range counts {
+		leaderboard = append(leaderboard, domain.BrokerageCount{Brokerage: brokerage, Count: count})
+	}
+
+	// Break ties by name so the JSON ordering is deterministic
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Count != leaderboard[j].Count {
+			return leaderboard[i].Count > leaderboard[j].Count
+		}
+		return leaderboard[i].Brokerage < leaderboard[j].Brokerage
+	})
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GetRatingDistribution returns how many ratings exist for each target rating
+func (h *Handlers) GetRatingDistribution(c *gin.Context) {
+	distribution, err := h.stockRepo.GetRatingDistribution(c.Request.Context())
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	if distribution == nil {
+		distribution = map[string]int{}
+	}
+
+	c.JSON(http.StatusOK, distribution)
 }
 
 // GetStockRatingsByTicker retrieves all ratings for a specific ticker
@@ -185,6 +628,12 @@ func (h *Handlers) GetStockRatingsByTicker(c *gin.Context) {
 		return
 	}
 
+	fields, err := parseFields[domain.StockRating](c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
 	ratings, err := h.stockRepo.GetStockRatingsByTicker(c.Request.Context(), ticker)
 	if err != nil {
 		HandleError(c, err)
@@ -196,43 +645,247 @@ func (h *Handlers) GetStockRatingsByTicker(c *gin.Context) {
 		return
 	}
 
+	ratings = roundRatings(ratings, h.cfg.JSONDecimalPlaces)
+
+	if fields != nil {
+		respondWithFields(c, ratings, fields)
+		return
+	}
+
 	c.JSON(http.StatusOK, ratings)
 }
 
 // GetRecommendations retrieves stock recommendations
 func (h *Handlers) GetRecommendations(c *gin.Context) {
+	fields, err := parseFields[domain.StockRecommendation](c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
 	recommendations, err := h.recommendationSvc.GetCachedRecommendations(c.Request.Context())
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
+	etag := recommendationsETag(c.Request.Context(), recommendations, c.Request.URL.Query())
+	c.Header("ETag", etag)
+	// Replaces the global no-store so clients keep the entity and revalidate it with If-None-Match
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	recommendations = roundRecommendations(recommendations, h.cfg.JSONDecimalPlaces)
+
+	if fields != nil {
+		respondWithFields(c, recommendations, fields)
+		return
+	}
+
 	c.JSON(http.StatusOK, recommendations)
 }
 
-// TriggerIngestion manually triggers a full data ingestion process
+// GetAllRecommendations returns the complete ranked recommendation set one page
+// at a time, for browsing beyond the top recommendations
+func (h *Handlers) GetAllRecommendations(c *gin.Context) {
+	query := PageQuery{Page: 1, Limit: 20}
+	if err := bindQuery(c, &query); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	recommendations, err := h.recommendationSvc.GetCachedAllRecommendations(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	total := len(recommendations)
+	start := min((query.Page-1)*query.Limit, total)
+	end := min(start+query.Limit, total)
+	pagination := domain.Pagination{
+		Page:       query.Page,
+		Limit:      query.Limit,
+		TotalItems: total,
+		TotalPages: (total + query.Limit - 1) / query.Limit,
+	}
+
+	page := roundRecommendations(recommendations[start:end], h.cfg.JSONDecimalPlaces)
+	c.JSON(http.StatusOK, domain.NewPaginatedResponse(page, pagination))
+}
+
+// RecommendationsForTickersRequest is the body accepted by GetRecommendationsForTickers
+type RecommendationsForTickersRequest struct {
+	Tickers []string `json:"tickers"`
+}
+
+// GetRecommendationsForTickers scores only the requested tickers, such as a watchlist,
+// bypassing the cached top recommendations
+func (h *Handlers) GetRecommendationsForTickers(c *gin.Context) {
+	var req RecommendationsForTickersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("request body must be {\"tickers\": [...]}"))
+		return
+	}
+
+	tickers, err := normalizeSymbols(req.Tickers, "tickers")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	recommendations, err := h.recommendationSvc.GenerateRecommendationsForTickers(c.Request.Context(), tickers)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recommendations": roundRecommendations(recommendations, h.cfg.JSONDecimalPlaces),
+		"count":           len(recommendations),
+	})
+}
+
+// RefreshRecommendations forces regeneration of the recommendation cache
+func (h *Handlers) RefreshRecommendations(c *gin.Context) {
+	recommendations, err := h.recommendationSvc.RefreshRecommendations(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recommendations": roundRecommendations(recommendations, h.cfg.JSONDecimalPlaces),
+		"count":           len(recommendations),
+	})
+}
+
+// TriggerIngestion starts a full data ingestion run in the background and
+// returns a job ID that can be polled via GetIngestionJob. Only one run may be
+// in flight; a trigger while one is running returns 409 Conflict.
 func (h *Handlers) TriggerIngestion(c *gin.Context) {
+	job, started := h.ingestJobs.start(h.clock.Now())
+	if !started {
+		c.Header("Location", "/api/v1/ingest/"+job.ID)
+		HandleError(c, apperrors.ErrConflict.WithDetails(fmt.Sprintf("Ingestion job %s is already running", job.ID)))
+		return
+	}
+
+	// Gin cancels the request context once the 202 is written, so the run gets
+	// its own deadline and carries over only the request ID for log correlation
+	requestID := logging.RequestIDFromContext(c.Request.Context())
 	go func() {
-		if err := h.ingestionSvc.IngestAllData(c.Request.Context()); err != nil {
-			println("Ingestion error:", err.Error())
+		ctx, cancel := context.WithTimeout(logging.WithRequestID(context.Background(), requestID), ingestionJobTimeout)
+		defer cancel()
+
+		result, err := h.ingestionSvc.IngestAllData(ctx)
+		h.ingestJobs.finish(job.ID, h.clock.Now(), result, err)
+		if err != nil {
+			slog.ErrorContext(ctx, "triggered ingestion failed", "job_id", job.ID, "error", err)
+			return
 		}
+		slog.InfoContext(ctx, "triggered ingestion completed",
+			"job_id", job.ID,
+			"fetched", result.TotalFetched,
+			"inserted", result.Inserted,
+			"skipped_duplicates", result.SkippedDuplicates,
+		)
+		h.readiness.MarkIngested()
 	}()
 
+	c.Header("Location", "/api/v1/ingest/"+job.ID)
 	c.JSON(http.StatusAccepted, gin.H{
 		"message": "Data ingestion started",
 		"status":  "accepted",
+		"job_id":  job.ID,
 	})
 }
 
-// HealthCheck returns the health status of the service
-func (h *Handlers) HealthCheck(c *gin.Context) {
+// GetIngestionJob reports the status of a triggered ingestion run
+func (h *Handlers) GetIngestionJob(c *gin.Context) {
+	jobID := c.Param("jobID")
+
+	job, ok := h.ingestJobs.get(jobID)
+	if !ok {
+		HandleError(c, apperrors.ErrNotFound.WithDetails(fmt.Sprintf("Ingestion job %s not found", jobID)))
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// EnrichStocks triggers data enrichment for the requested tickers, or for all
+// known tickers when none are given
+func (h *Handlers) EnrichStocks(c *gin.Context) {
+	var tickers []string
+	for _, ticker := range strings.Split(c.Query("tickers"), ",") {
+		if ticker = strings.ToUpper(strings.TrimSpace(ticker)); ticker != "" {
+			tickers = append(tickers, ticker)
+		}
+	}
+
+	if len(tickers) == 0 {
+		var err error
+		tickers, err = h.stockRepo.GetUniqueTickers(c.Request.Context())
+		if err != nil {
+			HandleError(c, err)
+			return
+		}
+	}
+
+	refreshed, err := h.ingestionSvc.EnrichStockData(c.Request.Context(), tickers)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"service":   "stock-analyzer",
-		"timestamp": gin.H{"time": "now"},
+		"message":   "Data enrichment completed",
+		"tickers":   len(tickers),
+		"refreshed": refreshed,
 	})
 }
 
+// DeleteOldEnrichedData removes enriched stock data older than the given number of days
+func (h *Handlers) DeleteOldEnrichedData(c *gin.Context) {
+	days, err := parseIntQuery(c, "older_than_days", 30)
+	if err != nil || days < 1 {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("older_than_days must be a positive integer"))
+		return
+	}
+
+	deleted, err := h.stockRepo.DeleteOldEnrichedData(c.Request.Context(), h.clock.Now().AddDate(0, 0, -days))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted":         deleted,
+		"older_than_days": days,
+	})
+}
+
+// GetSchemaVersion reports the applied database schema version so deploys can be verified
+func (h *Handlers) GetSchemaVersion(c *gin.Context) {
+	if h.schemaRepo == nil {
+		HandleError(c, apperrors.ErrServiceUnavailable.WithDetails("schema version is not available for this storage backend"))
+		return
+	}
+
+	version, err := h.schemaRepo.GetSchemaVersion(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schema_version": version})
+}
+
 // parseIntQuery parses an integer query parameter with a default value
 func parseIntQuery(c *gin.Context, key string, defaultValue int) (int, error) {
 	str := c.Query(key)
@@ -1,19 +1,31 @@
 package api
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/internal/storage"
+	"stock-analyzer/pkg/clock"
+	"stock-analyzer/pkg/config"
 	apperrors "stock-analyzer/pkg/errors"
+	"stock-analyzer/pkg/locale"
+	"stock-analyzer/pkg/logging"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -29,26 +41,72 @@ func (m *MockStockRepository) CreateStockRating(ctx context.Context, rating *dom
 	return args.Error(0)
 }
 
+func (m *MockStockRepository) UpsertStockRating(ctx context.Context, rating *domain.StockRating) error {
+	args := m.Called(ctx, rating)
+	return args.Error(0)
+}
+
 func (m *MockStockRepository) CreateStockRatingsBatch(ctx context.Context, ratings []*domain.StockRating) (int, error) {
 	args := m.Called(ctx, ratings)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockStockRepository) CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ratings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 func (m *MockStockRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
 	args := m.Called(ctx, filters)
 	return args.Get(0).(*domain.PaginatedResponse[domain.StockRating]), args.Error(1)
 }
 
+func (m *MockStockRepository) StreamStockRatings(ctx context.Context, filters domain.FilterOptions, fn func(domain.StockRating) error) error {
+	args := m.Called(ctx, filters)
+	if ratings, ok := args.Get(0).([]domain.StockRating); ok {
+		for _, rating := range ratings {
+			if err := fn(rating); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockStockRepository) GetStockRatingsByTicker(ctx context.Context, ticker string) ([]domain.StockRating, error) {
 	args := m.Called(ctx, ticker)
 	return args.Get(0).([]domain.StockRating), args.Error(1)
 }
 
+func (m *MockStockRepository) GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]domain.StockRating, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.StockRating), args.Error(1)
+}
+
 func (m *MockStockRepository) GetUniqueTickers(ctx context.Context) ([]string, error) {
 	args := m.Called()
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockStockRepository) GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockStockRepository) GetRatingDistribution(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func (m *MockStockRepository) CreateEnrichedStockData(ctx context.Context, data *domain.EnrichedStockData) error {
 	args := m.Called(ctx, data)
 	return args.Error(0)
@@ -72,19 +130,32 @@ func (m *MockStockRepository) DeleteOldEnrichedData(ctx context.Context, olderTh
 	return args.Get(0).(int64), args.Error(1)
 }
 
+// MockAuditRepository is a mock implementation of domain.AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) CreateAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
 // MockIngestionService is a mock implementation of domain.IngestionService
 type MockIngestionService struct {
 	mock.Mock
 }
 
-func (m *MockIngestionService) IngestAllData(ctx context.Context) error {
+func (m *MockIngestionService) IngestAllData(ctx context.Context) (*domain.IngestionResult, error) {
 	args := m.Called(ctx)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IngestionResult), args.Error(1)
 }
 
-func (m *MockIngestionService) EnrichStockData(ctx context.Context, tickers []string) error {
+func (m *MockIngestionService) EnrichStockData(ctx context.Context, tickers []string) (int, error) {
 	args := m.Called(ctx, tickers)
-	return args.Error(0)
+	return args.Int(0), args.Error(1)
 }
 
 // MockRecommendationService is a mock implementation of domain.RecommendationService
@@ -97,11 +168,65 @@ func (m *MockRecommendationService) GenerateRecommendations(ctx context.Context)
 	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
 }
 
+func (m *MockRecommendationService) GenerateAllRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
+}
+
+func (m *MockRecommendationService) GetCachedAllRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
+}
+
+func (m *MockRecommendationService) GenerateRecommendationsForTickers(ctx context.Context, tickers []string) ([]domain.StockRecommendation, error) {
+	args := m.Called(ctx, tickers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
+}
+
 func (m *MockRecommendationService) GetCachedRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
 }
 
+func (m *MockRecommendationService) RefreshRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.StockRecommendation), args.Error(1)
+}
+
+func (m *MockRecommendationService) Subscribe() (<-chan []domain.StockRecommendation, func()) {
+	args := m.Called()
+	return args.Get(0).(chan []domain.StockRecommendation), args.Get(1).(func())
+}
+
+// MockSchemaRepository is a mock implementation of domain.SchemaRepository
+type MockSchemaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchemaRepository) GetSchemaVersion(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// MockHealthChecker is a mock implementation of domain.HealthChecker
+type MockHealthChecker struct {
+	mock.Mock
+}
+
+func (m *MockHealthChecker) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 // MockAlpacaService is a mock implementation of alpaca.Service
 type MockAlpacaService struct {
 	mock.Mock
@@ -120,6 +245,14 @@ func (m *MockAlpacaService) GetSnapshot(ctx context.Context, symbol string) (*do
 	return args.Get(0).(*domain.Snapshot), args.Error(1)
 }
 
+func (m *MockAlpacaService) GetSnapshots(ctx context.Context, symbols []string) (map[string]*domain.Snapshot, error) {
+	args := m.Called(ctx, symbols)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*domain.Snapshot), args.Error(1)
+}
+
 func (m *MockAlpacaService) GetRecentBars(ctx context.Context, symbol string) ([]domain.PriceBar, error) {
 	args := m.Called(ctx, symbol)
 	return args.Get(0).([]domain.PriceBar), args.Error(1)
@@ -130,13 +263,38 @@ func (m *MockAlpacaService) IsMarketHours() bool {
 	return args.Bool(0)
 }
 
+// MockWatchlistRepository is a mock implementation of domain.WatchlistRepository
+type MockWatchlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWatchlistRepository) AddToWatchlist(ctx context.Context, userID, ticker string) error {
+	args := m.Called(ctx, userID, ticker)
+	return args.Error(0)
+}
+
+func (m *MockWatchlistRepository) RemoveFromWatchlist(ctx context.Context, userID, ticker string) error {
+	args := m.Called(ctx, userID, ticker)
+	return args.Error(0)
+}
+
+func (m *MockWatchlistRepository) GetWatchlist(ctx context.Context, userID string) ([]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func setupTestHandlers() (*Handlers, *MockStockRepository, *MockIngestionService, *MockRecommendationService, *MockAlpacaService) {
 	stockRepo := &MockStockRepository{}
 	ingestionSvc := &MockIngestionService{}
 	recommendationSvc := &MockRecommendationService{}
 	alpacaSvc := &MockAlpacaService{}
 
-	handlers := NewHandlers(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc)
+	cfg := &config.Config{
+		LogoBaseURL:       config.DefaultLogoBaseURL,
+		JSONDecimalPlaces: config.DefaultJSONDecimalPlaces,
+	}
+
+	handlers := NewHandlers(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc, cfg)
 
 	return handlers, stockRepo, ingestionSvc, recommendationSvc, alpacaSvc
 }
@@ -146,17 +304,41 @@ func setupGinRouter(handlers *Handlers) *gin.Engine {
 	router := gin.New()
 
 	// Add middleware
+	router.Use(RequestID())
+	router.Use(Locale())
 	router.Use(ErrorHandler())
 
 	// Setup routes
 	v1 := router.Group("/api/v1")
 	{
 		v1.GET("/ratings", handlers.GetStockRatings)
+		v1.GET("/ratings/export", handlers.ExportStockRatings)
 		v1.GET("/ratings/:ticker", handlers.GetStockRatingsByTicker)
+		v1.GET("/analytics/brokerages", handlers.GetBrokerageLeaderboard)
+		v1.GET("/analytics/rating-distribution", handlers.GetRatingDistribution)
 		v1.GET("/recommendations", handlers.GetRecommendations)
+		v1.GET("/recommendations/all", handlers.GetAllRecommendations)
+		v1.POST("/recommendations/refresh", handlers.RefreshRecommendations)
+		v1.POST("/recommendations/for", handlers.GetRecommendationsForTickers)
+		v1.GET("/stocks/prices", handlers.GetBulkPrices)
 		v1.GET("/stocks/:symbol/price", handlers.GetStockPrice)
+		v1.GET("/stocks/:symbol/recent", handlers.GetRecentBars)
+		v1.GET("/stocks/default-logo", handlers.GetDefaultLogo)
 		v1.GET("/stocks/:symbol/logo", handlers.GetStockLogo)
+		v1.GET("/stocks/:symbol/snapshot", handlers.GetStockSnapshot)
+		v1.GET("/stocks/:symbol/stream", handlers.StreamStockSnapshots)
+		v1.GET("/recommendations/stream", handlers.StreamRecommendations)
+		v1.POST("/stocks/snapshots/warm", handlers.WarmSnapshots)
 		v1.POST("/ingest", handlers.TriggerIngestion)
+		v1.GET("/ingest/:jobID", handlers.GetIngestionJob)
+		v1.POST("/enrich", handlers.EnrichStocks)
+		v1.GET("/admin/schema-version", handlers.GetSchemaVersion)
+
+		watchlist := v1.Group("/watchlist", UserAPIKeyAuth(handlers.cfg.UserAPIKeys))
+		watchlist.GET("", handlers.GetWatchlist)
+		watchlist.GET("/recommendations", handlers.GetWatchlistRecommendations)
+		watchlist.POST("/:ticker", handlers.AddToWatchlist)
+		watchlist.DELETE("/:ticker", handlers.RemoveFromWatchlist)
 	}
 
 	return router
@@ -201,7 +383,7 @@ func TestGetStockRatings_Success(t *testing.T) {
 		},
 	}
 	stockRepo.On("GetStockRatings", mock.Anything, mock.MatchedBy(func(filters domain.FilterOptions) bool {
-		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "time" && filters.SortDesc && filters.Search == ""
+		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "time" && filters.Order == domain.SortDescending && filters.Search == ""
 	})).Return(expectedResponse, nil)
 
 	// Test request
@@ -221,6 +403,7 @@ func TestGetStockRatings_Success(t *testing.T) {
 	assert.Equal(t, 20, response.Pagination.Limit)
 	assert.Equal(t, 2, response.Pagination.TotalItems)
 	assert.Equal(t, 1, response.Pagination.TotalPages)
+	assert.Equal(t, "desc", response.Pagination.Order)
 
 	stockRepo.AssertExpectations(t)
 }
@@ -255,7 +438,7 @@ func TestGetStockRatings_WithPagination(t *testing.T) {
 		},
 	}
 	stockRepo.On("GetStockRatings", mock.Anything, mock.MatchedBy(func(filters domain.FilterOptions) bool {
-		return filters.Page == 2 && filters.Limit == 5 && filters.SortBy == "time" && filters.SortDesc && filters.Search == ""
+		return filters.Page == 2 && filters.Limit == 5 && filters.SortBy == "time" && filters.Order == domain.SortDescending && filters.Search == ""
 	})).Return(expectedResponse, nil)
 
 	// Test request with pagination
@@ -307,7 +490,7 @@ func TestGetStockRatings_WithSearch(t *testing.T) {
 		},
 	}
 	stockRepo.On("GetStockRatings", mock.Anything, mock.MatchedBy(func(filters domain.FilterOptions) bool {
-		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "time" && filters.SortDesc && filters.Search == "Apple"
+		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "time" && filters.Order == domain.SortDescending && filters.Search == "Apple"
 	})).Return(expectedResponse, nil)
 
 	// Test request with search
@@ -356,7 +539,7 @@ func TestGetStockRatings_WithSorting(t *testing.T) {
 		},
 	}
 	stockRepo.On("GetStockRatings", mock.Anything, mock.MatchedBy(func(filters domain.FilterOptions) bool {
-		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "ticker" && !filters.SortDesc && filters.Search == ""
+		return filters.Page == 1 && filters.Limit == 20 && filters.SortBy == "ticker" && filters.Order == domain.SortAscending && filters.Search == ""
 	})).Return(expectedResponse, nil)
 
 	// Test request with sorting
@@ -372,10 +555,55 @@ func TestGetStockRatings_WithSorting(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Len(t, response.Data, 1)
+	assert.Equal(t, "asc", response.Pagination.Order)
 
 	stockRepo.AssertExpectations(t)
 }
 
+func TestGetStockRatings_OrderReachesSQL(t *testing.T) {
+	t.Log("Testing GetStockRatings: the validated order parameter drives the repository's ORDER BY end-to-end")
+	cases := []struct {
+		query string
+		sql   string
+	}{
+		{"order=asc", `ORDER BY "ticker" ASC, rating_id ASC`},
+		{"order=ASC", `ORDER BY "ticker" ASC, rating_id ASC`},
+		{"order=desc", `ORDER BY "ticker" DESC, rating_id DESC`},
+		{"", `ORDER BY "ticker" DESC, rating_id DESC`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			cfg := &config.Config{JSONDecimalPlaces: config.DefaultJSONDecimalPlaces}
+			handlers := NewHandlers(storage.NewPostgresRepository(db), &MockIngestionService{}, &MockRecommendationService{}, &MockAlpacaService{}, cfg)
+			router := setupGinRouter(handlers)
+
+			dbMock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			dbMock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  `+tc.sql+` LIMIT $1 OFFSET $2`).
+				WithArgs(20, 0).
+				WillReturnRows(sqlmock.NewRows([]string{
+					"rating_id", "ticker", "company", "brokerage", "action",
+					"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+				}))
+
+			req, _ := http.NewRequest("GET", "/api/v1/ratings?sort_by=ticker&"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestGetStockRatings_InvalidParameters(t *testing.T) {
 	t.Log("Testing GetStockRatings: with invalid pagination parameters")
 	handlers, stockRepo, _, _, _ := setupTestHandlers()
@@ -407,6 +635,188 @@ func TestGetStockRatings_InvalidParameters(t *testing.T) {
 	stockRepo.AssertExpectations(t)
 }
 
+func TestGetStockRatings_InvalidOrder(t *testing.T) {
+	t.Log("Testing GetStockRatings: with an unsupported sort order")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, order := range []string{"descending", "up", "1"} {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings?order="+order, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResp ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &errorResp)
+		require.NoError(t, err)
+		assert.Equal(t, apperrors.ErrCodeValidation, errorResp.Code)
+		assert.Contains(t, errorResp.Details, "invalid order parameter")
+	}
+
+	stockRepo.AssertNotCalled(t, "GetStockRatings", mock.Anything, mock.Anything)
+}
+
+func TestGetStockRatings_QueryBinding(t *testing.T) {
+	t.Log("Testing GetStockRatings: binds and validates typed query parameters")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetStockRatings", mock.Anything, domain.FilterOptions{
+		Page:   3,
+		Limit:  100,
+		Search: "Apple",
+		SortBy: "ticker",
+		Order:  domain.SortAscending,
+	}).Return(&domain.PaginatedResponse[domain.StockRating]{
+		Data:       []domain.StockRating{},
+		Pagination: domain.Pagination{Page: 3, Limit: 100},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings?page=3&limit=100&search=Apple&sort_by=ticker&order=ASC", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestGetStockRatings_QueryConstraintViolations(t *testing.T) {
+	t.Log("Testing GetStockRatings: in strict mode out-of-range query parameters are validation errors")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	handlers.cfg.StrictPagination = true
+	router := setupGinRouter(handlers)
+
+	tests := []struct {
+		query   string
+		details string
+	}{
+		{"page=0", "invalid page parameter: must be at least 1"},
+		{"limit=0", "invalid limit parameter: must be at least 1"},
+		{"limit=101", "invalid limit parameter: must be at most 100"},
+		{"order=sideways", "invalid order parameter: must be one of 'asc', 'desc'"},
+		{"page=0&limit=500", "invalid page parameter: must be at least 1; invalid limit parameter: must be at most 100"},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.query)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, apperrors.ErrCodeValidation, errorResp.Code, tt.query)
+		assert.Equal(t, tt.details, errorResp.Details, tt.query)
+	}
+
+	stockRepo.AssertNotCalled(t, "GetStockRatings", mock.Anything, mock.Anything)
+}
+
+func TestGetStockRatings_ClampsPaginationByDefault(t *testing.T) {
+	t.Log("Testing GetStockRatings: by default out-of-range pagination is pulled into range")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	tests := []struct {
+		query string
+		page  int
+		limit int
+	}{
+		{"page=0", 1, 20},
+		{"page=-3&limit=0", 1, 1},
+		{"page=2&limit=500", 2, 100},
+		{"page=4&limit=50", 4, 50},
+	}
+
+	for _, tt := range tests {
+		filters := domain.FilterOptions{Page: tt.page, Limit: tt.limit, SortBy: "time", Order: domain.SortDescending}
+		stockRepo.On("GetStockRatings", mock.Anything, filters).Return(&domain.PaginatedResponse[domain.StockRating]{
+			Data:       []domain.StockRating{},
+			Pagination: domain.Pagination{Page: tt.page, Limit: tt.limit},
+		}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/api/v1/ratings?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.query)
+	}
+
+	// Malformed values and bad sort orders are still rejected
+	for _, query := range []string{"page=abc", "order=sideways"} {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestGetStockRatings_StrictPaginationQuery(t *testing.T) {
+	t.Log("Testing GetStockRatings: the strict_pagination query flag overrides the configured mode per request")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings?limit=500&strict_pagination=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, "invalid limit parameter: must be at most 100", errorResp.Details)
+	stockRepo.AssertNotCalled(t, "GetStockRatings", mock.Anything, mock.Anything)
+
+	// A client can opt back into clamping when the server is strict
+	handlers.cfg.StrictPagination = true
+	filters := domain.FilterOptions{Page: 1, Limit: 100, SortBy: "time", Order: domain.SortDescending}
+	stockRepo.On("GetStockRatings", mock.Anything, filters).Return(&domain.PaginatedResponse[domain.StockRating]{
+		Data:       []domain.StockRating{},
+		Pagination: domain.Pagination{Page: 1, Limit: 100},
+	}, nil).Once()
+
+	req, _ = http.NewRequest("GET", "/api/v1/ratings?limit=500&strict_pagination=false", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stockRepo.AssertExpectations(t)
+
+	req, _ = http.NewRequest("GET", "/api/v1/ratings?strict_pagination=maybe", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetStockRatings_EmptyResultSerializesAsArray(t *testing.T) {
+	t.Log("Testing GetStockRatings: an empty page serializes data as [] rather than null")
+	for _, path := range []string{"/api/v1/ratings", "/api/v1/ratings?fields=ticker"} {
+		t.Run(path, func(t *testing.T) {
+			handlers, stockRepo, _, _, _ := setupTestHandlers()
+			router := setupGinRouter(handlers)
+
+			stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(&domain.PaginatedResponse[domain.StockRating]{
+				Pagination: domain.Pagination{Page: 1, Limit: 20},
+			}, nil)
+
+			req, _ := http.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var raw map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+			assert.JSONEq(t, "[]", string(raw["data"]))
+		})
+	}
+}
+
 func TestGetStockRatings_DatabaseError(t *testing.T) {
 	t.Log("Testing GetStockRatings: repository returns an error")
 	handlers, stockRepo, _, _, _ := setupTestHandlers()
@@ -526,9 +936,112 @@ func TestGetStockPrice_Success(t *testing.T) {
 	assert.Equal(t, 100.0, response.Bars[0].Open)
 	assert.Equal(t, 105.5, response.Bars[1].Close)
 
+	// Summary is computed from the bars above
+	require.NotNil(t, response.Summary)
+	assert.Equal(t, 100.0, response.Summary.First)
+	assert.Equal(t, 105.5, response.Summary.Last)
+	assert.Equal(t, 106.0, response.Summary.High)
+	assert.Equal(t, 99.0, response.Summary.Low)
+	assert.Equal(t, int64(1800000), response.Summary.TotalVolume)
+	assert.InDelta(t, 5.5, response.Summary.ReturnPercent, 1e-9)
+
 	alpacaSvc.AssertExpectations(t)
 }
 
+func TestGetStockPrice_Downsampled(t *testing.T) {
+	t.Log("Testing GetStockPrice: points parameter merges bars into fewer OHLC buckets")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	priceBars := make([]domain.PriceBar, 10)
+	for i := range priceBars {
+		price := float64(100 + i)
+		priceBars[i] = domain.PriceBar{
+			Timestamp: fmt.Sprintf("2023-12-01T%02d:00:00Z", i),
+			Open:      price,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price + 0.5,
+			Volume:    100,
+		}
+	}
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "AAPL", "1Hour", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(priceBars, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price?points=3", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response StockPriceResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	require.Len(t, response.Bars, 3)
+	assert.Equal(t, "2023-12-01T00:00:00Z", response.Bars[0].Timestamp)
+	assert.Equal(t, 109.5, response.Bars[2].Close)
+
+	// The summary still covers every bar
+	require.NotNil(t, response.Summary)
+	assert.Equal(t, int64(1000), response.Summary.TotalVolume)
+
+	for _, points := range []string{"0", "-5", "many"} {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price?points="+points, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, points)
+		assert.Contains(t, w.Body.String(), "invalid points parameter", points)
+	}
+}
+
+func TestDownsampleBars(t *testing.T) {
+	t.Log("Testing utility: downsampleBars bucket boundaries and OHLC merging")
+
+	bars := make([]domain.PriceBar, 10)
+	for i := range bars {
+		price := float64(i)
+		bars[i] = domain.PriceBar{
+			Timestamp: fmt.Sprintf("t%d", i),
+			Open:      price,
+			High:      price + 10,
+			Low:       price - 10,
+			Close:     price + 0.5,
+			Volume:    int64(i + 1),
+		}
+	}
+
+	// 10 bars into 3 buckets split as [0,3) [3,6) [6,10)
+	sampled := downsampleBars(bars, 3)
+	require.Len(t, sampled, 3)
+
+	assert.Equal(t, domain.PriceBar{Timestamp: "t0", Open: 0, High: 12, Low: -10, Close: 2.5, Volume: 6}, sampled[0])
+	assert.Equal(t, domain.PriceBar{Timestamp: "t3", Open: 3, High: 15, Low: -7, Close: 5.5, Volume: 15}, sampled[1])
+	assert.Equal(t, domain.PriceBar{Timestamp: "t6", Open: 6, High: 19, Low: -4, Close: 9.5, Volume: 34}, sampled[2])
+
+	// Even split: 10 bars into 5 buckets of two
+	sampled = downsampleBars(bars, 5)
+	require.Len(t, sampled, 5)
+	for i, bar := range sampled {
+		assert.Equal(t, fmt.Sprintf("t%d", i*2), bar.Timestamp)
+		assert.Equal(t, int64(i*4+3), bar.Volume)
+	}
+
+	// A single point spans every bar
+	sampled = downsampleBars(bars, 1)
+	require.Len(t, sampled, 1)
+	assert.Equal(t, 0.0, sampled[0].Open)
+	assert.Equal(t, 9.5, sampled[0].Close)
+	assert.Equal(t, int64(55), sampled[0].Volume)
+
+	// Nothing to reduce
+	assert.Equal(t, bars, downsampleBars(bars, 10))
+	assert.Equal(t, bars, downsampleBars(bars, 50))
+	assert.Equal(t, bars, downsampleBars(bars, 0))
+	assert.Empty(t, downsampleBars(nil, 3))
+}
+
 func TestGetStockPrice_DifferentPeriods(t *testing.T) {
 	t.Log("Testing GetStockPrice: handling different time periods")
 	handlers, _, _, _, alpacaSvc := setupTestHandlers()
@@ -600,23 +1113,134 @@ func TestGetStockPrice_NoData(t *testing.T) {
 	alpacaSvc.AssertExpectations(t)
 }
 
-func TestGetStockPrice_AlpacaError(t *testing.T) {
-	t.Log("Testing GetStockPrice: when Alpaca service returns an error")
+func TestGetStockPrice_FallbackToWiderWindow(t *testing.T) {
+	t.Log("Testing GetStockPrice: falls back to the maximum window when the requested period is empty")
 	handlers, _, _, _, alpacaSvc := setupTestHandlers()
 	router := setupGinRouter(handlers)
 
-	alpacaSvc.On("GetHistoricalBars", mock.Anything, "AAPL", "1Hour", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]domain.PriceBar{}, fmt.Errorf("API error"))
+	priceBars := []domain.PriceBar{
+		{
+			Timestamp: "2023-12-01T00:00:00Z",
+			Open:      20.0,
+			High:      22.0,
+			Low:       19.5,
+			Close:     21.0,
+			Volume:    50000,
+		},
+	}
 
-	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price", nil)
+	// Requested 1M window has no data, only the wider window does
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "NEWIPO", "1Hour", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]domain.PriceBar{}, nil).Once()
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "NEWIPO", "1Day", mock.MatchedBy(func(start time.Time) bool {
+		return time.Since(start) > 2*365*24*time.Hour
+	}), mock.AnythingOfType("time.Time")).Return(priceBars, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/NEWIPO/price?period=1M&fallback=true", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-
-	alpacaSvc.AssertExpectations(t)
-}
+	assert.Equal(t, http.StatusOK, w.Code)
 
-func TestGetStockLogo_Success(t *testing.T) {
+	var response StockPriceResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "NEWIPO", response.Symbol)
+	assert.True(t, response.Partial)
+	assert.Len(t, response.Bars, 1)
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetStockPrice_FallbackStillEmpty(t *testing.T) {
+	t.Log("Testing GetStockPrice: returns 404 when the fallback window is also empty")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "NODATA", mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]domain.PriceBar{}, nil).Twice()
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/NODATA/price?fallback=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetStockPrice_AlpacaError(t *testing.T) {
+	t.Log("Testing GetStockPrice: when Alpaca service returns an error")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "AAPL", "1Hour", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]domain.PriceBar{}, fmt.Errorf("API error"))
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetRecentBars_Success(t *testing.T) {
+	t.Log("Testing GetRecentBars: successful retrieval with uppercased symbol")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	priceBars := []domain.PriceBar{
+		{
+			Timestamp: "2023-12-01T09:30:00Z",
+			Open:      100.0,
+			High:      105.0,
+			Low:       99.0,
+			Close:     104.0,
+			Volume:    1000000,
+		},
+	}
+
+	alpacaSvc.On("GetRecentBars", mock.Anything, "AAPL").Return(priceBars, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/aapl/recent", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response StockPriceResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "AAPL", response.Symbol)
+	assert.Len(t, response.Bars, 1)
+	assert.Equal(t, 104.0, response.Bars[0].Close)
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetRecentBars_NoData(t *testing.T) {
+	t.Log("Testing GetRecentBars: when Alpaca service returns no bars")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	alpacaSvc.On("GetRecentBars", mock.Anything, "AAPL").Return([]domain.PriceBar{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/recent", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var errorResp ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &errorResp)
+	require.NoError(t, err)
+	assert.Contains(t, errorResp.Details, "No recent price data available")
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetStockLogo_Success(t *testing.T) {
 	t.Log("Testing GetStockLogo: successful retrieval of logo URL")
 	handlers, _, _, _, _ := setupTestHandlers()
 	router := setupGinRouter(handlers)
@@ -632,12 +1256,165 @@ func TestGetStockLogo_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "AAPL", response.Symbol)
-	assert.Contains(t, response.LogoURL, "clearbit.com")
-	assert.Contains(t, response.LogoURL, "aapl.com")
+	assert.Equal(t, "https://logo.clearbit.com/apple.com", response.LogoURL)
 
 	// Check cache headers
 	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
 	assert.Equal(t, `"AAPL"`, w.Header().Get("ETag"))
+
+	// Override the template to point at an internal logo service
+	handlers.cfg.LogoBaseURL = "https://assets.internal.example/logos/%s.png"
+
+	req, _ = http.NewRequest("GET", "/api/v1/stocks/msft/logo", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "MSFT", response.Symbol)
+	assert.Equal(t, "https://assets.internal.example/logos/msft.png", response.LogoURL)
+}
+
+func TestResolveLogoDomain(t *testing.T) {
+	t.Log("Testing utility: resolveLogoDomain")
+
+	tests := []struct {
+		symbol   string
+		expected string
+	}{
+		{"GOOGL", "abc.xyz"},
+		{"META", "meta.com"},
+		{"BRK.B", "berkshirehathaway.com"},
+		{"brk.b", "berkshirehathaway.com"},
+		{"XYZ", "xyz.com"},
+		{"abcd", "abcd.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveLogoDomain(tt.symbol))
+		})
+	}
+}
+
+func TestGetStockLogo_UnmappedTicker(t *testing.T) {
+	t.Log("Testing GetStockLogo: unmapped tickers fall back to <ticker>.com")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/xyz/logo", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response StockLogoResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://logo.clearbit.com/xyz.com", response.LogoURL)
+}
+
+func TestGetDefaultLogo(t *testing.T) {
+	t.Log("Testing GetDefaultLogo: serves the embedded placeholder as SVG")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/default-logo", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, defaultLogoSVG, w.Body.Bytes())
+	assert.Contains(t, w.Body.String(), "<svg")
+}
+
+func TestGetStockLogo_RedirectFormat(t *testing.T) {
+	t.Log("Testing GetStockLogo: redirect format falls back to the default logo for unmapped tickers")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	tests := []struct {
+		symbol   string
+		location string
+	}{
+		{"AAPL", "https://logo.clearbit.com/apple.com"},
+		{"XYZ", defaultLogoPath},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/"+tt.symbol+"/logo?format=redirect", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code, tt.symbol)
+		assert.Equal(t, tt.location, w.Header().Get("Location"), tt.symbol)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/logo?format=png", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetStockLogo_Sizes(t *testing.T) {
+	t.Log("Testing GetStockLogo: size parameter is validated and passed to the provider")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, size := range []string{"32", "64", "128"} {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/logo?size="+size, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, size)
+
+		var response StockLogoResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://logo.clearbit.com/apple.com?size="+size, response.LogoURL, size)
+		assert.Equal(t, `"AAPL-`+size+`"`, w.Header().Get("ETag"), size)
+	}
+
+	for _, size := range []string{"500", "abc", "0"} {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/logo?size="+size, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, size)
+
+		var errorResp ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &errorResp)
+		require.NoError(t, err)
+		assert.Contains(t, errorResp.Details, "invalid size parameter", size)
+	}
+
+	// Templates with a {size} placeholder receive the size directly
+	handlers.cfg.LogoBaseURL = "https://assets.internal.example/logos/%s-{size}.png"
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/msft/logo?size=64&format=redirect", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://assets.internal.example/logos/msft-64.png", w.Header().Get("Location"))
+
+	// Providers without size support keep their URL unchanged
+	handlers.cfg.LogoBaseURL = "https://assets.internal.example/logos/%s.png"
+
+	req, _ = http.NewRequest("GET", "/api/v1/stocks/msft/logo?size=32", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response StockLogoResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "https://assets.internal.example/logos/msft.png", response.LogoURL)
 }
 
 func TestGetStockLogo_MissingSymbol(t *testing.T) {
@@ -711,290 +1488,2911 @@ func TestGetRecommendations_ServiceError(t *testing.T) {
 	recommendationSvc.AssertExpectations(t)
 }
 
-func TestTriggerIngestion_Success(t *testing.T) {
-	t.Log("Testing TriggerIngestion: successfully triggers ingestion service")
-	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+func TestGetRecommendations_ETag(t *testing.T) {
+	t.Log("Testing GetRecommendations: response carries an ETag and honors If-None-Match")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
 	router := setupGinRouter(handlers)
 
-	// The handler starts ingestion asynchronously, so we need to handle this carefully
-	ingestionSvc.On("IngestAllData", mock.Anything).Return(nil).Maybe()
+	recommendations := []domain.StockRecommendation{
+		{
+			Ticker:      "AAPL",
+			Company:     "Apple Inc.",
+			Score:       0.85,
+			GeneratedAt: time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC),
+		},
+	}
 
-	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return(recommendations, nil)
+
+	// First request returns the payload with an ETag
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
 
-	var response map[string]interface{}
+	var response []domain.StockRecommendation
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
+	assert.Len(t, response, 1)
 
-	assert.Equal(t, "Data ingestion started", response["message"])
-	assert.Equal(t, "accepted", response["status"])
+	// Revalidating with the same ETag yields 304 without a body
+	req, _ = http.NewRequest("GET", "/api/v1/recommendations", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-	// Wait a bit for the goroutine to potentially start
-	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.Bytes())
+
+	// Different query parameters produce a different representation
+	req, _ = http.NewRequest("GET", "/api/v1/recommendations?fields=ticker", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// A different locale produces a different representation
+	req, _ = http.NewRequest("GET", "/api/v1/recommendations", nil)
+	req.Header.Set("Accept-Language", "de-DE")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	recommendationSvc.AssertExpectations(t)
 }
 
-func TestHealthCheck(t *testing.T) {
-	t.Log("Testing HealthCheck: endpoint returns OK")
-	handlers, _, _, _, _ := setupTestHandlers()
-	router := setupGinRouter(handlers)
+func TestGetRecommendations_CacheControlAllowsRevalidation(t *testing.T) {
+	t.Log("Testing GetRecommendations: the route overrides the global no-store so clients can revalidate the ETag")
+	gin.SetMode(gin.TestMode)
+	recommendationSvc := &MockRecommendationService{}
+	router := SetupRouter(&MockStockRepository{}, &MockIngestionService{}, recommendationSvc, &MockAlpacaService{}, &config.Config{}, nil)
 
-	req, _ := http.NewRequest("GET", "/health", nil)
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return([]domain.StockRecommendation{{Ticker: "AAPL"}}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations", nil)
 	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
 
-	// Add health check route
-	router.GET("/health", handlers.HealthCheck)
+	req, _ = http.NewRequest("GET", "/api/v1/recommendations", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+}
+
+func TestGetRecommendations_WithFields(t *testing.T) {
+	t.Log("Testing GetRecommendations: sparse fieldset only returns requested fields")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendations := []domain.StockRecommendation{
+		{
+			Ticker:          "AAPL",
+			Company:         "Apple Inc.",
+			Score:           0.85,
+			Rationale:       "Strong fundamentals",
+			LatestRating:    "Buy",
+			TechnicalSignal: "Golden Cross",
+			GeneratedAt:     time.Now(),
+		},
+	}
+
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return(recommendations, nil)
 
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations?fields=ticker,score,rationale", nil)
+	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response map[string]interface{}
+	var response []map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 
-	assert.Equal(t, "healthy", response["status"])
-	assert.Equal(t, "stock-analyzer", response["service"])
-	assert.Contains(t, response, "timestamp")
+	require.Len(t, response, 1)
+	assert.Len(t, response[0], 3)
+	assert.Equal(t, "AAPL", response[0]["ticker"])
+	assert.Equal(t, 0.85, response[0]["score"])
+	assert.Equal(t, "Strong fundamentals", response[0]["rationale"])
+	assert.NotContains(t, response[0], "company")
+
+	recommendationSvc.AssertExpectations(t)
 }
 
-func TestParseIntQuery(t *testing.T) {
-	t.Log("Testing utility: ParseIntQuery")
-	gin.SetMode(gin.TestMode)
+func TestGetRecommendations_WithUnknownField(t *testing.T) {
+	t.Log("Testing GetRecommendations: unknown field in sparse fieldset is rejected")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
 
-	tests := []struct {
-		name         string
-		queryValue   string
-		defaultValue int
-		expected     int
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations?fields=ticker,password", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errorResp ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &errorResp)
+	require.NoError(t, err)
+	assert.Equal(t, apperrors.ErrCodeValidation, errorResp.Code)
+	assert.Contains(t, errorResp.Details, "password")
+
+	recommendationSvc.AssertNotCalled(t, "GetCachedRecommendations", mock.Anything)
+}
+
+func TestGetStockRatings_WithFields(t *testing.T) {
+	t.Log("Testing GetStockRatings: sparse fieldset keeps pagination metadata")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	expectedResponse := &domain.PaginatedResponse[domain.StockRating]{
+		Data: []domain.StockRating{
+			{
+				RatingID:  uuid.New(),
+				Ticker:    "AAPL",
+				Company:   "Apple Inc.",
+				Brokerage: "Goldman Sachs",
+				Action:    "upgraded by",
+				RatingTo:  "Buy",
+				Time:      time.Now(),
+				CreatedAt: time.Now(),
+			},
+		},
+		Pagination: domain.Pagination{Page: 1, Limit: 20, TotalItems: 1, TotalPages: 1},
+	}
+	stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings?fields=ticker,rating_to", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.PaginatedResponse[map[string]interface{}]
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, map[string]interface{}{"ticker": "AAPL", "rating_to": "Buy"}, response.Data[0])
+	assert.Equal(t, 1, response.Pagination.TotalItems)
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestTriggerIngestion_Success(t *testing.T) {
+	t.Log("Testing TriggerIngestion: successfully triggers ingestion service")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	// The handler starts ingestion asynchronously, so we need to handle this carefully
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(&domain.IngestionResult{}, nil).Maybe()
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Data ingestion started", response["message"])
+	assert.Equal(t, "accepted", response["status"])
+
+	// Wait a bit for the goroutine to potentially start
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestTriggerIngestion_JobSucceeds(t *testing.T) {
+	t.Log("Testing TriggerIngestion: returns a job ID whose status reaches succeeded with counts")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	release := make(chan struct{})
+	ingestionSvc.On("IngestAllData", mock.Anything).
+		Return(&domain.IngestionResult{TotalFetched: 5, Inserted: 3, SkippedDuplicates: 2}, nil).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline, "ingestion should run under its own deadline")
+			<-release
+		})
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	jobID, _ := started["job_id"].(string)
+	require.NotEmpty(t, jobID)
+	_, err := uuid.Parse(jobID)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/ingest/"+jobID, w.Header().Get("Location"))
+
+	poll := func() IngestionJob {
+		req, _ := http.NewRequest("GET", "/api/v1/ingest/"+jobID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var job IngestionJob
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		return job
+	}
+
+	assert.Equal(t, IngestionJobRunning, poll().Status)
+
+	close(release)
+	assert.Eventually(t, func() bool { return poll().Status == IngestionJobSucceeded }, time.Second, 5*time.Millisecond)
+
+	job := poll()
+	require.NotNil(t, job.Result)
+	assert.Equal(t, 5, job.Result.TotalFetched)
+	assert.Equal(t, 3, job.Result.Inserted)
+	assert.Equal(t, 2, job.Result.SkippedDuplicates)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Empty(t, job.Error)
+}
+
+func TestTriggerIngestion_OutlivesRequestContext(t *testing.T) {
+	t.Log("Testing TriggerIngestion: ingestion completes after the request context is cancelled")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	release := make(chan struct{})
+	ctxErr := make(chan error, 1)
+	ingestionSvc.On("IngestAllData", mock.MatchedBy(func(ctx context.Context) bool {
+		return logging.RequestIDFromContext(ctx) == "req-ingest"
+	})).Return(&domain.IngestionResult{Inserted: 1}, nil).Run(func(args mock.Arguments) {
+		<-release
+		ctxErr <- args.Get(0).(context.Context).Err()
+	})
+
+	reqCtx, cancelRequest := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(reqCtx, "POST", "/api/v1/ingest", nil)
+	req.Header.Set(RequestIDHeader, "req-ingest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	// Simulate the server tearing down the request once the response is sent
+	cancelRequest()
+	close(release)
+
+	select {
+	case err := <-ctxErr:
+		assert.NoError(t, err, "ingestion context should not inherit the request's cancellation")
+	case <-time.After(time.Second):
+		t.Fatal("ingestion did not run")
+	}
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	jobID := started["job_id"].(string)
+	assert.Eventually(t, func() bool {
+		job, ok := handlers.ingestJobs.get(jobID)
+		return ok && job.Status == IngestionJobSucceeded
+	}, time.Second, 5*time.Millisecond)
+	ingestionSvc.AssertExpectations(t)
+}
+
+func TestTriggerIngestion_JobFails(t *testing.T) {
+	t.Log("Testing TriggerIngestion: a failed run is reported with its error")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(nil, fmt.Errorf("upstream unavailable"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	jobID := started["job_id"].(string)
+
+	var job IngestionJob
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/api/v1/ingest/"+jobID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &job) != nil {
+			return false
+		}
+		return job.Status == IngestionJobFailed
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, "upstream unavailable", job.Error)
+	assert.Nil(t, job.Result)
+}
+
+func TestTriggerIngestion_JobKeepsPartialResult(t *testing.T) {
+	t.Log("Testing TriggerIngestion: a run cut short by its time budget reports its partial counts")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	partial := &domain.IngestionResult{TotalFetched: 4, Inserted: 3, SkippedDuplicates: 1}
+	ingestionSvc.On("IngestAllData", mock.Anything).
+		Return(partial, apperrors.New(apperrors.ErrCodeTimeout, "ingestion time budget exhausted"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	jobID := started["job_id"].(string)
+
+	var job IngestionJob
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/api/v1/ingest/"+jobID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &job) != nil {
+			return false
+		}
+		return job.Status == IngestionJobFailed
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, partial, job.Result)
+	assert.Contains(t, job.Error, "time budget")
+}
+
+func TestGetIngestionJob_NotFound(t *testing.T) {
+	t.Log("Testing GetIngestionJob: unknown job IDs return 404")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ingest/"+uuid.NewString(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestIngestionJobs_EvictsOldest(t *testing.T) {
+	t.Log("Testing ingestionJobs: the registry evicts the oldest job once full")
+	jobs := newIngestionJobs()
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	var ids []string
+	for i := 0; i < maxRetainedIngestionJobs; i++ {
+		job, started := jobs.start(now)
+		require.True(t, started)
+		jobs.finish(job.ID, now, &domain.IngestionResult{}, nil)
+		ids = append(ids, job.ID)
+	}
+
+	latest, started := jobs.start(now)
+	require.True(t, started)
+
+	_, ok := jobs.get(ids[0])
+	assert.False(t, ok, "oldest job should be evicted")
+	_, ok = jobs.get(ids[1])
+	assert.True(t, ok)
+	_, ok = jobs.get(latest.ID)
+	assert.True(t, ok)
+}
+
+func TestTriggerIngestion_ConcurrentRequestsStartOneRun(t *testing.T) {
+	t.Log("Testing TriggerIngestion: concurrent triggers start exactly one run; the rest get 409")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	release := make(chan struct{})
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(&domain.IngestionResult{}, nil).Run(func(mock.Arguments) { <-release })
+
+	const requests = 2
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, 1, counts[http.StatusAccepted])
+	assert.Equal(t, 1, counts[http.StatusConflict])
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		_, started := handlers.ingestJobs.start(time.Now())
+		return started
+	}, time.Second, 5*time.Millisecond, "the lock should be released once the run completes")
+	ingestionSvc.AssertNumberOfCalls(t, "IngestAllData", 1)
+}
+
+func TestTriggerIngestion_ReleasesLockAfterFailure(t *testing.T) {
+	t.Log("Testing TriggerIngestion: a failed run releases the lock so a new run can start")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(nil, fmt.Errorf("upstream unavailable")).Once()
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(&domain.IngestionResult{}, nil).Once()
+
+	trigger := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusAccepted, trigger().Code)
+
+	var second *httptest.ResponseRecorder
+	require.Eventually(t, func() bool {
+		second = trigger()
+		return second.Code == http.StatusAccepted
+	}, time.Second, 5*time.Millisecond)
+
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(second.Body.Bytes(), &started))
+	jobID := started["job_id"].(string)
+	assert.Eventually(t, func() bool {
+		job, ok := handlers.ingestJobs.get(jobID)
+		return ok && job.Status == IngestionJobSucceeded
+	}, time.Second, 5*time.Millisecond)
+	ingestionSvc.AssertExpectations(t)
+}
+
+func TestHealthCheck(t *testing.T) {
+	t.Log("Testing HealthCheck: endpoint returns OK")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	// Add health check route
+	router.GET("/health", handlers.HealthCheck)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "healthy", response["status"])
+	assert.Equal(t, "stock-analyzer", response["service"])
+	assert.Contains(t, response, "timestamp")
+}
+
+func TestHealthCheck_DatabaseUp(t *testing.T) {
+	t.Log("Testing HealthCheck: pings the database with a deadline and reports healthy")
+	handlers, _, _, _, _ := setupTestHandlers()
+	checker := new(MockHealthChecker)
+	handlers.SetHealthChecker(checker)
+
+	checker.On("Ping", mock.MatchedBy(func(ctx context.Context) bool {
+		_, hasDeadline := ctx.Deadline()
+		return hasDeadline
+	})).Return(nil)
+
+	router := gin.New()
+	router.GET("/health", handlers.HealthCheck)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "healthy", response["status"])
+
+	timestamp, ok := response["timestamp"].(string)
+	require.True(t, ok)
+	_, err := time.Parse(time.RFC3339, timestamp)
+	assert.NoError(t, err)
+
+	checker.AssertExpectations(t)
+}
+
+func TestHealthCheck_DatabaseDown(t *testing.T) {
+	t.Log("Testing HealthCheck: returns 503 unhealthy when the database ping fails")
+	handlers, _, _, _, _ := setupTestHandlers()
+	checker := new(MockHealthChecker)
+	handlers.SetHealthChecker(checker)
+
+	checker.On("Ping", mock.Anything).
+		Return(apperrors.Wrap(fmt.Errorf("connection refused"), apperrors.ErrCodeDatabase, "failed to ping database"))
+
+	router := gin.New()
+	router.GET("/health", handlers.HealthCheck)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "unhealthy", response["status"])
+	assert.Equal(t, apperrors.ErrCodeUnavailable, response["code"])
+	assert.Contains(t, response, "timestamp")
+}
+
+func TestHealthCheck_CachesPingWithinWindow(t *testing.T) {
+	t.Log("Testing health checks: the database is pinged at most once per cache window across probes")
+	handlers, _, _, _, _ := setupTestHandlers()
+	fakeClock := clock.NewFake(time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC))
+	handlers.SetClock(fakeClock)
+	handlers.SetHealthCheckCacheTTL(5 * time.Second)
+	checker := new(MockHealthChecker)
+	handlers.SetHealthChecker(checker)
+
+	checker.On("Ping", mock.Anything).Return(nil).Once()
+
+	router := gin.New()
+	router.GET("/health", handlers.HealthCheck)
+	router.GET("/health/ready", handlers.ReadinessCheck)
+
+	probe := func(path string) int {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := "/health"
+			if i%2 == 0 {
+				path = "/health/ready"
+			}
+			assert.Equal(t, http.StatusOK, probe(path))
+		}()
+	}
+	wg.Wait()
+
+	fakeClock.Advance(5*time.Second - time.Millisecond)
+	assert.Equal(t, http.StatusOK, probe("/health/ready"))
+	checker.AssertNumberOfCalls(t, "Ping", 1)
+
+	// Once the window passes, an outage is reported on the next probe and then cached
+	checker.On("Ping", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	fakeClock.Advance(time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, probe("/health"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe("/health/ready"))
+	checker.AssertNumberOfCalls(t, "Ping", 2)
+}
+
+func TestHealthCheck_NoCacheWindowPingsEveryProbe(t *testing.T) {
+	t.Log("Testing health checks: without a cache window every probe pings the database")
+	handlers, _, _, _, _ := setupTestHandlers()
+	checker := new(MockHealthChecker)
+	handlers.SetHealthChecker(checker)
+
+	checker.On("Ping", mock.Anything).Return(nil)
+
+	router := gin.New()
+	router.GET("/health", handlers.HealthCheck)
+
+	for range 3 {
+		req, _ := http.NewRequest("GET", "/health", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	checker.AssertNumberOfCalls(t, "Ping", 3)
+}
+
+func TestLivenessCheck(t *testing.T) {
+	t.Log("Testing LivenessCheck: always 200, even when the database is down")
+	handlers, _, _, _, _ := setupTestHandlers()
+	checker := new(MockHealthChecker)
+	checker.On("Ping", mock.Anything).Return(fmt.Errorf("connection refused")).Maybe()
+	handlers.SetHealthChecker(checker)
+
+	router := gin.New()
+	router.GET("/health/live", handlers.LivenessCheck)
+
+	req, _ := http.NewRequest("GET", "/health/live", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"alive"`)
+	checker.AssertNotCalled(t, "Ping", mock.Anything)
+}
+
+func TestReadinessCheck(t *testing.T) {
+	t.Log("Testing ReadinessCheck: ready only when the database is up and ingestion, if required, is done")
+
+	tests := []struct {
+		name             string
+		pingErr          error
+		requireIngestion bool
+		ingested         bool
+		wantStatus       int
+		wantDetails      string
+	}{
+		{"database up", nil, false, false, http.StatusOK, ""},
+		{"database down", fmt.Errorf("connection refused"), false, false, http.StatusServiceUnavailable, "database unreachable"},
+		{"ingestion pending", nil, true, false, http.StatusServiceUnavailable, "initial ingestion has not completed"},
+		{"ingestion complete", nil, true, true, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, _, _, _, _ := setupTestHandlers()
+			checker := new(MockHealthChecker)
+			checker.On("Ping", mock.Anything).Return(tt.pingErr)
+			handlers.SetHealthChecker(checker)
+
+			readiness := NewReadiness(tt.requireIngestion)
+			if tt.ingested {
+				readiness.MarkIngested()
+			}
+			handlers.SetReadiness(readiness)
+
+			router := gin.New()
+			router.GET("/health/ready", handlers.ReadinessCheck)
+
+			req, _ := http.NewRequest("GET", "/health/ready", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "ready", response["status"])
+			} else {
+				assert.Equal(t, "not_ready", response["status"])
+				assert.Equal(t, apperrors.ErrCodeUnavailable, response["code"])
+				assert.Equal(t, tt.wantDetails, response["details"])
+			}
+		})
+	}
+}
+
+func TestTriggerIngestion_MarksReady(t *testing.T) {
+	t.Log("Testing TriggerIngestion: a successful run marks the service ready")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	readiness := NewReadiness(true)
+	handlers.SetReadiness(readiness)
+	router := setupGinRouter(handlers)
+
+	done := make(chan struct{})
+	ingestionSvc.On("IngestAllData", mock.Anything).Return(&domain.IngestionResult{}, nil).Run(func(mock.Arguments) { close(done) })
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	<-done
+	assert.Eventually(t, func() bool { return !readiness.ingestionPending() }, time.Second, 5*time.Millisecond)
+}
+
+func TestHandleError_ProblemDetailsValidation(t *testing.T) {
+	t.Log("Testing HandleError: validation errors are RFC 7807 problems when the client accepts problem+json")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings?page=0&strict_pagination=true", nil)
+	req.Header.Set("Accept", "application/problem+json, application/json;q=0.9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "urn:stock-analyzer:problem:validation-error", problem.Type)
+	assert.NotEmpty(t, problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "invalid page parameter: must be at least 1", problem.Detail)
+	assert.Equal(t, "/api/v1/ratings", problem.Instance)
+	assert.Equal(t, apperrors.ErrCodeValidation, problem.Code)
+
+	stockRepo.AssertNotCalled(t, "GetStockRatings", mock.Anything, mock.Anything)
+}
+
+func TestHandleError_ProblemDetailsNotFound(t *testing.T) {
+	t.Log("Testing HandleError: not-found errors are RFC 7807 problems when the client accepts problem+json")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetStockRatingsByTicker", mock.Anything, "NONEXISTENT").Return([]domain.StockRating{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/NONEXISTENT", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "urn:stock-analyzer:problem:not-found", body["type"])
+	assert.NotEmpty(t, body["title"])
+	assert.Equal(t, float64(http.StatusNotFound), body["status"])
+	assert.Contains(t, body["detail"], "no ratings found for ticker")
+	assert.Equal(t, "/api/v1/ratings/NONEXISTENT", body["instance"])
+	assert.NotContains(t, body, "error")
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestHandleError_DefaultsToErrorResponse(t *testing.T) {
+	t.Log("Testing HandleError: clients that do not ask for problem+json keep the ErrorResponse body")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, accept := range []string{"", "application/json", "*/*"} {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings?page=0&strict_pagination=true", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, accept)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json", accept)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp), accept)
+		assert.Equal(t, apperrors.ErrCodeValidation, errorResp.Code, accept)
+		assert.NotEmpty(t, errorResp.Error, accept)
+	}
+}
+
+func TestHandleError_RateLimited(t *testing.T) {
+	t.Log("Testing HandleError: rate-limited errors return 429 with a Retry-After header")
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/limited", func(c *gin.Context) {
+		HandleError(c, apperrors.RateLimited(1500*time.Millisecond))
+	})
+	router.GET("/limited-no-hint", func(c *gin.Context) {
+		HandleError(c, apperrors.ErrRateLimited)
+	})
+
+	req, _ := http.NewRequest("GET", "/limited", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, apperrors.ErrCodeRateLimited, errorResp.Code)
+
+	req, _ = http.NewRequest("GET", "/limited-no-hint", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+// setupCORSRouter returns a router serving GET /ping behind the CORS middleware
+func setupCORSRouter(allowedOrigins []string, development bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(allowedOrigins, development))
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_AllowedOriginIsEchoed(t *testing.T) {
+	t.Log("Testing CORS middleware: configured and local dev origins are echoed back")
+	router := setupCORSRouter([]string{"https://app.example.com"}, false)
+
+	for _, origin := range []string{"https://app.example.com", "http://localhost:5173"} {
+		w := corsRequest(router, "GET", origin)
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+}
+
+func TestCORS_MultipleConfiguredOrigins(t *testing.T) {
+	t.Log("Testing CORS middleware: each of several configured origins is echoed and unknown ones are rejected")
+	t.Setenv("FRONTEND_URL", "")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://staging.example.com,https://preview.example.com")
+	cfg := config.Load()
+	router := setupCORSRouter(cfg.AllowedOrigins(), false)
+
+	for _, origin := range []string{
+		"https://app.example.com",
+		"https://staging.example.com",
+		"https://preview.example.com",
+	} {
+		w := corsRequest(router, "GET", origin)
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	w := corsRequest(router, "GET", "https://unknown.example.com")
+	assert.Empty(t, w.Header().Values("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_DisallowedOriginInProduction(t *testing.T) {
+	t.Log("Testing CORS middleware: production omits Access-Control-Allow-Origin for unknown origins")
+	router := setupCORSRouter([]string{"https://app.example.com"}, false)
+
+	w := corsRequest(router, "GET", "https://evil.example.com")
+	assert.Empty(t, w.Header().Values("Access-Control-Allow-Origin"))
+
+	w = corsRequest(router, "OPTIONS", "https://evil.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Values("Access-Control-Allow-Origin"))
+
+	// Without any configured origin production no longer falls back to a wildcard
+	router = setupCORSRouter(nil, false)
+	w = corsRequest(router, "GET", "https://evil.example.com")
+	assert.Empty(t, w.Header().Values("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_DevelopmentWildcard(t *testing.T) {
+	t.Log("Testing CORS middleware: development allows unknown origins with a wildcard")
+	router := setupCORSRouter(nil, true)
+
+	w := corsRequest(router, "GET", "https://anything.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = corsRequest(router, "GET", "http://localhost:3000")
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_PreflightIsCacheableAndVariesByOrigin(t *testing.T) {
+	t.Log("Testing CORS middleware: preflights return 204 without no-cache, and every response varies by Origin")
+	router := setupCORSRouter([]string{"https://app.example.com"}, false)
+
+	w := corsRequest(router, "OPTIONS", "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	assert.Equal(t, "86400", w.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+	assert.Empty(t, w.Header().Get("Pragma"))
+	assert.Empty(t, w.Header().Get("Expires"))
+
+	// Data responses keep the no-cache headers
+	w = corsRequest(router, "GET", "https://app.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	assert.Equal(t, "no-cache, no-store, must-revalidate", w.Header().Get("Cache-Control"))
+
+	// Rejected origins still vary so a cached rejection is not served to an allowed origin
+	w = corsRequest(router, "GET", "https://evil.example.com")
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORS_VaryKeepsOtherValues(t *testing.T) {
+	t.Log("Testing CORS middleware: Vary: Origin is added alongside Vary values from earlier middleware")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Locale())
+	router.Use(CORS(nil, false))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := corsRequest(router, "GET", "https://app.example.com")
+	assert.ElementsMatch(t, []string{"Accept-Language", "Origin"}, w.Header().Values("Vary"))
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Log("Testing Metrics middleware: request counters increment per route and status")
+	gin.SetMode(gin.TestMode)
+	stockRepo := &MockStockRepository{}
+	recommendationSvc := &MockRecommendationService{}
+	router := SetupRouter(stockRepo, &MockIngestionService{}, recommendationSvc, &MockAlpacaService{}, &config.Config{}, nil)
+
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return([]domain.StockRecommendation{}, nil)
+
+	healthCounter := httpRequestsTotal.WithLabelValues("/health", "GET", "200")
+	recommendationsCounter := httpRequestsTotal.WithLabelValues("/api/v1/recommendations", "GET", "200")
+	notFoundCounter := httpRequestsTotal.WithLabelValues("unmatched", "GET", "404")
+
+	healthBefore := testutil.ToFloat64(healthCounter)
+	recommendationsBefore := testutil.ToFloat64(recommendationsCounter)
+	notFoundBefore := testutil.ToFloat64(notFoundCounter)
+
+	for _, path := range []string{"/health", "/health", "/api/v1/recommendations", "/does-not-exist"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, healthBefore+2, testutil.ToFloat64(healthCounter))
+	assert.Equal(t, recommendationsBefore+1, testutil.ToFloat64(recommendationsCounter))
+	assert.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFoundCounter))
+
+	// The metrics endpoint exposes the collected series
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "http_requests_total")
+	assert.Contains(t, w.Body.String(), "http_request_duration_seconds")
+}
+
+func TestRequestID_RoundTrip(t *testing.T) {
+	t.Log("Testing RequestID middleware: incoming X-Request-ID is echoed and passed to services")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ratings := []domain.StockRating{{RatingID: uuid.New(), Ticker: "AAPL", RatingTo: "Buy"}}
+	stockRepo.On("GetStockRatingsByTicker", mock.MatchedBy(func(ctx context.Context) bool {
+		return logging.RequestIDFromContext(ctx) == "req-123"
+	}), "AAPL").Return(ratings, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/AAPL", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get(RequestIDHeader))
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestRequestID_InvalidIncomingIDIsReplaced(t *testing.T) {
+	t.Log("Testing RequestID middleware: oversized or non-printable incoming IDs are replaced with a generated one")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, requestID := range []string{strings.Repeat("a", maxRequestIDLength+1), "req 123", "req\x7f"} {
+		req, _ := http.NewRequest("GET", "/api/v1/does-not-exist", nil)
+		req.Header.Set(RequestIDHeader, requestID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		_, err := uuid.Parse(w.Header().Get(RequestIDHeader))
+		assert.NoError(t, err, "%q should be replaced", requestID)
+	}
+
+	// An ID of exactly the column width is kept
+	requestID := strings.Repeat("a", maxRequestIDLength)
+	req, _ := http.NewRequest("GET", "/api/v1/does-not-exist", nil)
+	req.Header.Set(RequestIDHeader, requestID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, requestID, w.Header().Get(RequestIDHeader))
+}
+
+func TestRequestID_GeneratedAndIncludedInErrors(t *testing.T) {
+	t.Log("Testing RequestID middleware: a generated ID is returned in the header and error body")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetStockRatingsByTicker", mock.Anything, "NONE").Return([]domain.StockRating{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/NONE", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	requestID := w.Header().Get(RequestIDHeader)
+	_, err := uuid.Parse(requestID)
+	require.NoError(t, err)
+
+	var errorResp ErrorResponse
+	err = json.Unmarshal(w.Body.Bytes(), &errorResp)
+	require.NoError(t, err)
+	assert.Equal(t, requestID, errorResp.RequestID)
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestLocale_NegotiatedFromAcceptLanguage(t *testing.T) {
+	t.Log("Testing Locale middleware: Accept-Language is negotiated and passed to services")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendationSvc.On("GetCachedRecommendations", mock.MatchedBy(func(ctx context.Context) bool {
+		return locale.FromContext(ctx).Tag == "de-DE"
+	})).Return([]domain.StockRecommendation{}, nil).Once()
+	recommendationSvc.On("GetCachedRecommendations", mock.MatchedBy(func(ctx context.Context) bool {
+		return locale.FromContext(ctx).Tag == "en-US"
+	})).Return([]domain.StockRecommendation{}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations", nil)
+	req.Header.Set("Accept-Language", "de-AT,de;q=0.9,en;q=0.5")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "de-DE", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+
+	// Unsupported languages fall back to en-US
+	req, _ = http.NewRequest("GET", "/api/v1/recommendations", nil)
+	req.Header.Set("Accept-Language", "tlh")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "en-US", w.Header().Get("Content-Language"))
+
+	recommendationSvc.AssertExpectations(t)
+}
+
+func TestRouteTimeout_SlowHandlerIsCutOff(t *testing.T) {
+	t.Log("Testing RouteTimeout middleware: slow handlers get a 504 at their route timeout")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.Use(RouteTimeout(map[string]time.Duration{
+		"/slow": 20 * time.Millisecond,
+	}, time.Second))
+
+	handlerDeadline := make(chan bool, 1)
+	router.GET("/slow", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		handlerDeadline <- hasDeadline
+
+		time.Sleep(200 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"status": "too late"})
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"status": "ok"})
+	})
+
+	start := time.Now()
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.True(t, <-handlerDeadline)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Less(t, time.Since(start), time.Second)
+
+	var errorResp ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &errorResp)
+	require.NoError(t, err)
+	assert.Equal(t, "request timed out", errorResp.Error)
+	assert.Equal(t, apperrors.ErrCodeTimeout, errorResp.Code)
+	assert.NotContains(t, w.Body.String(), "too late")
+
+	// Routes without an override use the default timeout and pass through untouched
+	req, _ = http.NewRequest("GET", "/fast", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestRouteTimeout_ProblemDetails(t *testing.T) {
+	t.Log("Testing RouteTimeout middleware: timeouts are RFC 7807 problems when the client accepts problem+json")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.Use(RouteTimeout(map[string]time.Duration{
+		"/slow": 20 * time.Millisecond,
+	}, time.Second))
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(200 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"status": "too late"})
+	})
+
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "urn:stock-analyzer:problem:timeout", problem.Type)
+	assert.Equal(t, "request timed out", problem.Title)
+	assert.Equal(t, http.StatusGatewayTimeout, problem.Status)
+	assert.Equal(t, "/slow", problem.Instance)
+}
+
+func TestRouteTimeout_PanicIsRecovered(t *testing.T) {
+	t.Log("Testing RouteTimeout middleware: panics inside timed handlers still reach ErrorHandler")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.Use(RouteTimeout(nil, time.Second))
+	router.GET("/panic", func(c *gin.Context) {
+		panic(apperrors.ErrValidationFailure)
+	})
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestParseIntQuery(t *testing.T) {
+	t.Log("Testing utility: ParseIntQuery")
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		queryValue   string
+		defaultValue int
+		expected     int
 		expectError  bool
 	}{
 		{
-			name:         "valid integer",
-			queryValue:   "42",
-			defaultValue: 10,
-			expected:     42,
-			expectError:  false,
-		},
-		{
-			name:         "empty query uses default",
-			queryValue:   "",
-			defaultValue: 10,
-			expected:     10,
-			expectError:  false,
-		},
-		{
-			name:         "invalid integer",
-			queryValue:   "invalid",
-			defaultValue: 10,
-			expected:     0,
-			expectError:  true,
+			name:         "valid integer",
+			queryValue:   "42",
+			defaultValue: 10,
+			expected:     42,
+			expectError:  false,
+		},
+		{
+			name:         "empty query uses default",
+			queryValue:   "",
+			defaultValue: 10,
+			expected:     10,
+			expectError:  false,
+		},
+		{
+			name:         "invalid integer",
+			queryValue:   "invalid",
+			defaultValue: 10,
+			expected:     0,
+			expectError:  true,
+		},
+		{
+			name:         "negative integer",
+			queryValue:   "-5",
+			defaultValue: 10,
+			expected:     -5,
+			expectError:  false,
+		},
+		{
+			name:         "zero",
+			queryValue:   "0",
+			defaultValue: 10,
+			expected:     0,
+			expectError:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("  - Sub-test: %s", tt.name)
+			// Create a test context with query parameter
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			if tt.queryValue != "" {
+				req, _ := http.NewRequest("GET", fmt.Sprintf("/?test=%s", tt.queryValue), nil)
+				c.Request = req
+			} else {
+				req, _ := http.NewRequest("GET", "/", nil)
+				c.Request = req
+			}
+
+			result, err := parseIntQuery(c, "test", tt.defaultValue)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expected, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+// Benchmarks for expensive operations
+func BenchmarkGetStockRatings(b *testing.B) {
+	b.Log("Benchmarking GetStockRatings endpoint")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	// Setup large dataset
+	ratings := make([]domain.StockRating, 1000)
+	for i := 0; i < 1000; i++ {
+		ratings[i] = domain.StockRating{
+			RatingID:  uuid.New(),
+			Ticker:    fmt.Sprintf("TICK%d", i),
+			Company:   fmt.Sprintf("Company %d", i),
+			Brokerage: "Test Brokerage",
+			Action:    "upgraded by",
+			RatingTo:  "Buy",
+			Time:      time.Now().Add(-time.Duration(i) * time.Hour),
+			CreatedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		}
+	}
+
+	expectedResponse := &domain.PaginatedResponse[domain.StockRating]{
+		Data: ratings,
+		Pagination: domain.Pagination{
+			Page:       1,
+			Limit:      20,
+			TotalItems: 10000,
+			TotalPages: 500,
+		},
+	}
+	stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkGetStockPrice(b *testing.B) {
+	b.Log("Benchmarking GetStockPrice endpoint")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	// Setup large price data
+	priceBars := make([]domain.PriceBar, 1000)
+	for i := 0; i < 1000; i++ {
+		priceBars[i] = domain.PriceBar{
+			Timestamp: time.Now().Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
+			Open:      100.0 + float64(i)*0.1,
+			High:      105.0 + float64(i)*0.1,
+			Low:       99.0 + float64(i)*0.1,
+			Close:     104.0 + float64(i)*0.1,
+			Volume:    int64(1000000 + i*1000),
+		}
+	}
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(priceBars, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+}
+
+// Stress tests for concurrent operations
+func TestConcurrentGetStockRatings(t *testing.T) {
+	t.Log("Testing GetStockRatings: with high concurrency")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ratings := []domain.StockRating{
+		{
+			RatingID:  uuid.New(),
+			Ticker:    "AAPL",
+			Company:   "Apple Inc.",
+			Brokerage: "Goldman Sachs",
+			Action:    "upgraded by",
+			RatingTo:  "Buy",
+			Time:      time.Now(),
+			CreatedAt: time.Now(),
+		},
+	}
+
+	expectedResponse := &domain.PaginatedResponse[domain.StockRating]{
+		Data: ratings,
+		Pagination: domain.Pagination{
+			Page:       1,
+			Limit:      20,
+			TotalItems: 1,
+			TotalPages: 1,
+		},
+	}
+	stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+
+	// Run 100 concurrent requests
+	concurrency := 100
+	done := make(chan bool, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			req, _ := http.NewRequest("GET", "/api/v1/ratings", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			done <- true
+		}()
+	}
+
+	// Wait for all requests to complete
+	for i := 0; i < concurrency; i++ {
+		<-done
+	}
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestConcurrentGetStockPrice(t *testing.T) {
+	t.Log("Testing GetStockPrice: with high concurrency")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	priceBars := []domain.PriceBar{
+		{
+			Timestamp: "2023-12-01T09:30:00Z",
+			Open:      100.0,
+			High:      105.0,
+			Low:       99.0,
+			Close:     104.0,
+			Volume:    1000000,
+		},
+	}
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(priceBars, nil)
+
+	// Run 50 concurrent requests
+	concurrency := 50
+	done := make(chan bool, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		go func(id int) {
+			symbol := fmt.Sprintf("STOCK%d", id%10) // Use 10 different symbols
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/stocks/%s/price", symbol), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			done <- true
+		}(i)
+	}
+
+	// Wait for all requests to complete
+	for i := 0; i < concurrency; i++ {
+		<-done
+	}
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestDeleteOldEnrichedData_RecordsAuditEntry(t *testing.T) {
+	t.Log("Testing DeleteOldEnrichedData: admin delete produces an audit record")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	auditRepo := new(MockAuditRepository)
+	auditor := NewAuditLogger(auditRepo, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		c.Set(principalContextKey, "admin@example.com")
+		c.Next()
+	})
+	router.DELETE("/api/v1/enriched", Audit(auditor, adminActions), handlers.DeleteOldEnrichedData)
+
+	stockRepo.On("DeleteOldEnrichedData", mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(3), nil)
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Actor == "admin@example.com" &&
+			entry.Action == "enriched.delete" &&
+			entry.Target == "/api/v1/enriched?older_than_days=7" &&
+			entry.Status == http.StatusOK &&
+			entry.RequestID == "audit-req-1" &&
+			!entry.Timestamp.IsZero()
+	})).Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/enriched?older_than_days=7", nil)
+	req.Header.Set(RequestIDHeader, "audit-req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(3), response["deleted"])
+
+	stockRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestDeleteOldEnrichedData_CutoffUsesClock(t *testing.T) {
+	t.Log("Testing DeleteOldEnrichedData: cutoff is computed from the injected clock")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	handlers.SetClock(clock.NewFake(now))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/api/v1/enriched", handlers.DeleteOldEnrichedData)
+
+	stockRepo.On("DeleteOldEnrichedData", mock.Anything, time.Date(2025, 3, 7, 15, 0, 0, 0, time.UTC)).Return(int64(1), nil)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/enriched?older_than_days=7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stockRepo.AssertExpectations(t)
+}
+
+// setupWatchlistRouter returns a router whose watchlist routes accept the keys
+// "alice-key" and "bob-key", backed by a mock watchlist repository
+func setupWatchlistRouter() (*gin.Engine, *MockStockRepository, *MockWatchlistRepository) {
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	handlers.cfg.UserAPIKeys = map[string]string{"alice": "alice-key", "bob": "bob-key"}
+
+	watchlistRepo := &MockWatchlistRepository{}
+	handlers.SetWatchlistRepository(watchlistRepo)
+
+	return setupGinRouter(handlers), stockRepo, watchlistRepo
+}
+
+// setupAPIKeyRouter serves a single protected write route behind APIKeyAuth that
+// echoes the request principal in an X-Principal header
+func setupAPIKeyRouter(key string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/write", APIKeyAuth(key), func(c *gin.Context) {
+		c.Header("X-Principal", c.GetString(principalContextKey))
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestAPIKeyAuth_ValidKey(t *testing.T) {
+	t.Log("Testing APIKeyAuth: a request with the configured key reaches the handler")
+	router := setupAPIKeyRouter("s3cret")
+
+	req, _ := http.NewRequest("POST", "/write", nil)
+	req.Header.Set(APIKeyHeader, "s3cret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, apiKeyPrincipal, w.Header().Get("X-Principal"))
+}
+
+func TestAPIKeyAuth_InvalidKey(t *testing.T) {
+	t.Log("Testing APIKeyAuth: missing or wrong keys are rejected with 401 and the standard error body")
+	router := setupAPIKeyRouter("s3cret")
+
+	for _, key := range []string{"", "wrong", "s3cret-but-longer"} {
+		req, _ := http.NewRequest("POST", "/write", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "key %q", key)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, apperrors.ErrCodeUnauthorized, errorResp.Code)
+	}
+}
+
+func TestAPIKeyAuth_UnconfiguredPassesThrough(t *testing.T) {
+	t.Log("Testing APIKeyAuth: with no key configured, requests pass through in dev mode")
+	router := setupAPIKeyRouter("")
+
+	for _, key := range []string{"", "anything"} {
+		req, _ := http.NewRequest("POST", "/write", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "key %q", key)
+		assert.Equal(t, unauthenticatedPrincipal, w.Header().Get("X-Principal"), "key %q", key)
+	}
+}
+
+func TestSetupRouter_WriteRoutesRequireAPIKey(t *testing.T) {
+	t.Log("Testing SetupRouter: write routes need API_KEY while read routes stay public")
+	gin.SetMode(gin.TestMode)
+	ingestionSvc := &MockIngestionService{}
+	recommendationSvc := &MockRecommendationService{}
+	router := SetupRouter(&MockStockRepository{}, ingestionSvc, recommendationSvc, &MockAlpacaService{}, &config.Config{APIKey: "s3cret"}, nil)
+
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return([]domain.StockRecommendation{}, nil)
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/ingest"},
+		{"POST", "/api/v1/enrich"},
+		{"DELETE", "/api/v1/enriched"},
+		{"POST", "/api/v1/recommendations/refresh"},
+		{"PUT", "/api/v1/admin/maintenance"},
+		{"POST", "/api/v1/stocks/snapshots/warm"},
+	} {
+		req, _ := http.NewRequest(route.method, route.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", route.method, route.path)
+	}
+	ingestionSvc.AssertNotCalled(t, "IngestAllData", mock.Anything)
+
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// auditedStockRepository is a stock repository that also persists audit entries
+type auditedStockRepository struct {
+	*MockStockRepository
+	*MockAuditRepository
+}
+
+func TestSetupRouter_AuditsAdminRoutesBeforeAuth(t *testing.T) {
+	t.Log("Testing SetupRouter: rejected admin writes and admin reads are audited, other routes are not")
+	gin.SetMode(gin.TestMode)
+	auditRepo := &MockAuditRepository{}
+	recommendationSvc := &MockRecommendationService{}
+	repo := auditedStockRepository{&MockStockRepository{}, auditRepo}
+	cfg := &config.Config{APIKey: "s3cret", AuditLogPersist: true}
+	router := SetupRouter(repo, &MockIngestionService{}, recommendationSvc, &MockAlpacaService{}, cfg, nil)
+
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return([]domain.StockRecommendation{}, nil)
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == "ingest.trigger" && entry.Actor == anonymousActor && entry.Status == http.StatusUnauthorized
+	})).Return(nil).Once()
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == "maintenance.get" && entry.Status == http.StatusOK
+	})).Return(nil).Once()
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/ingest"},
+		{"GET", "/api/v1/admin/maintenance"},
+		{"GET", "/api/v1/recommendations"},
+	} {
+		req, _ := http.NewRequest(route.method, route.path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	auditRepo.AssertExpectations(t)
+	auditRepo.AssertNumberOfCalls(t, "CreateAuditEntry", 2)
+}
+
+func TestWithoutTimeout(t *testing.T) {
+	t.Log("Testing withoutTimeout: long-lived routes get a disabled timeout without touching the configured map")
+	configured := map[string]time.Duration{
+		"/api/v1/stocks/:symbol/price":  15 * time.Second,
+		"/api/v1/stocks/:symbol/stream": time.Second,
+	}
+
+	timeouts := withoutTimeout(configured, snapshotStreamRoute, recommendationStreamRoute, ratingsExportRoute)
+
+	assert.Equal(t, time.Duration(0), timeouts[snapshotStreamRoute])
+	assert.Equal(t, time.Duration(0), timeouts[recommendationStreamRoute])
+	assert.Equal(t, time.Duration(0), timeouts[ratingsExportRoute])
+	assert.Equal(t, 15*time.Second, timeouts["/api/v1/stocks/:symbol/price"])
+	assert.Equal(t, time.Second, configured[snapshotStreamRoute])
+
+	assert.Equal(t, map[string]time.Duration{snapshotStreamRoute: 0}, withoutTimeout(nil, snapshotStreamRoute))
+}
+
+func TestWatchlist_RequiresAPIKey(t *testing.T) {
+	t.Log("Testing watchlist endpoints: missing or unknown API keys are rejected with 401")
+	router, _, watchlistRepo := setupWatchlistRouter()
+
+	for _, key := range []string{"", "wrong-key"} {
+		req, _ := http.NewRequest("GET", "/api/v1/watchlist", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "key %q", key)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, apperrors.ErrCodeUnauthorized, errorResp.Code)
+	}
+
+	watchlistRepo.AssertNotCalled(t, "GetWatchlist", mock.Anything, mock.Anything)
+}
+
+func TestAddToWatchlist(t *testing.T) {
+	t.Log("Testing AddToWatchlist: adds the uppercased ticker for the authenticated user")
+	router, _, watchlistRepo := setupWatchlistRouter()
+
+	watchlistRepo.On("AddToWatchlist", mock.Anything, "alice", "AAPL").Return(nil)
+
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/aapl", nil)
+	req.Header.Set(APIKeyHeader, "alice-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ticker":"AAPL"`)
+	watchlistRepo.AssertExpectations(t)
+}
+
+func TestAddToWatchlist_InvalidTicker(t *testing.T) {
+	t.Log("Testing AddToWatchlist: tickers longer than the column width are rejected")
+	router, _, watchlistRepo := setupWatchlistRouter()
+
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/ABCDEFGHIJK", nil)
+	req.Header.Set(APIKeyHeader, "alice-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	watchlistRepo.AssertNotCalled(t, "AddToWatchlist", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRemoveFromWatchlist(t *testing.T) {
+	t.Log("Testing RemoveFromWatchlist: removes a watched ticker and 404s on one not watched")
+	router, _, watchlistRepo := setupWatchlistRouter()
+
+	watchlistRepo.On("RemoveFromWatchlist", mock.Anything, "bob", "AAPL").Return(nil)
+	watchlistRepo.On("RemoveFromWatchlist", mock.Anything, "bob", "MSFT").Return(apperrors.ErrNotFound.WithDetails("MSFT is not on the watchlist"))
+
+	cases := map[string]int{"AAPL": http.StatusNoContent, "MSFT": http.StatusNotFound}
+	for ticker, expected := range cases {
+		req, _ := http.NewRequest("DELETE", "/api/v1/watchlist/"+ticker, nil)
+		req.Header.Set(APIKeyHeader, "bob-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expected, w.Code, ticker)
+	}
+	watchlistRepo.AssertExpectations(t)
+}
+
+func TestGetWatchlist(t *testing.T) {
+	t.Log("Testing GetWatchlist: lists the user's tickers with their latest ratings")
+	router, stockRepo, watchlistRepo := setupWatchlistRouter()
+
+	targetTo := 201.456
+	watchlistRepo.On("GetWatchlist", mock.Anything, "alice").Return([]string{"AAPL", "ZZZZ"}, nil)
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", RatingTo: "Buy", TargetTo: &targetTo},
+		"MSFT": {Ticker: "MSFT", RatingTo: "Hold"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/watchlist", nil)
+	req.Header.Set(APIKeyHeader, "alice-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data  []domain.WatchlistItem `json:"data"`
+		Count int                    `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, 2, response.Count)
+
+	assert.Equal(t, "AAPL", response.Data[0].Ticker)
+	require.NotNil(t, response.Data[0].LatestRating)
+	assert.Equal(t, "Buy", response.Data[0].LatestRating.RatingTo)
+	assert.Equal(t, 201.46, *response.Data[0].LatestRating.TargetTo)
+
+	assert.Equal(t, "ZZZZ", response.Data[1].Ticker)
+	assert.Nil(t, response.Data[1].LatestRating)
+
+	watchlistRepo.AssertExpectations(t)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestGetWatchlist_Empty(t *testing.T) {
+	t.Log("Testing GetWatchlist: an empty watchlist returns [] without loading ratings")
+	router, stockRepo, watchlistRepo := setupWatchlistRouter()
+
+	watchlistRepo.On("GetWatchlist", mock.Anything, "bob").Return([]string{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/watchlist", nil)
+	req.Header.Set(APIKeyHeader, "bob-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[],"count":0}`, w.Body.String())
+	stockRepo.AssertNotCalled(t, "GetLatestRatingsByTicker", mock.Anything)
+}
+
+func TestGetWatchlistRecommendations(t *testing.T) {
+	t.Log("Testing GetWatchlistRecommendations: scores only the authenticated user's watchlist tickers")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	handlers.cfg.UserAPIKeys = map[string]string{"alice": "alice-key"}
+	watchlistRepo := &MockWatchlistRepository{}
+	handlers.SetWatchlistRepository(watchlistRepo)
+	router := setupGinRouter(handlers)
+
+	watchlistRepo.On("GetWatchlist", mock.Anything, "alice").Return([]string{"AAPL", "MSFT"}, nil)
+	recommendationSvc.On("GenerateRecommendationsForTickers", mock.Anything, []string{"AAPL", "MSFT"}).Return([]domain.StockRecommendation{
+		{Ticker: "MSFT", Score: 0.8123},
+		{Ticker: "AAPL", Score: 0.7},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/watchlist/recommendations", nil)
+	req.Header.Set(APIKeyHeader, "alice-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Recommendations []domain.StockRecommendation `json:"recommendations"`
+		Count           int                          `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	require.Len(t, response.Recommendations, 2)
+	assert.Equal(t, "MSFT", response.Recommendations[0].Ticker)
+	assert.Equal(t, 0.81, response.Recommendations[0].Score)
+
+	watchlistRepo.AssertExpectations(t)
+	recommendationSvc.AssertExpectations(t)
+}
+
+func TestGetWatchlistRecommendations_EmptyWatchlist(t *testing.T) {
+	t.Log("Testing GetWatchlistRecommendations: an empty watchlist returns no recommendations without scoring")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	handlers.cfg.UserAPIKeys = map[string]string{"bob": "bob-key"}
+	watchlistRepo := &MockWatchlistRepository{}
+	handlers.SetWatchlistRepository(watchlistRepo)
+	router := setupGinRouter(handlers)
+
+	watchlistRepo.On("GetWatchlist", mock.Anything, "bob").Return([]string{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/watchlist/recommendations", nil)
+	req.Header.Set(APIKeyHeader, "bob-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"recommendations":[],"count":0}`, w.Body.String())
+	recommendationSvc.AssertNotCalled(t, "GenerateRecommendationsForTickers", mock.Anything, mock.Anything)
+}
+
+func TestAudit_FailedActionIsAuditedAsAnonymous(t *testing.T) {
+	t.Log("Testing Audit: rejected admin action is still recorded")
+	handlers, _, _, _, _ := setupTestHandlers()
+	auditRepo := new(MockAuditRepository)
+	auditor := NewAuditLogger(auditRepo, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.DELETE("/api/v1/enriched", Audit(auditor, adminActions), handlers.DeleteOldEnrichedData)
+
+	auditRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Actor == anonymousActor && entry.Status == http.StatusBadRequest
+	})).Return(fmt.Errorf("audit table unavailable"))
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/enriched?older_than_days=0", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Persistence failures must not change the response
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAuditLogger_RecordOutlivesCancelledRequest(t *testing.T) {
+	t.Log("Testing AuditLogger: entries are persisted with a live, bounded context after the request is cancelled")
+	auditRepo := new(MockAuditRepository)
+	auditor := NewAuditLogger(auditRepo, nil)
+
+	auditRepo.On("CreateAuditEntry", mock.MatchedBy(func(ctx context.Context) bool {
+		deadline, ok := ctx.Deadline()
+		return ctx.Err() == nil && ok && time.Until(deadline) <= auditWriteTimeout
+	}), mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	auditor.Record(ctx, &domain.AuditEntry{Actor: "admin", Action: "ingest.trigger", Status: http.StatusAccepted})
+
+	auditRepo.AssertExpectations(t)
+}
+
+func rankedRecommendations(n int) []domain.StockRecommendation {
+	recommendations := make([]domain.StockRecommendation, n)
+	for i := range recommendations {
+		recommendations[i] = domain.StockRecommendation{
+			Ticker: fmt.Sprintf("TICK%02d", i),
+			Score:  1 - float64(i)/100,
+		}
+	}
+	return recommendations
+}
+
+func TestGetAllRecommendations_Pagination(t *testing.T) {
+	t.Log("Testing GetAllRecommendations: pages through the full ranked list beyond the top recommendations")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendationSvc.On("GetCachedAllRecommendations", mock.Anything).Return(rankedRecommendations(25), nil)
+
+	cases := []struct {
+		name    string
+		query   string
+		page    int
+		limit   int
+		tickers []string
+	}{
+		{"defaults to the first page of 20", "", 1, 20, nil},
+		{"second page", "?page=2&limit=10", 2, 10, []string{"TICK10", "TICK19"}},
+		{"partial last page", "?page=3&limit=10", 3, 10, []string{"TICK20", "TICK24"}},
+		{"past the end", "?page=4&limit=10", 4, 10, []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/recommendations/all"+tc.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response domain.PaginatedResponse[domain.StockRecommendation]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.Equal(t, tc.page, response.Pagination.Page)
+			assert.Equal(t, tc.limit, response.Pagination.Limit)
+			assert.Equal(t, 25, response.Pagination.TotalItems)
+			assert.Equal(t, (25+tc.limit-1)/tc.limit, response.Pagination.TotalPages)
+			assert.NotNil(t, response.Data)
+
+			if tc.tickers == nil {
+				assert.Len(t, response.Data, 20)
+				assert.Equal(t, "TICK00", response.Data[0].Ticker)
+				return
+			}
+			if len(tc.tickers) == 0 {
+				assert.Empty(t, response.Data)
+				return
+			}
+			assert.Equal(t, tc.tickers[0], response.Data[0].Ticker)
+			assert.Equal(t, tc.tickers[1], response.Data[len(response.Data)-1].Ticker)
+		})
+	}
+}
+
+func TestGetAllRecommendations_InvalidPagination(t *testing.T) {
+	t.Log("Testing GetAllRecommendations: out-of-range page and limit are rejected")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, query := range []string{"?page=0", "?limit=0", "?limit=101", "?page=abc"} {
+		req, _ := http.NewRequest("GET", "/api/v1/recommendations/all"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	recommendationSvc.AssertNotCalled(t, "GetCachedAllRecommendations", mock.Anything)
+}
+
+func TestGetAllRecommendations_ServiceError(t *testing.T) {
+	t.Log("Testing GetAllRecommendations: a generation failure is returned as an error response")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendationSvc.On("GetCachedAllRecommendations", mock.Anything).
+		Return(nil, apperrors.Wrap(fmt.Errorf("connection refused"), apperrors.ErrCodeDatabase, "failed to get latest ratings"))
+
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations/all", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRefreshRecommendations_Success(t *testing.T) {
+	t.Log("Testing RefreshRecommendations: returns freshly generated recommendations")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendations := []domain.StockRecommendation{
+		{Ticker: "AAPL", Score: 85.5, GeneratedAt: time.Now()},
+		{Ticker: "MSFT", Score: 72.0, GeneratedAt: time.Now()},
+	}
+	recommendationSvc.On("RefreshRecommendations", mock.Anything).Return(recommendations, nil)
+
+	req, _ := http.NewRequest("POST", "/api/v1/recommendations/refresh", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Recommendations []domain.StockRecommendation `json:"recommendations"`
+		Count           int                          `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	require.Len(t, response.Recommendations, 2)
+	assert.Equal(t, "AAPL", response.Recommendations[0].Ticker)
+
+	recommendationSvc.AssertNotCalled(t, "GetCachedRecommendations", mock.Anything)
+	recommendationSvc.AssertExpectations(t)
+}
+
+func TestRefreshRecommendations_GenerationError(t *testing.T) {
+	t.Log("Testing RefreshRecommendations: propagates generation error")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	recommendationSvc.On("RefreshRecommendations", mock.Anything).
+		Return([]domain.StockRecommendation{}, apperrors.Wrap(fmt.Errorf("connection refused"), apperrors.ErrCodeDatabase, "failed to get latest ratings"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/recommendations/refresh", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, apperrors.ErrCodeDatabase, response.Code)
+
+	recommendationSvc.AssertExpectations(t)
+}
+
+func TestEnrichStocks_WithTickers(t *testing.T) {
+	t.Log("Testing EnrichStocks: enriches requested tickers through the ingestion interface")
+	handlers, stockRepo, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ingestionSvc.On("EnrichStockData", mock.Anything, []string{"AAPL", "MSFT"}).Return(1, nil)
+
+	req, _ := http.NewRequest("POST", "/api/v1/enrich?tickers=aapl,%20MSFT,", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["tickers"])
+	assert.Equal(t, float64(1), response["refreshed"])
+
+	ingestionSvc.AssertExpectations(t)
+	stockRepo.AssertNotCalled(t, "GetUniqueTickers", mock.Anything)
+}
+
+func TestEnrichStocks_DefaultsToAllTickers(t *testing.T) {
+	t.Log("Testing EnrichStocks: enriches all known tickers when none are given")
+	handlers, stockRepo, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetUniqueTickers", mock.Anything).Return([]string{"AAPL", "GOOGL", "TSLA"}, nil)
+	ingestionSvc.On("EnrichStockData", mock.Anything, []string{"AAPL", "GOOGL", "TSLA"}).Return(3, nil)
+
+	req, _ := http.NewRequest("POST", "/api/v1/enrich", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stockRepo.AssertExpectations(t)
+	ingestionSvc.AssertExpectations(t)
+}
+
+func TestEnrichStocks_NotImplemented(t *testing.T) {
+	t.Log("Testing EnrichStocks: a stubbed enrichment source returns 501 instead of success")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ingestionSvc.On("EnrichStockData", mock.Anything, []string{"AAPL"}).
+		Return(0, fmt.Errorf("failed to enrich AAPL: %w", apperrors.ErrNotImplemented.WithDetails("news sentiment source")))
+
+	req, _ := http.NewRequest("POST", "/api/v1/enrich?tickers=AAPL", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, apperrors.ErrCodeNotImplemented, errorResp.Code)
+	assert.Equal(t, "news sentiment source", errorResp.Details)
+}
+
+func TestEnrichStocks_EnrichmentError(t *testing.T) {
+	t.Log("Testing EnrichStocks: propagates enrichment errors")
+	handlers, _, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ingestionSvc.On("EnrichStockData", mock.Anything, []string{"AAPL"}).
+		Return(0, apperrors.Wrap(fmt.Errorf("connection refused"), apperrors.ErrCodeDatabase, "failed to store enriched data"))
+
+	req, _ := http.NewRequest("POST", "/api/v1/enrich?tickers=AAPL", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	ingestionSvc.AssertExpectations(t)
+}
+
+func TestGetBulkPrices_Success(t *testing.T) {
+	t.Log("Testing GetBulkPrices: fetches each symbol once")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, symbol := range []string{"AAPL", "GOOGL", "MSFT"} {
+		bars := []domain.PriceBar{{Timestamp: "2023-12-01T09:30:00Z", Close: 100.0}}
+		alpacaSvc.On("GetHistoricalBars", mock.Anything, symbol, "1Day", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(bars, nil).Once()
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/prices?symbols=AAPL,googl,MSFT,AAPL&period=3M", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response BulkPriceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Results, 3)
+	assert.Len(t, response.Results["GOOGL"], 1)
+	assert.Empty(t, response.Errors)
+
+	alpacaSvc.AssertExpectations(t)
+	alpacaSvc.AssertNumberOfCalls(t, "GetHistoricalBars", 3)
+}
+
+func TestGetBulkPrices_PartialFailure(t *testing.T) {
+	t.Log("Testing GetBulkPrices: failing symbols are reported alongside successful ones")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	bars := []domain.PriceBar{{Timestamp: "2023-12-01T09:30:00Z", Close: 100.0}}
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "AAPL", mock.Anything, mock.Anything, mock.Anything).Return(bars, nil)
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "BAD", mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.PriceBar(nil), apperrors.ErrUpstreamAPIFailure.WithDetails("symbol not found"))
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/prices?symbols=AAPL,BAD", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response BulkPriceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Results, "AAPL")
+	assert.NotContains(t, response.Results, "BAD")
+	assert.Equal(t, "External API request failed: symbol not found", response.Errors["BAD"])
+
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGetBulkPrices_AllFailed(t *testing.T) {
+	t.Log("Testing GetBulkPrices: returns 502 when every symbol fails")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.PriceBar(nil), fmt.Errorf("alpaca unavailable"))
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/prices?symbols=AAPL,MSFT", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	var response BulkPriceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Results)
+	// Errors other than AppErrors are not echoed to the client
+	assert.Equal(t, map[string]string{"AAPL": genericErrorMessage, "MSFT": genericErrorMessage}, response.Errors)
+}
+
+func TestGetBulkPrices_Validation(t *testing.T) {
+	t.Log("Testing GetBulkPrices: rejects empty and oversized symbol lists")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	tooMany := make([]string, maxBulkSymbols+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	for _, query := range []string{"", "?symbols=", "?symbols=,,", "?symbols=" + strings.Join(tooMany, ",")} {
+		req, _ := http.NewRequest("GET", "/api/v1/stocks/prices"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "query %q", query)
+	}
+
+	alpacaSvc.AssertNotCalled(t, "GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetRecommendations_RoundsNumericFields(t *testing.T) {
+	t.Log("Testing GetRecommendations: rounds scores and targets to the configured precision")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	target := 180.12345
+	sentiment := 0.7250000000000001
+	recommendations := []domain.StockRecommendation{
+		{Ticker: "AAPL", Score: 0.8500000000000001, TargetPrice: &target, SentimentScore: &sentiment, GeneratedAt: time.Now()},
+	}
+	recommendationSvc.On("GetCachedRecommendations", mock.Anything).Return(recommendations, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/recommendations", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"score":0.85,`)
+	assert.Contains(t, w.Body.String(), `"target_price":180.12,`)
+	assert.Contains(t, w.Body.String(), `"sentiment_score":0.73,`)
+
+	// The cached recommendations must not be modified
+	assert.Equal(t, 0.8500000000000001, recommendations[0].Score)
+	assert.Equal(t, 180.12345, target)
+}
+
+func TestGetStockPrice_RoundsSummary(t *testing.T) {
+	t.Log("Testing GetStockPrice: rounds bars and summary to the configured precision")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	priceBars := []domain.PriceBar{
+		{Timestamp: "2023-12-01T09:30:00Z", Open: 100.004, High: 105.129, Low: 99.001, Close: 101.1, Volume: 1000},
+		{Timestamp: "2023-12-01T10:30:00Z", Open: 101.1, High: 103.5, Low: 100.2, Close: 103.3333333, Volume: 2000},
+	}
+	alpacaSvc.On("GetHistoricalBars", mock.Anything, "AAPL", mock.Anything, mock.Anything, mock.Anything).Return(priceBars, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response StockPriceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Summary)
+	assert.Equal(t, 100.0, response.Summary.First)
+	assert.Equal(t, 103.33, response.Summary.Last)
+	assert.Equal(t, 105.13, response.Summary.High)
+	assert.Equal(t, 99.0, response.Summary.Low)
+	assert.Equal(t, 3.33, response.Summary.ReturnPercent)
+	assert.Equal(t, 103.33, response.Bars[1].Close)
+}
+
+func TestRoundFloat(t *testing.T) {
+	t.Log("Testing roundFloat: rounds to places and leaves values alone when disabled")
+	assert.Equal(t, 0.85, roundFloat(0.8500000000000001, 2))
+	assert.Equal(t, 1.2346, roundFloat(1.23456, 4))
+	assert.Equal(t, 1.23456, roundFloat(1.23456, 0))
+	assert.Nil(t, roundFloatPtr(nil, 2))
+}
+
+func TestExportStockRatings_Success(t *testing.T) {
+	t.Log("Testing ExportStockRatings: streams ratings as CSV")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ratingTime := time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC)
+	ratings := []domain.StockRating{
+		{
+			Ticker:     "AAPL",
+			Company:    "Apple Inc.",
+			Brokerage:  "Goldman Sachs",
+			Action:     "upgraded by",
+			RatingFrom: stringPtr("Hold"),
+			RatingTo:   "Buy",
+			TargetFrom: float64Ptr(150.0),
+			TargetTo:   float64Ptr(180.555),
+			Time:       ratingTime,
 		},
 		{
-			name:         "negative integer",
-			queryValue:   "-5",
-			defaultValue: 10,
-			expected:     -5,
-			expectError:  false,
+			Ticker:    "MSFT",
+			Company:   "Microsoft, Inc.",
+			Brokerage: "Morgan Stanley",
+			Action:    "initiated by",
+			RatingTo:  "Overweight",
+			Time:      ratingTime,
 		},
+	}
+
+	expectedFilters := domain.FilterOptions{Search: "a", SortBy: "ticker", Order: domain.SortAscending}
+	stockRepo.On("StreamStockRatings", mock.Anything, expectedFilters).Return(ratings, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/export?search=a&sort_by=ticker&order=asc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="ratings.csv"`, w.Header().Get("Content-Disposition"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "ticker,company,brokerage,action,rating_from,rating_to,target_from,target_to,time", lines[0])
+	assert.Equal(t, "AAPL,Apple Inc.,Goldman Sachs,upgraded by,Hold,Buy,150,180.56,2024-12-24T12:00:00Z", lines[1])
+	assert.Equal(t, `MSFT,"Microsoft, Inc.",Morgan Stanley,initiated by,,Overweight,,,2024-12-24T12:00:00Z`, lines[2])
+
+	stockRepo.AssertExpectations(t)
+}
+
+func TestExportStockRatings_RepositoryError(t *testing.T) {
+	t.Log("Testing ExportStockRatings: returns a JSON error when the query fails before streaming")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("StreamStockRatings", mock.Anything, mock.Anything).
+		Return(nil, apperrors.Wrap(fmt.Errorf("connection refused"), apperrors.ErrCodeDatabase, "failed to query stock ratings"))
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportStockRatings_EmptyExport(t *testing.T) {
+	t.Log("Testing ExportStockRatings: no matching ratings returns 204, or a header-only CSV when configured")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("StreamStockRatings", mock.Anything, mock.Anything).Return([]domain.StockRating{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/export?search=nothing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	handlers.cfg.ExportEmptyHeaderOnly = true
+
+	req, _ = http.NewRequest("GET", "/api/v1/ratings/export?search=nothing&columns=ticker,time", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "ticker,time\n", w.Body.String())
+}
+
+func TestExportStockRatings_SelectedColumns(t *testing.T) {
+	t.Log("Testing ExportStockRatings: columns parameter selects and orders the exported fields")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	ratings := []domain.StockRating{
 		{
-			name:         "zero",
-			queryValue:   "0",
-			defaultValue: 10,
-			expected:     0,
-			expectError:  false,
+			Ticker:    "AAPL",
+			Company:   "Apple Inc.",
+			Brokerage: "Goldman Sachs",
+			Action:    "upgraded by",
+			RatingTo:  "Buy",
+			TargetTo:  float64Ptr(180.0),
+			Time:      time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC),
 		},
 	}
+	stockRepo.On("StreamStockRatings", mock.Anything, mock.Anything).Return(ratings, nil)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Logf("  - Sub-test: %s", tt.name)
-			// Create a test context with query parameter
-			w := httptest.NewRecorder()
-			c, _ := gin.CreateTestContext(w)
+	req, _ := http.NewRequest("GET", "/api/v1/ratings/export?columns=rating_to,ticker,%20Target_To", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-			if tt.queryValue != "" {
-				req, _ := http.NewRequest("GET", fmt.Sprintf("/?test=%s", tt.queryValue), nil)
-				c.Request = req
-			} else {
-				req, _ := http.NewRequest("GET", "/", nil)
-				c.Request = req
-			}
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "rating_to,ticker,target_to", lines[0])
+	assert.Equal(t, "Buy,AAPL,180", lines[1])
+}
+
+func TestExportStockRatings_InvalidColumns(t *testing.T) {
+	t.Log("Testing ExportStockRatings: unknown or duplicate columns are rejected")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	for _, columns := range []string{"ticker,price", "ticker,ticker", "ticker,"} {
+		req, _ := http.NewRequest("GET", "/api/v1/ratings/export?columns="+columns, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, columns)
+		assert.Contains(t, w.Body.String(), "invalid columns parameter", columns)
+	}
+
+	stockRepo.AssertNotCalled(t, "StreamStockRatings", mock.Anything, mock.Anything)
+}
+
+func TestGetBrokerageLeaderboard_Success(t *testing.T) {
+	t.Log("Testing GetBrokerageLeaderboard: sorted by count with ties broken by name")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetRatingsCountByBrokerage", mock.Anything).Return(map[string]int{
+		"Morgan Stanley": 17,
+		"Goldman Sachs":  42,
+		"Barclays":       17,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/analytics/brokerages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"brokerage": "Goldman Sachs", "count": 42},
+		{"brokerage": "Barclays", "count": 17},
+		{"brokerage": "Morgan Stanley", "count": 17}
+	]`, w.Body.String())
+	stockRepo.AssertExpectations(t)
+}
+
+func TestGetBrokerageLeaderboard_DatabaseError(t *testing.T) {
+	t.Log("Testing GetBrokerageLeaderboard: repository errors are returned")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetRatingsCountByBrokerage", mock.Anything).Return(nil, apperrors.ErrDatabaseFailure)
+
+	req, _ := http.NewRequest("GET", "/api/v1/analytics/brokerages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetRatingDistribution_Success(t *testing.T) {
+	t.Log("Testing GetRatingDistribution: returns counts per rating")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetRatingDistribution", mock.Anything).Return(map[string]int{"Buy": 120, "Hold": 45}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/analytics/rating-distribution", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"Buy": 120, "Hold": 45}`, w.Body.String())
+	stockRepo.AssertExpectations(t)
+}
+
+func TestGetRatingDistribution_Empty(t *testing.T) {
+	t.Log("Testing GetRatingDistribution: no ratings renders an empty object, not null")
+	handlers, stockRepo, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	stockRepo.On("GetRatingDistribution", mock.Anything).Return(map[string]int{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/analytics/rating-distribution", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{}", w.Body.String())
+}
+
+func TestGetSchemaVersion_Success(t *testing.T) {
+	t.Log("Testing GetSchemaVersion: reports the applied migration version")
+	handlers, _, _, _, _ := setupTestHandlers()
+	schemaRepo := new(MockSchemaRepository)
+	handlers.SetSchemaRepository(schemaRepo)
+	router := setupGinRouter(handlers)
+
+	schemaRepo.On("GetSchemaVersion", mock.Anything).Return(5, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/schema-version", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"schema_version": 5}`, w.Body.String())
+	schemaRepo.AssertExpectations(t)
+}
+
+func TestGetSchemaVersion_Errors(t *testing.T) {
+	t.Log("Testing GetSchemaVersion: missing repository and query failures")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/schema-version", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	schemaRepo := new(MockSchemaRepository)
+	schemaRepo.On("GetSchemaVersion", mock.Anything).Return(0, apperrors.ErrDatabaseFailure)
+	handlers.SetSchemaRepository(schemaRepo)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// Helper functions
+func stringPtr(s string) *string {
+	return &s
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func setupMaintenanceRouter(handlers *Handlers, mode *MaintenanceMode) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+
+	handlers.SetMaintenance(mode)
+
+	v1 := router.Group("/api/v1")
+	v1.Use(ReadOnly(mode))
+	{
+		v1.GET("/ratings/:ticker", handlers.GetStockRatingsByTicker)
+		v1.POST("/ingest", handlers.TriggerIngestion)
+		v1.GET("/admin/maintenance", handlers.GetMaintenanceMode)
+		v1.PUT("/admin/maintenance", handlers.SetMaintenanceMode)
+	}
+
+	return router
+}
+
+func TestGetRecommendationsForTickers_Success(t *testing.T) {
+	t.Log("Testing GetRecommendationsForTickers: scores a normalized watchlist")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	router := setupGinRouter(handlers)
 
-			result, err := parseIntQuery(c, "test", tt.defaultValue)
+	recommendationSvc.On("GenerateRecommendationsForTickers", mock.Anything, []string{"AAPL", "MSFT"}).
+		Return([]domain.StockRecommendation{
+			{Ticker: "MSFT", Score: 0.9123},
+			{Ticker: "AAPL", Score: 0.85},
+		}, nil)
 
-			if tt.expectError {
-				assert.Error(t, err)
-				assert.Equal(t, tt.expected, result)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
-			}
-		})
+	body := `{"tickers": ["aapl", " msft ", "AAPL"]}`
+	req, _ := http.NewRequest("POST", "/api/v1/recommendations/for", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Recommendations []domain.StockRecommendation `json:"recommendations"`
+		Count           int                          `json:"count"`
 	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "MSFT", response.Recommendations[0].Ticker)
+	assert.Equal(t, 0.91, response.Recommendations[0].Score)
+	recommendationSvc.AssertExpectations(t)
 }
 
-// Benchmarks for expensive operations
-func BenchmarkGetStockRatings(b *testing.B) {
-	b.Log("Benchmarking GetStockRatings endpoint")
-	handlers, stockRepo, _, _, _ := setupTestHandlers()
+func TestGetRecommendationsForTickers_InvalidRequests(t *testing.T) {
+	t.Log("Testing GetRecommendationsForTickers: rejects malformed bodies and empty or oversized watchlists")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
 	router := setupGinRouter(handlers)
 
-	// Setup large dataset
-	ratings := make([]domain.StockRating, 1000)
-	for i := 0; i < 1000; i++ {
-		ratings[i] = domain.StockRating{
-			RatingID:  uuid.New(),
-			Ticker:    fmt.Sprintf("TICK%d", i),
-			Company:   fmt.Sprintf("Company %d", i),
-			Brokerage: "Test Brokerage",
-			Action:    "upgraded by",
-			RatingTo:  "Buy",
-			Time:      time.Now().Add(-time.Duration(i) * time.Hour),
-			CreatedAt: time.Now().Add(-time.Duration(i) * time.Hour),
-		}
+	tooMany := make([]string, maxBulkSymbols+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("T%d", i)
 	}
+	tooManyBody, _ := json.Marshal(RecommendationsForTickersRequest{Tickers: tooMany})
 
-	expectedResponse := &domain.PaginatedResponse[domain.StockRating]{
-		Data: ratings,
-		Pagination: domain.Pagination{
-			Page:       1,
-			Limit:      20,
-			TotalItems: 10000,
-			TotalPages: 500,
-		},
+	tests := []struct {
+		body    string
+		details string
+	}{
+		{`{"tickers": "AAPL"}`, `request body must be {"tickers": [...]}`},
+		{`{"tickers": []}`, "tickers parameter is required"},
+		{`{"tickers": [" ", ""]}`, "tickers parameter is required"},
+		{string(tooManyBody), fmt.Sprintf("at most %d tickers may be requested at once", maxBulkSymbols)},
 	}
-	stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(expectedResponse, nil)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		req, _ := http.NewRequest("GET", "/api/v1/ratings", nil)
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", "/api/v1/recommendations/for", strings.NewReader(tt.body))
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.body)
+
+		var errorResp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+		assert.Equal(t, tt.details, errorResp.Details, tt.body)
 	}
+
+	recommendationSvc.AssertNotCalled(t, "GenerateRecommendationsForTickers", mock.Anything, mock.Anything)
 }
 
-func BenchmarkGetStockPrice(b *testing.B) {
-	b.Log("Benchmarking GetStockPrice endpoint")
-	handlers, _, _, _, alpacaSvc := setupTestHandlers()
-	router := setupGinRouter(handlers)
+func TestReadOnly_AllowsReadOnlyPost(t *testing.T) {
+	t.Log("Testing ReadOnly: watchlist recommendations stay available in maintenance mode")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	mode := NewMaintenanceMode(true, "")
+	router := setupMaintenanceRouter(handlers, mode)
+	router.POST("/api/v1/recommendations/for", ReadOnly(mode), handlers.GetRecommendationsForTickers)
 
-	// Setup large price data
-	priceBars := make([]domain.PriceBar, 1000)
-	for i := 0; i < 1000; i++ {
-		priceBars[i] = domain.PriceBar{
-			Timestamp: time.Now().Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
-			Open:      100.0 + float64(i)*0.1,
-			High:      105.0 + float64(i)*0.1,
-			Low:       99.0 + float64(i)*0.1,
-			Close:     104.0 + float64(i)*0.1,
-			Volume:    int64(1000000 + i*1000),
-		}
-	}
+	recommendationSvc.On("GenerateRecommendationsForTickers", mock.Anything, []string{"AAPL"}).
+		Return([]domain.StockRecommendation{}, nil)
 
-	alpacaSvc.On("GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(priceBars, nil)
+	req, _ := http.NewRequest("POST", "/api/v1/recommendations/for", strings.NewReader(`{"tickers": ["AAPL"]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/price", nil)
+	req, _ = http.NewRequest("POST", "/api/v1/ingest", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSetMaintenanceMode_Toggle(t *testing.T) {
+	t.Log("Testing SetMaintenanceMode: toggles the flag at runtime")
+	handlers, _, _, _, _ := setupTestHandlers()
+	mode := NewMaintenanceMode(false, "")
+	router := setupMaintenanceRouter(handlers, mode)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/maintenance", strings.NewReader(`{"enabled": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, mode.Enabled())
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/maintenance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled": true}`, w.Body.String())
+
+	// The toggle stays writable so maintenance mode can be turned off again
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/maintenance", strings.NewReader(`{"enabled": false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, mode.Enabled())
+}
+
+func TestSetMaintenanceMode_InvalidBody(t *testing.T) {
+	t.Log("Testing SetMaintenanceMode: rejects a body without enabled")
+	handlers, _, _, _, _ := setupTestHandlers()
+	router := setupMaintenanceRouter(handlers, NewMaintenanceMode(false, ""))
+
+	for _, body := range []string{`{}`, `not json`, `{"enabled": "yes"}`} {
+		req, _ := http.NewRequest("PUT", "/api/v1/admin/maintenance", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "body %q", body)
 	}
 }
 
-// Stress tests for concurrent operations
-func TestConcurrentGetStockRatings(t *testing.T) {
-	t.Log("Testing GetStockRatings: with high concurrency")
-	handlers, stockRepo, _, _, _ := setupTestHandlers()
+func TestReadOnly_BlocksWritesInMaintenanceMode(t *testing.T) {
+	t.Log("Testing ReadOnly: rejects writes but serves reads while maintenance mode is on")
+	handlers, stockRepo, ingestionSvc, _, _ := setupTestHandlers()
+	router := setupMaintenanceRouter(handlers, NewMaintenanceMode(true, ""))
+
+	stockRepo.On("GetStockRatingsByTicker", mock.Anything, "AAPL").Return([]domain.StockRating{{Ticker: "AAPL"}}, nil)
+
+	req, _ := http.NewRequest("POST", "/api/v1/ingest", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, apperrors.ErrCodeUnavailable, response.Code)
+	ingestionSvc.AssertNotCalled(t, "IngestAllData", mock.Anything)
+
+	req, _ = http.NewRequest("GET", "/api/v1/ratings/AAPL", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMaintenanceMode_PersistsState(t *testing.T) {
+	t.Log("Testing MaintenanceMode: persisted flag survives a restart")
+	stateFile := filepath.Join(t.TempDir(), "maintenance")
+
+	mode := NewMaintenanceMode(false, stateFile)
+	require.NoError(t, mode.SetEnabled(true))
+
+	restored := NewMaintenanceMode(false, stateFile)
+	assert.True(t, restored.Enabled())
+}
+
+func TestWarmSnapshots_Success(t *testing.T) {
+	t.Log("Testing WarmSnapshots: fetches snapshots in one call and reports missing symbols")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
 	router := setupGinRouter(handlers)
 
-	ratings := []domain.StockRating{
-		{
-			RatingID:  uuid.New(),
-			Ticker:    "AAPL",
-			Company:   "Apple Inc.",
-			Brokerage: "Goldman Sachs",
-			Action:    "upgraded by",
-			RatingTo:  "Buy",
-			Time:      time.Now(),
-			CreatedAt: time.Now(),
-		},
-	}
+	alpacaSvc.On("GetSnapshots", mock.Anything, []string{"AAPL", "MSFT", "NOPE"}).Return(map[string]*domain.Snapshot{
+		"AAPL": {Symbol: "AAPL"},
+		"MSFT": {Symbol: "MSFT"},
+	}, nil).Once()
 
-	expectedResponse := &domain.PaginatedResponse[domain.StockRating]{
-		Data: ratings,
-		Pagination: domain.Pagination{
-			Page:       1,
-			Limit:      20,
-			TotalItems: 1,
-			TotalPages: 1,
-		},
-	}
-	stockRepo.On("GetStockRatings", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+	req, _ := http.NewRequest("POST", "/api/v1/stocks/snapshots/warm", strings.NewReader(`{"symbols": ["aapl", "MSFT", "nope", "AAPL"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-	// Run 100 concurrent requests
-	concurrency := 100
-	done := make(chan bool, concurrency)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"warmed": 2, "missing": ["NOPE"]}`, w.Body.String())
+	alpacaSvc.AssertExpectations(t)
+}
 
-	for i := 0; i < concurrency; i++ {
-		go func() {
-			req, _ := http.NewRequest("GET", "/api/v1/ratings", nil)
-			w := httptest.NewRecorder()
-			router.ServeHTTP(w, req)
+func TestWarmSnapshots_Validation(t *testing.T) {
+	t.Log("Testing WarmSnapshots: rejects missing symbols")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
 
-			assert.Equal(t, http.StatusOK, w.Code)
-			done <- true
-		}()
+	for _, body := range []string{`{}`, `{"symbols": []}`, `not json`} {
+		req, _ := http.NewRequest("POST", "/api/v1/stocks/snapshots/warm", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "body %q", body)
 	}
 
-	// Wait for all requests to complete
-	for i := 0; i < concurrency; i++ {
-		<-done
+	alpacaSvc.AssertNotCalled(t, "GetSnapshots", mock.Anything, mock.Anything)
+}
+
+// dialSnapshotStream opens a snapshot stream for symbol against server
+func dialSnapshotStream(server *httptest.Server, symbol string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/stocks/" + symbol + "/stream"
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+func TestStreamStockSnapshots_PushesSnapshots(t *testing.T) {
+	t.Log("Testing StreamStockSnapshots: snapshots are pushed on each interval and the slot is freed on disconnect")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	handlers.SetSnapshotStreaming(10*time.Millisecond, 2)
+	server := httptest.NewServer(setupGinRouter(handlers))
+	defer server.Close()
+
+	alpacaSvc.On("IsMarketHours").Return(true)
+	alpacaSvc.On("GetSnapshot", mock.Anything, "AAPL").Return(&domain.Snapshot{
+		Symbol:      "AAPL",
+		LatestTrade: &domain.Trade{Price: 187.5},
+	}, nil)
+
+	conn, _, err := dialSnapshotStream(server, "aapl")
+	require.NoError(t, err)
+
+	for range 2 {
+		var snapshot domain.Snapshot
+		require.NoError(t, conn.ReadJSON(&snapshot))
+		assert.Equal(t, "AAPL", snapshot.Symbol)
+		require.NotNil(t, snapshot.LatestTrade)
+		assert.Equal(t, 187.5, snapshot.LatestTrade.Price)
 	}
+	assert.Equal(t, 1, handlers.streams.active())
 
-	stockRepo.AssertExpectations(t)
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool {
+		return handlers.streams.active() == 0
+	}, time.Second, 5*time.Millisecond)
 }
 
-func TestConcurrentGetStockPrice(t *testing.T) {
-	t.Log("Testing GetStockPrice: with high concurrency")
+func TestStreamStockSnapshots_ClosesWhenMarketCloses(t *testing.T) {
+	t.Log("Testing StreamStockSnapshots: the stream ends with a normal closure once the market closes")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	handlers.SetSnapshotStreaming(10*time.Millisecond, 2)
+	server := httptest.NewServer(setupGinRouter(handlers))
+	defer server.Close()
+
+	// Open for the handshake and the first poll, closed afterwards
+	alpacaSvc.On("IsMarketHours").Return(true).Twice()
+	alpacaSvc.On("IsMarketHours").Return(false)
+	alpacaSvc.On("GetSnapshot", mock.Anything, "AAPL").Return(&domain.Snapshot{Symbol: "AAPL"}, nil).Once()
+
+	conn, _, err := dialSnapshotStream(server, "AAPL")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var snapshot domain.Snapshot
+	require.NoError(t, conn.ReadJSON(&snapshot))
+	assert.Equal(t, "AAPL", snapshot.Symbol)
+
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+	assert.Equal(t, "market closed", closeErr.Text)
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestStreamStockSnapshots_LimitsConcurrentStreams(t *testing.T) {
+	t.Log("Testing StreamStockSnapshots: streams beyond the concurrent limit are refused with 503")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	handlers.SetSnapshotStreaming(time.Hour, 1)
+	server := httptest.NewServer(setupGinRouter(handlers))
+	defer server.Close()
+
+	alpacaSvc.On("IsMarketHours").Return(true)
+	alpacaSvc.On("GetSnapshot", mock.Anything, "AAPL").Return(&domain.Snapshot{Symbol: "AAPL"}, nil)
+
+	first, _, err := dialSnapshotStream(server, "AAPL")
+	require.NoError(t, err)
+	defer first.Close()
+	var snapshot domain.Snapshot
+	require.NoError(t, first.ReadJSON(&snapshot))
+
+	_, resp, err := dialSnapshotStream(server, "MSFT")
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	var errorResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errorResp))
+	assert.Equal(t, "too many concurrent snapshot streams", errorResp.Details)
+	alpacaSvc.AssertNotCalled(t, "GetSnapshot", mock.Anything, "MSFT")
+}
+
+func TestStreamStockSnapshots_MarketClosed(t *testing.T) {
+	t.Log("Testing StreamStockSnapshots: streams are refused before the upgrade while the market is closed")
 	handlers, _, _, _, alpacaSvc := setupTestHandlers()
 	router := setupGinRouter(handlers)
 
-	priceBars := []domain.PriceBar{
-		{
-			Timestamp: "2023-12-01T09:30:00Z",
-			Open:      100.0,
-			High:      105.0,
-			Low:       99.0,
-			Close:     104.0,
-			Volume:    1000000,
-		},
-	}
+	alpacaSvc.On("IsMarketHours").Return(false)
 
-	alpacaSvc.On("GetHistoricalBars", mock.Anything, mock.Anything, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(priceBars, nil)
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/AAPL/stream", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-	// Run 50 concurrent requests
-	concurrency := 50
-	done := make(chan bool, concurrency)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 
-	for i := 0; i < concurrency; i++ {
-		go func(id int) {
-			symbol := fmt.Sprintf("STOCK%d", id%10) // Use 10 different symbols
-			req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/stocks/%s/price", symbol), nil)
-			w := httptest.NewRecorder()
-			router.ServeHTTP(w, req)
+	var errorResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, "snapshots are only streamed during market hours", errorResp.Details)
+	assert.Equal(t, 0, handlers.streams.active())
+	alpacaSvc.AssertNotCalled(t, "GetSnapshot", mock.Anything, mock.Anything)
+}
 
-			assert.Equal(t, http.StatusOK, w.Code)
-			done <- true
-		}(i)
+// openRecommendationStream subscribes to the recommendation stream on server and
+// returns the response with a reader over its body
+func openRecommendationStream(t *testing.T, server *httptest.Server) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	resp, err := http.Get(server.URL + "/api/v1/recommendations/stream")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// readEvent reads one Server-Sent Events block, up to and excluding its blank line
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
 	}
+}
 
-	// Wait for all requests to complete
-	for i := 0; i < concurrency; i++ {
-		<-done
+func TestStreamRecommendations_PushesRefreshes(t *testing.T) {
+	t.Log("Testing StreamRecommendations: each refresh is sent as a data event and the subscription ends on disconnect")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	server := httptest.NewServer(setupGinRouter(handlers))
+	defer server.Close()
+
+	updates := make(chan []domain.StockRecommendation, 1)
+	unsubscribed := make(chan struct{})
+	recommendationSvc.On("Subscribe").Return(updates, func() { close(unsubscribed) }).Once()
+
+	resp, reader := openRecommendationStream(t, server)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	updates <- []domain.StockRecommendation{{Ticker: "AAPL", Score: 8.456789}}
+
+	event := readEvent(t, reader)
+	require.Len(t, event, 1)
+	data, found := strings.CutPrefix(event[0], "data: ")
+	require.True(t, found, event[0])
+
+	var recommendations []domain.StockRecommendation
+	require.NoError(t, json.Unmarshal([]byte(data), &recommendations))
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "AAPL", recommendations[0].Ticker)
+	assert.Equal(t, 8.46, recommendations[0].Score, "scores are rounded like other responses")
+
+	require.NoError(t, resp.Body.Close())
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscription to end when the client disconnected")
 	}
+	recommendationSvc.AssertExpectations(t)
+}
 
-	alpacaSvc.AssertExpectations(t)
+func TestStreamRecommendations_SendsHeartbeats(t *testing.T) {
+	t.Log("Testing StreamRecommendations: idle streams send heartbeat comments")
+	handlers, _, _, recommendationSvc, _ := setupTestHandlers()
+	handlers.SetRecommendationHeartbeat(10 * time.Millisecond)
+	server := httptest.NewServer(setupGinRouter(handlers))
+	defer server.Close()
+
+	recommendationSvc.On("Subscribe").Return(make(chan []domain.StockRecommendation), func() {})
+
+	resp, reader := openRecommendationStream(t, server)
+	defer resp.Body.Close()
+
+	for range 2 {
+		assert.Equal(t, []string{": heartbeat"}, readEvent(t, reader))
+	}
+}
+
+func TestGetStockSnapshot_Success(t *testing.T) {
+	t.Log("Testing GetStockSnapshot: returns the snapshot for a symbol")
+	handlers, _, _, _, alpacaSvc := setupTestHandlers()
+	router := setupGinRouter(handlers)
+
+	alpacaSvc.On("GetSnapshot", mock.Anything, "AAPL").Return(&domain.Snapshot{
+		Symbol:      "AAPL",
+		LatestTrade: &domain.Trade{Price: 150.0},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/stocks/aapl/snapshot", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.Snapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "AAPL", response.Symbol)
+	require.NotNil(t, response.LatestTrade)
+	assert.Equal(t, 150.0, response.LatestTrade.Price)
 }
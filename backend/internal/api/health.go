@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout bounds the database ping so load-balancer probes stay fast
+const healthCheckTimeout = 2 * time.Second
+
+// Readiness tracks whether the service may receive traffic beyond its
+// dependencies being reachable. When ingestion is required, the service only
+// reports ready once an ingestion run has completed.
+type Readiness struct {
+	requireIngestion bool
+	ingested         atomic.Bool
+}
+
+// NewReadiness creates a readiness state; requireIngestion holds readiness back
+// until MarkIngested is called
+func NewReadiness(requireIngestion bool) *Readiness {
+	return &Readiness{requireIngestion: requireIngestion}
+}
+
+// MarkIngested records that an ingestion run has completed
+func (r *Readiness) MarkIngested() {
+	r.ingested.Store(true)
+}
+
+// ingestionPending reports whether readiness is still waiting for ingestion
+func (r *Readiness) ingestionPending() bool {
+	return r.requireIngestion && !r.ingested.Load()
+}
+
+// healthCache remembers the last database ping so frequent probes within ttl reuse
+// its result instead of pinging again. A zero ttl disables caching.
+type healthCache struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	checkedAt time.Time
+	err       error
+}
+
+// pingDatabase checks the database with a short deadline; it succeeds when no
+// health checker is configured. Within the health cache window the previous
+// result, success or failure, is returned without pinging.
+func (h *Handlers) pingDatabase(ctx context.Context) error {
+	if h.healthChecker == nil {
+		return nil
+	}
+
+	// Holding the lock through the ping makes concurrent probes share one ping
+	h.health.mutex.Lock()
+	defer h.health.mutex.Unlock()
+
+	now := h.clock.Now()
+	if h.health.ttl > 0 && !h.health.checkedAt.IsZero() && now.Sub(h.health.checkedAt) < h.health.ttl {
+		return h.health.err
+	}
+
+	err := h.pingWithTimeout(ctx)
+	h.health.checkedAt = now
+	h.health.err = err
+	return err
+}
+
+// pingWithTimeout pings the health checker, bounded by healthCheckTimeout
+func (h *Handlers) pingWithTimeout(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := h.healthChecker.Ping(ctx); err != nil {
+		slog.WarnContext(ctx, "database health check failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// HealthCheck returns the health status of the service, pinging the database
+// when a health checker is configured
+func (h *Handlers) HealthCheck(c *gin.Context) {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	if err := h.pingDatabase(c.Request.Context()); err != nil {
+		unavailable := apperrors.ErrServiceUnavailable.WithDetails("database unreachable")
+		c.JSON(unavailable.HTTPStatus(), gin.H{
+			"status":    "unhealthy",
+			"service":   "stock-analyzer",
+			"timestamp": timestamp,
+			"code":      unavailable.Code,
+			"details":   unavailable.Details,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "healthy",
+		"service":   "stock-analyzer",
+		"timestamp": timestamp,
+	})
+}
+
+// LivenessCheck reports that the process is up. It never touches dependencies,
+// so an orchestrator only restarts the service when it is truly stuck.
+func (h *Handlers) LivenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "alive",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// ReadinessCheck reports whether the service can take traffic: the database must
+// be reachable and, when required, initial ingestion must have completed
+func (h *Handlers) ReadinessCheck(c *gin.Context) {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	var details string
+	switch {
+	case h.pingDatabase(c.Request.Context()) != nil:
+		details = "database unreachable"
+	case h.readiness.ingestionPending():
+		details = "initial ingestion has not completed"
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "ready",
+			"timestamp": timestamp,
+		})
+		return
+	}
+
+	unavailable := apperrors.ErrServiceUnavailable.WithDetails(details)
+	c.JSON(unavailable.HTTPStatus(), gin.H{
+		"status":    "not_ready",
+		"timestamp": timestamp,
+		"code":      unavailable.Code,
+		"details":   unavailable.Details,
+	})
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"stock-analyzer/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ingestionJobTimeout bounds a triggered ingestion run, which outlives the HTTP request
+const ingestionJobTimeout = 15 * time.Minute
+
+// maxRetainedIngestionJobs caps how many jobs the registry remembers; the oldest
+// jobs are evicted first
+const maxRetainedIngestionJobs = 100
+
+// IngestionJobStatus is the lifecycle state of a triggered ingestion run
+type IngestionJobStatus string
+
+const (
+	IngestionJobRunning   IngestionJobStatus = "running"
+	IngestionJobSucceeded IngestionJobStatus = "succeeded"
+	IngestionJobFailed    IngestionJobStatus = "failed"
+)
+
+// IngestionJob describes a triggered ingestion run and, once finished, its outcome
+type IngestionJob struct {
+	ID         string                  `json:"job_id"`
+	Status     IngestionJobStatus      `json:"status"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+	Result     *domain.IngestionResult `json:"result,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// ingestionJobs is an in-memory registry of triggered ingestion runs. At most
+// one job runs at a time so concurrent triggers don't duplicate upstream work.
+type ingestionJobs struct {
+	mu      sync.RWMutex
+	jobs    map[string]*IngestionJob
+	order   []string
+	running string // ID of the job currently running, or "" when idle
+}
+
+func newIngestionJobs() *ingestionJobs {
+	return &ingestionJobs{jobs: make(map[string]*IngestionJob)}
+}
+
+// start registers a new running job and returns a copy of it. If a job is
+// already running, no job is started and that job is returned with false.
+func (r *ingestionJobs) start(now time.Time) (IngestionJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running != "" {
+		return *r.jobs[r.running], false
+	}
+
+	job := &IngestionJob{
+		ID:        uuid.NewString(),
+		Status:    IngestionJobRunning,
+		StartedAt: now,
+	}
+
+	r.evictLocked()
+	r.jobs[job.ID] = job
+	r.order = append(r.order, job.ID)
+	r.running = job.ID
+	return *job, true
+}
+
+// finish records the outcome of a job; a nil err marks it succeeded. A failed
+// job keeps any partial result the run returned.
+func (r *ingestionJobs) finish(id string, now time.Time, result *domain.IngestionResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running == id {
+		r.running = ""
+	}
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return
+	}
+
+	job.FinishedAt = &now
+	job.Result = result
+	if err != nil {
+		job.Status = IngestionJobFailed
+		job.Error = err.Error()
+		return
+	}
+	job.Status = IngestionJobSucceeded
+}
+
+// get returns a copy of the job with the given ID
+func (r *ingestionJobs) get(id string) (IngestionJob, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return IngestionJob{}, false
+	}
+	return *job, true
+}
+
+// evictLocked drops the oldest jobs once the registry is full. It is only
+// called when no job is running, so every evicted job has finished. The caller
+// must hold the write lock.
+func (r *ingestionJobs) evictLocked() {
+	for len(r.order) >= maxRetainedIngestionJobs {
+		delete(r.jobs, r.order[0])
+		r.order = r.order[1:]
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import "strings"
+
+// logoDomains maps tickers to the company's primary web domain for tickers
+// where the domain is not simply "<ticker>.com". Add new entries here.
+var logoDomains = map[string]string{
+	"AAPL":  "apple.com",
+	"ABBV":  "abbvie.com",
+	"AMZN":  "amazon.com",
+	"BAC":   "bankofamerica.com",
+	"BRK.A": "berkshirehathaway.com",
+	"BRK.B": "berkshirehathaway.com",
+	"COST":  "costco.com",
+	"GOOG":  "abc.xyz",
+	"GOOGL": "abc.xyz",
+	"HD":    "homedepot.com",
+	"JNJ":   "jnj.com",
+	"JPM":   "jpmorganchase.com",
+	"KO":    "coca-colacompany.com",
+	"MA":    "mastercard.com",
+	"META":  "meta.com",
+	"MSFT":  "microsoft.com",
+	"NFLX":  "netflix.com",
+	"NVDA":  "nvidia.com",
+	"PG":    "pg.com",
+	"TSLA":  "tesla.com",
+	"UNH":   "unitedhealthgroup.com",
+	"V":     "visa.com",
+	"WMT":   "walmart.com",
+	"XOM":   "exxonmobil.com",
+}
+
+func init() {
+	// Normalize keys and values so hand-edited entries cannot break lookups
+	normalized := make(map[string]string, len(logoDomains))
+	for ticker, domain := range logoDomains {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if ticker == "" || domain == "" {
+			continue
+		}
+		normalized[ticker] = domain
+	}
+	logoDomains = normalized
+}
+
+// hasLogoDomain reports whether a ticker has a known company domain
+func hasLogoDomain(symbol string) bool {
+	_, exists := logoDomains[strings.ToUpper(symbol)]
+	return exists
+}
+
+// resolveLogoDomain returns the company domain for a ticker, falling back to "<ticker>.com"
+func resolveLogoDomain(symbol string) string {
+	if domain, exists := logoDomains[strings.ToUpper(symbol)]; exists {
+		return domain
+	}
+
+	return strings.ToLower(symbol) + ".com"
+}
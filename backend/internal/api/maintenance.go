@@ -0,0 +1,138 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenancePath is the admin route that toggles maintenance mode; it stays
+// writable while maintenance mode is on so the mode can be turned off again
+const maintenancePath = "/api/v1/admin/maintenance"
+
+// readOnlyPostPaths are POST routes that only read data, so maintenance mode allows them
+var readOnlyPostPaths = map[string]bool{
+	"/api/v1/recommendations/for": true,
+}
+
+// MaintenanceMode is a runtime toggle that pauses write requests. When a state
+// file is configured the flag survives restarts.
+type MaintenanceMode struct {
+	mu        sync.RWMutex
+	enabled   bool
+	stateFile string
+}
+
+// NewMaintenanceMode creates a maintenance toggle. If stateFile is set and holds a
+// previously persisted value, that value takes precedence over enabled.
+func NewMaintenanceMode(enabled bool, stateFile string) *MaintenanceMode {
+	mode := &MaintenanceMode{
+		enabled:   enabled,
+		stateFile: stateFile,
+	}
+
+	if stateFile == "" {
+		return mode
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read maintenance state file", "path", stateFile, "error", err)
+		}
+		return mode
+	}
+
+	persisted, err := strconv.ParseBool(strings.TrimSpace(string(data)))
+	if err != nil {
+		slog.Warn("ignoring invalid maintenance state file", "path", stateFile, "error", err)
+		return mode
+	}
+
+	mode.enabled = persisted
+	return mode
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *MaintenanceMode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// SetEnabled turns maintenance mode on or off, persisting the flag when a state
+// file is configured. The in-memory flag is updated even if persisting fails.
+func (m *MaintenanceMode) SetEnabled(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = enabled
+
+	if m.stateFile == "" {
+		return nil
+	}
+
+	if err := os.WriteFile(m.stateFile, []byte(strconv.FormatBool(enabled)), 0o644); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeInternal, "failed to persist maintenance mode")
+	}
+
+	return nil
+}
+
+// ReadOnly middleware rejects write requests with a 503 while maintenance mode is on.
+// Reads, read-only POST queries, and the maintenance toggle itself are always allowed.
+func ReadOnly(mode *MaintenanceMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mode.Enabled() || isReadMethod(c.Request.Method) || c.FullPath() == maintenancePath ||
+			(c.Request.Method == http.MethodPost && readOnlyPostPaths[c.FullPath()]) {
+			c.Next()
+			return
+		}
+
+		HandleError(c, apperrors.ErrMaintenanceMode)
+		c.Abort()
+	}
+}
+
+// isReadMethod reports whether an HTTP method is safe to serve in read-only mode
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// MaintenanceModeRequest is the body accepted by SetMaintenanceMode
+type MaintenanceModeRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// GetMaintenanceMode reports whether maintenance mode is on
+func (h *Handlers) GetMaintenanceMode(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"enabled": h.maintenance.Enabled()})
+}
+
+// SetMaintenanceMode turns maintenance mode on or off at runtime
+func (h *Handlers) SetMaintenanceMode(c *gin.Context) {
+	var req MaintenanceModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("request body must be {\"enabled\": true|false}"))
+		return
+	}
+
+	if err := h.maintenance.SetEnabled(*req.Enabled); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": *req.Enabled})
+}
@@ -2,19 +2,85 @@ package api
 
 import (
 	"errors"
+	"log/slog"
+	"math"
 	"net/http"
-	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	apperrors "stock-analyzer/pkg/errors"
+	"stock-analyzer/pkg/locale"
+	"stock-analyzer/pkg/logging"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// RequestIDHeader is the header used to propagate request correlation IDs
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDContextKey is the Gin context key holding the request ID
+const requestIDContextKey = "request_id"
+
+// maxRequestIDLength matches the audit_log.request_id column
+const maxRequestIDLength = 100
+
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code"`
-	Details string `json:"details,omitempty"`
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Details   string `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// RequestID middleware assigns every request a correlation ID, reusing the
+// incoming X-Request-ID header when it is a valid ID, and exposes it on the response
+// header, the Gin context, and the request context passed to services
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(requestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID can be reused: it must
+// fit the audit_log column and contain only visible ASCII, so it is safe to log and echo
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Locale middleware stores the locale negotiated from the Accept-Language header in
+// the request context, so services can format text for the client. Requests without
+// a supported language get the en-US default.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loc := locale.Parse(c.GetHeader("Accept-Language"))
+
+		c.Header("Content-Language", loc.Tag)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Request = c.Request.WithContext(locale.WithLocale(c.Request.Context(), loc))
+
+		c.Next()
+	}
 }
 
 // ErrorHandler middleware handles application errors and converts them to HTTP responses
@@ -23,16 +89,18 @@ func ErrorHandler() gin.HandlerFunc {
 		if err, ok := recovered.(error); ok {
 			handleError(c, err)
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Internal server error",
-				Code:  apperrors.ErrCodeInternal,
+			writeError(c, http.StatusInternalServerError, ErrorResponse{
+				Error:     "Internal server error",
+				Code:      apperrors.ErrCodeInternal,
+				RequestID: c.GetString(requestIDContextKey),
 			})
 		}
 		c.Abort()
 	})
 }
 
-// HandleError is a helper function to handle errors in handlers
+// HandleError is a helper function to handle errors in handlers. The error is
+// sent as RFC 7807 problem details when the client accepts application/problem+json.
 func HandleError(c *gin.Context, err error) {
 	handleError(c, err)
 }
@@ -41,74 +109,84 @@ func handleError(c *gin.Context, err error) {
 	var appErr *apperrors.AppError
 
 	if errors.As(err, &appErr) {
-		println("🔴 AppError:", appErr.Error())
-		c.JSON(appErr.HTTPStatus(), ErrorResponse{
-			Error:   appErr.Message,
-			Code:    appErr.Code,
-			Details: appErr.Details,
+		slog.ErrorContext(c.Request.Context(), "request failed",
+			"request_id", c.GetString(requestIDContextKey), "code", appErr.Code, "error", appErr)
+		if appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+		writeError(c, appErr.HTTPStatus(), ErrorResponse{
+			Error:     appErr.Message,
+			Code:      appErr.Code,
+			Details:   appErr.Details,
+			RequestID: c.GetString(requestIDContextKey),
 		})
 		return
 	}
 
-	println("🔴 Unknown Error:", err.Error())
-	c.JSON(http.StatusInternalServerError, ErrorResponse{
-		Error:   err.Error(),
-		Code:    apperrors.ErrCodeInternal,
-		Details: "Raw error returned for debugging purposes",
+	slog.ErrorContext(c.Request.Context(), "request failed with unknown error",
+		"request_id", c.GetString(requestIDContextKey), "error", err)
+	writeError(c, http.StatusInternalServerError, ErrorResponse{
+		Error:     err.Error(),
+		Code:      apperrors.ErrCodeInternal,
+		Details:   "Raw error returned for debugging purposes",
+		RequestID: c.GetString(requestIDContextKey),
 	})
 }
 
-// CORS middleware to handle cross-origin requests
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		origin := c.GetHeader("Origin")
+// defaultCORSOrigins are the local frontend dev servers that are always allowed
+var defaultCORSOrigins = []string{
+	"http://localhost:5173",
+	"http://localhost:3000",
+	"http://127.0.0.1:5173",
+	"http://127.0.0.1:3000",
+}
 
-		allowedOrigins := []string{
-			"http://localhost:5173",
-			"http://localhost:3000",
-			"http://127.0.0.1:5173",
-			"http://127.0.0.1:3000",
+// CORS middleware to handle cross-origin requests. The local dev servers and the
+// given origins are allowed and echoed back; any other origin gets a wildcard in
+// development and no Access-Control-Allow-Origin header elsewhere, so browsers
+// block the response. The allowlist is fixed when the middleware is created.
+// Preflight requests are answered with 204 and, unlike other responses, are not
+// marked no-cache.
+func CORS(allowedOrigins []string, development bool) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(defaultCORSOrigins)+len(allowedOrigins))
+	for _, origin := range slices.Concat(defaultCORSOrigins, allowedOrigins) {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowed[origin] = true
 		}
+	}
 
-		if frontendURL := os.Getenv("FRONTEND_URL"); frontendURL != "" {
-			allowedOrigins = append(allowedOrigins, frontendURL)
-		}
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
 
 		var allowedOrigin string
-		for _, allowedOrigin = range allowedOrigins {
-			if origin == allowedOrigin {
-				break
-			}
+		if allowed[origin] {
+			allowedOrigin = origin
+		} else if development {
+			allowedOrigin = "*"
 		}
 
-		if origin != allowedOrigin {
-			if os.Getenv("ENVIRONMENT") == "development" || os.Getenv("FRONTEND_URL") == "" {
-				allowedOrigin = "*"
-			} else {
-				if frontendURL := os.Getenv("FRONTEND_URL"); frontendURL != "" {
-					allowedOrigin = frontendURL
-				} else {
-					allowedOrigin = allowedOrigins[0]
-				}
-			}
+		if allowedOrigin != "" {
+			c.Header("Access-Control-Allow-Origin", allowedOrigin)
 		}
-
-		c.Header("Access-Control-Allow-Origin", allowedOrigin)
+		// The allowed origin depends on the request's Origin, so shared caches must key on it
+		c.Writer.Header().Add("Vary", "Origin")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, HEAD")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Accept, Origin, X-Api-Key, X-Amz-Date, X-Amz-Security-Token")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Accept, Origin, X-Api-Key, X-Amz-Date, X-Amz-Security-Token, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type, X-Request-ID")
 		c.Header("Access-Control-Allow-Credentials", "false")
 		c.Header("Access-Control-Max-Age", "86400")
 
-		c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-		c.Header("Pragma", "no-cache")
-		c.Header("Expires", "0")
-
+		// Preflights are left cacheable so browsers honor Access-Control-Max-Age
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		// API data is not cached by default; handlers serving static assets override this
+		c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+		c.Header("Pragma", "no-cache")
+		c.Header("Expires", "0")
+
 		c.Next()
 	}
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"math"
+
+	"stock-analyzer/internal/domain"
+)
+
+// roundFloat rounds v to the given number of decimal places. Non-positive
+// places leave the value untouched so rounding can be disabled.
+func roundFloat(v float64, places int) float64 {
+	if places <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
+
+// roundFloatPtr returns a rounded copy of v, or nil if v is nil
+func roundFloatPtr(v *float64, places int) *float64 {
+	if v == nil {
+		return nil
+	}
+
+	rounded := roundFloat(*v, places)
+	return &rounded
+}
+
+// roundBars returns a copy of bars with prices rounded for output
+func roundBars(bars []domain.PriceBar, places int) []domain.PriceBar {
+	if bars == nil {
+		return nil
+	}
+
+	rounded := make([]domain.PriceBar, len(bars))
+	for i, bar := range bars {
+		bar.Open = roundFloat(bar.Open, places)
+		bar.High = roundFloat(bar.High, places)
+		bar.Low = roundFloat(bar.Low, places)
+		bar.Close = roundFloat(bar.Close, places)
+		rounded[i] = bar
+	}
+
+	return rounded
+}
+
+// roundSummary returns a copy of summary with prices and return rounded for output
+func roundSummary(summary *PriceSummary, places int) *PriceSummary {
+	if summary == nil {
+		return nil
+	}
+
+	rounded := *summary
+	rounded.First = roundFloat(summary.First, places)
+	rounded.Last = roundFloat(summary.Last, places)
+	rounded.High = roundFloat(summary.High, places)
+	rounded.Low = roundFloat(summary.Low, places)
+	rounded.ReturnPercent = roundFloat(summary.ReturnPercent, places)
+
+	return &rounded
+}
+
+// roundRatings returns a copy of ratings with price targets rounded for output
+func roundRatings(ratings []domain.StockRating, places int) []domain.StockRating {
+	if ratings == nil {
+		return nil
+	}
+
+	rounded := make([]domain.StockRating, len(ratings))
+	for i, rating := range ratings {
+		rating.TargetFrom = roundFloatPtr(rating.TargetFrom, places)
+		rating.TargetTo = roundFloatPtr(rating.TargetTo, places)
+		rounded[i] = rating
+	}
+
+	return rounded
+}
+
+// roundRecommendations returns a copy of recommendations with scores and targets rounded for output
+func roundRecommendations(recommendations []domain.StockRecommendation, places int) []domain.StockRecommendation {
+	if recommendations == nil {
+		return nil
+	}
+
+	rounded := make([]domain.StockRecommendation, len(recommendations))
+	for i, recommendation := range recommendations {
+		recommendation.Score = roundFloat(recommendation.Score, places)
+		recommendation.NormalizedScore = roundFloatPtr(recommendation.NormalizedScore, places)
+		recommendation.TargetPrice = roundFloatPtr(recommendation.TargetPrice, places)
+		recommendation.SentimentScore = roundFloatPtr(recommendation.SentimentScore, places)
+		rounded[i] = recommendation
+	}
+
+	return rounded
+}
@@ -0,0 +1,44 @@
+package api
+
+import "stock-analyzer/internal/domain"
+
+// PriceSummary describes the change across a series of price bars
+type PriceSummary struct {
+	First         float64 `json:"first"`          // Opening price of the first bar
+	Last          float64 `json:"last"`           // Closing price of the last bar
+	High          float64 `json:"high"`           // Highest price across all bars
+	Low           float64 `json:"low"`            // Lowest price across all bars
+	TotalVolume   int64   `json:"total_volume"`   // Sum of traded volume across all bars
+	ReturnPercent float64 `json:"return_percent"` // Percent change from First to Last
+}
+
+// summarizeBars computes a PriceSummary for bars ordered oldest to newest.
+// It returns nil when there are no bars to summarize.
+func summarizeBars(bars []domain.PriceBar) *PriceSummary {
+	if len(bars) == 0 {
+		return nil
+	}
+
+	summary := &PriceSummary{
+		First: bars[0].Open,
+		Last:  bars[len(bars)-1].Close,
+		High:  bars[0].High,
+		Low:   bars[0].Low,
+	}
+
+	for _, bar := range bars {
+		if bar.High > summary.High {
+			summary.High = bar.High
+		}
+		if bar.Low < summary.Low {
+			summary.Low = bar.Low
+		}
+		summary.TotalVolume += bar.Volume
+	}
+
+	if summary.First != 0 {
+		summary.ReturnPercent = (summary.Last - summary.First) / summary.First * 100
+	}
+
+	return summary
+}
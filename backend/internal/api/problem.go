@@ -0,0 +1,64 @@
+package api
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details responses
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix namespaces the problem type URI of each application error code
+const problemTypePrefix = "urn:stock-analyzer:problem:"
+
+// ProblemDetails is an RFC 7807 error body, sent instead of ErrorResponse when the
+// client accepts application/problem+json. Code and RequestID are extension members
+// carrying the same values as ErrorResponse.
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// newProblemDetails maps an ErrorResponse onto the RFC 7807 members. The type is
+// derived from the error code, so every error with the same code shares a type.
+func newProblemDetails(status int, resp ErrorResponse, instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:      problemTypePrefix + strings.ToLower(strings.ReplaceAll(resp.Code, "_", "-")),
+		Title:     resp.Error,
+		Status:    status,
+		Detail:    resp.Details,
+		Instance:  instance,
+		Code:      resp.Code,
+		RequestID: resp.RequestID,
+	}
+}
+
+// acceptsProblemJSON reports whether the Accept header lists application/problem+json
+func acceptsProblemJSON(c *gin.Context) bool {
+	for _, accepted := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError sends an error body with status, as problem details when the client
+// negotiated application/problem+json and as ErrorResponse otherwise
+func writeError(c *gin.Context, status int, resp ErrorResponse) {
+	if acceptsProblemJSON(c) {
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, newProblemDetails(status, resp, c.Request.URL.Path))
+		return
+	}
+
+	c.JSON(status, resp)
+}
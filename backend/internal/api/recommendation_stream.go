@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRecommendationHeartbeat is how often an idle recommendation stream sends a
+// comment line so proxies and clients keep the connection open
+const DefaultRecommendationHeartbeat = 15 * time.Second
+
+// StreamRecommendations pushes the recommendation list as a Server-Sent Event each time
+// the recommendation cache is refreshed, with heartbeat comments in between. Nothing is
+// sent on connect; clients load the current list from /recommendations. The subscription
+// ends when the client disconnects.
+//
+// Every subscriber receives the same refresh, so rationales are in the locale of the
+// request that triggered it rather than the subscriber's Accept-Language.
+func (h *Handlers) StreamRecommendations(c *gin.Context) {
+	updates, unsubscribe := h.recommendationSvc.Subscribe()
+	defer unsubscribe()
+
+	clearWriteDeadline(c)
+
+	ctx := c.Request.Context()
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Stops nginx-style proxies from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var event string
+		select {
+		case <-ctx.Done():
+			return
+		case recommendations := <-updates:
+			data, err := json.Marshal(roundRecommendations(recommendations, h.cfg.JSONDecimalPlaces))
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to encode recommendation event", "error", err)
+				continue
+			}
+			event = fmt.Sprintf("data: %s\n\n", data)
+		case <-heartbeat.C:
+			event = ": heartbeat\n\n"
+		}
+
+		if _, err := c.Writer.WriteString(event); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
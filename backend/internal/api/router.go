@@ -1,44 +1,150 @@
 package api
 
 import (
+	"maps"
+	"time"
+
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// SetupRouter creates and configures the HTTP router
-func SetupRouter(stockRepo domain.StockRepository, ingestionSvc domain.IngestionService, recommendationSvc domain.RecommendationService, alpacaSvc domain.AlpacaService) *gin.Engine {
+// SetupRouter creates and configures the HTTP router. readiness is reported by
+// /health/ready; nil means the service is ready whenever the database is reachable.
+func SetupRouter(stockRepo domain.StockRepository, ingestionSvc domain.IngestionService, recommendationSvc domain.RecommendationService, alpacaSvc domain.AlpacaService, cfg *config.Config, readiness *Readiness) *gin.Engine {
 	// Create Gin router
 	router := gin.New()
 
 	// Add middleware
 	router.Use(gin.Logger())
+	router.Use(RequestID())
+	router.Use(Locale())
+	router.Use(Metrics())
 	router.Use(ErrorHandler())
-	router.Use(CORS())
+	router.Use(CORS(cfg.AllowedOrigins(), cfg.IsDevelopment()))
+	router.Use(RouteTimeout(withoutTimeout(cfg.RouteTimeouts, snapshotStreamRoute, recommendationStreamRoute, ratingsExportRoute), time.Duration(cfg.RequestTimeout)*time.Second))
 
 	// Create handlers
-	handlers := NewHandlers(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc)
+	handlers := NewHandlers(stockRepo, ingestionSvc, recommendationSvc, alpacaSvc, cfg)
+
+	// Admin actions are always audited; persist them when the repository supports it
+	var auditRepo domain.AuditRepository
+	if repo, ok := stockRepo.(domain.AuditRepository); ok && cfg.AuditLogPersist {
+		auditRepo = repo
+	}
+	auditor := NewAuditLogger(auditRepo, nil)
+
+	// Maintenance mode pauses writes at runtime without a redeploy
+	maintenance := NewMaintenanceMode(cfg.MaintenanceMode, cfg.MaintenanceStateFile)
+	handlers.SetMaintenance(maintenance)
+
+	if repo, ok := stockRepo.(domain.SchemaRepository); ok {
+		handlers.SetSchemaRepository(repo)
+	}
+	if repo, ok := stockRepo.(domain.WatchlistRepository); ok {
+		handlers.SetWatchlistRepository(repo)
+	}
+	if checker, ok := stockRepo.(domain.HealthChecker); ok {
+		handlers.SetHealthChecker(checker)
+	}
+	handlers.SetHealthCheckCacheTTL(time.Duration(cfg.HealthCheckCacheSeconds) * time.Second)
+	handlers.SetSnapshotStreaming(time.Duration(cfg.StreamIntervalSeconds)*time.Second, cfg.MaxSnapshotStreams)
+	if readiness != nil {
+		handlers.SetReadiness(readiness)
+	}
 
 	// Health check endpoint
 	router.GET("/health", handlers.HealthCheck)
+	router.GET("/health/live", handlers.LivenessCheck)
+	router.GET("/health/ready", handlers.ReadinessCheck)
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// API v1 routes
+	// API v1 routes. Admin routes are audited before maintenance mode or authentication
+	// can reject them, so refused attempts are recorded too.
 	v1 := router.Group("/api/v1")
+	v1.Use(Audit(auditor, adminActions), ReadOnly(maintenance))
 	{
 		// Stock ratings endpoints
 		v1.GET("/ratings", handlers.GetStockRatings)
+		v1.GET("/ratings/export", handlers.ExportStockRatings)
 		v1.GET("/ratings/:ticker", handlers.GetStockRatingsByTicker)
 
 		// Recommendations endpoint
+		v1.GET("/analytics/brokerages", handlers.GetBrokerageLeaderboard)
+		v1.GET("/analytics/rating-distribution", handlers.GetRatingDistribution)
 		v1.GET("/recommendations", handlers.GetRecommendations)
+		v1.GET("/recommendations/all", handlers.GetAllRecommendations)
+		v1.GET("/recommendations/stream", handlers.StreamRecommendations)
+		v1.POST("/recommendations/for", handlers.GetRecommendationsForTickers)
 
 		// Stock price data endpoints
+		v1.GET("/stocks/prices", handlers.GetBulkPrices)
 		v1.GET("/stocks/:symbol/price", handlers.GetStockPrice)
+		v1.GET("/stocks/:symbol/recent", handlers.GetRecentBars)
+		v1.GET("/stocks/default-logo", handlers.GetDefaultLogo)
 		v1.GET("/stocks/:symbol/logo", handlers.GetStockLogo)
+		v1.GET("/stocks/:symbol/snapshot", handlers.GetStockSnapshot)
+		v1.GET("/stocks/:symbol/stream", handlers.StreamStockSnapshots)
+
+		// Per-user watchlist endpoints, authenticated by API key
+		watchlist := v1.Group("/watchlist", UserAPIKeyAuth(cfg.UserAPIKeys))
+		watchlist.GET("", handlers.GetWatchlist)
+		watchlist.GET("/recommendations", handlers.GetWatchlistRecommendations)
+		watchlist.POST("/:ticker", handlers.AddToWatchlist)
+		watchlist.DELETE("/:ticker", handlers.RemoveFromWatchlist)
 
 		// Admin/utility endpoints
-		v1.POST("/ingest", handlers.TriggerIngestion)
+		v1.GET("/ingest/:jobID", handlers.GetIngestionJob)
+		v1.GET("/admin/maintenance", handlers.GetMaintenanceMode)
+		v1.GET("/admin/schema-version", handlers.GetSchemaVersion)
+
+		// Write endpoints require the shared API key
+		protected := v1.Group("", APIKeyAuth(cfg.APIKey))
+		protected.POST("/stocks/snapshots/warm", handlers.WarmSnapshots)
+		protected.POST("/ingest", handlers.TriggerIngestion)
+		protected.POST("/enrich", handlers.EnrichStocks)
+		protected.DELETE("/enriched", handlers.DeleteOldEnrichedData)
+		protected.POST("/recommendations/refresh", handlers.RefreshRecommendations)
+		protected.PUT("/admin/maintenance", handlers.SetMaintenanceMode)
 	}
 
 	return router
 }
+
+// adminActions names the audit action of every admin route, keyed by method and route path
+var adminActions = map[string]string{
+	"GET /api/v1/ingest/:jobID":            "ingest.status",
+	"GET /api/v1/admin/maintenance":        "maintenance.get",
+	"GET /api/v1/admin/schema-version":     "schema.version",
+	"POST /api/v1/stocks/snapshots/warm":   "snapshots.warm",
+	"POST /api/v1/ingest":                  "ingest.trigger",
+	"POST /api/v1/enrich":                  "enrich.trigger",
+	"DELETE /api/v1/enriched":              "enriched.delete",
+	"POST /api/v1/recommendations/refresh": "recommendations.refresh",
+	"PUT /api/v1/admin/maintenance":        "maintenance.set",
+}
+
+// snapshotStreamRoute is the WebSocket route pushing live snapshots
+const snapshotStreamRoute = "/api/v1/stocks/:symbol/stream"
+
+// recommendationStreamRoute is the Server-Sent Events route pushing refreshed recommendations
+const recommendationStreamRoute = "/api/v1/recommendations/stream"
+
+// ratingsExportRoute is the CSV export route, which streams every matching rating
+const ratingsExportRoute = "/api/v1/ratings/export"
+
+// withoutTimeout copies routeTimeouts with the timeout disabled for long-lived
+// routes, such as streams that stay open until the client leaves and exports
+// written to the client as they are read
+func withoutTimeout(routeTimeouts map[string]time.Duration, routes ...string) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(routeTimeouts)+len(routes))
+	maps.Copy(result, routeTimeouts)
+	for _, route := range routes {
+		result[route] = 0
+	}
+	return result
+}
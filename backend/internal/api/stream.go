@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// DefaultSnapshotStreamInterval is how often a snapshot stream polls for a fresh snapshot
+const DefaultSnapshotStreamInterval = 5 * time.Second
+
+// DefaultMaxSnapshotStreams bounds how many snapshot streams may be open at once
+const DefaultMaxSnapshotStreams = 50
+
+// streamWriteTimeout bounds each write to a stream so a stalled client cannot hold a slot
+const streamWriteTimeout = 10 * time.Second
+
+// snapshotStreams paces snapshot streams and caps how many run concurrently
+type snapshotStreams struct {
+	interval time.Duration
+	slots    chan struct{}
+}
+
+func newSnapshotStreams(interval time.Duration, maxStreams int) *snapshotStreams {
+	if interval <= 0 {
+		interval = DefaultSnapshotStreamInterval
+	}
+	if maxStreams <= 0 {
+		maxStreams = DefaultMaxSnapshotStreams
+	}
+	return &snapshotStreams{
+		interval: interval,
+		slots:    make(chan struct{}, maxStreams),
+	}
+}
+
+// acquire claims a stream slot without waiting, reporting false when all are taken
+func (s *snapshotStreams) acquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot claimed by acquire
+func (s *snapshotStreams) release() {
+	<-s.slots
+}
+
+// active reports how many streams are open
+func (s *snapshotStreams) active() int {
+	return len(s.slots)
+}
+
+// StreamStockSnapshots upgrades the request to a WebSocket and pushes the symbol's
+// snapshot as JSON every stream interval until the client disconnects or the market
+// closes. Snapshots go through the Alpaca service, so its rate limiter and snapshot
+// cache pace the upstream calls. Streams are refused while the market is closed or
+// when the concurrent stream limit is reached.
+func (h *Handlers) StreamStockSnapshots(c *gin.Context) {
+	symbol := strings.ToUpper(c.Param("symbol"))
+	if symbol == "" {
+		HandleError(c, apperrors.ErrValidationFailure.WithDetails("symbol parameter is required"))
+		return
+	}
+
+	if !h.alpacaSvc.IsMarketHours() {
+		HandleError(c, apperrors.ErrServiceUnavailable.WithDetails("snapshots are only streamed during market hours"))
+		return
+	}
+
+	if !h.streams.acquire() {
+		HandleError(c, apperrors.ErrServiceUnavailable.WithDetails("too many concurrent snapshot streams"))
+		return
+	}
+	defer h.streams.release()
+
+	upgrader := websocket.Upgrader{CheckOrigin: streamOriginAllowed(c)}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error to the client
+		slog.WarnContext(c.Request.Context(), "snapshot stream upgrade failed", "symbol", symbol, "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// Reading processes control frames and notices when the client goes away
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	h.pushSnapshots(ctx, conn, symbol)
+}
+
+// pushSnapshots writes snapshots to conn until ctx is cancelled, a write fails, or the
+// market closes, in which case the stream is closed with a normal closure
+func (h *Handlers) pushSnapshots(ctx context.Context, conn *websocket.Conn, symbol string) {
+	ticker := time.NewTicker(h.streams.interval)
+	defer ticker.Stop()
+
+	// The previous poll's snapshot is just under one interval old when the next tick
+	// fires, so only snapshots younger than half an interval are reused. Streams of the
+	// same symbol still share fetches without replaying a cached snapshot.
+	maxAge := h.streams.interval / 2
+
+	for {
+		if !h.alpacaSvc.IsMarketHours() {
+			message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "market closed")
+			conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(streamWriteTimeout))
+			return
+		}
+
+		snapshot, err := h.alpacaSvc.GetSnapshot(domain.WithMaxSnapshotAge(ctx, maxAge), symbol)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			// A failed poll is skipped; the next tick tries again
+			slog.WarnContext(ctx, "snapshot stream poll failed", "symbol", symbol, "error", err)
+		case snapshot != nil:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(snapshot); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamOriginAllowed accepts WebSocket handshakes without an Origin, from the
+// server's own origin, or from an origin the CORS middleware allowed
+func streamOriginAllowed(c *gin.Context) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		if allowed := c.Writer.Header().Get("Access-Control-Allow-Origin"); allowed == "*" || allowed == origin {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
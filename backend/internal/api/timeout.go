@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteTimeout middleware bounds how long a handler may run. The timeout is looked
+// up by route template in routeTimeouts, falling back to defaultTimeout; a zero
+// timeout disables the limit. Handlers receive a request context carrying the
+// deadline, and clients get a 504 as soon as it passes even if the handler ignores it.
+func RouteTimeout(routeTimeouts map[string]time.Duration, defaultTimeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, exists := routeTimeouts[c.FullPath()]
+		if !exists {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		// Read before the handler runs, since it owns the context until it returns
+		requestID := c.GetString(requestIDContextKey)
+		asProblem := acceptsProblemJSON(c)
+		instance := c.Request.URL.Path
+		original := c.Writer
+		buffered := newTimeoutWriter(original)
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = buffered
+
+		done := make(chan struct{})
+		var recovered interface{}
+		go func() {
+			defer close(done)
+			defer func() {
+				recovered = recover()
+			}()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+			c.Writer = original
+			if recovered != nil {
+				// Re-panic on the request goroutine so ErrorHandler can recover it
+				panic(recovered)
+			}
+			buffered.flushTo(original)
+		case <-ctx.Done():
+			writeTimeoutResponse(original, requestID, asProblem, instance)
+
+			// The handler still owns the context until it returns; wait so the
+			// context is not recycled underneath it. Its output is discarded.
+			<-done
+			c.Writer = original
+			c.Abort()
+		}
+	}
+}
+
+// clearWriteDeadline lifts the server's WriteTimeout for a response that is written
+// for as long as the handler runs. Writers that cannot change the deadline, such as
+// test recorders, are left as they are.
+func clearWriteDeadline(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// writeTimeoutResponse writes the 504 error body directly to the client, as
+// problem details when asProblem is set
+func writeTimeoutResponse(w gin.ResponseWriter, requestID string, asProblem bool, instance string) {
+	status := apperrors.ErrRequestTimeout.HTTPStatus()
+	resp := ErrorResponse{
+		Error:     apperrors.ErrRequestTimeout.Message,
+		Code:      apperrors.ErrRequestTimeout.Code,
+		RequestID: requestID,
+	}
+
+	contentType := "application/json; charset=utf-8"
+	body, _ := json.Marshal(resp)
+	if asProblem {
+		contentType = ProblemContentType
+		body, _ = json.Marshal(newProblemDetails(status, resp, instance))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+	w.Flush()
+}
+
+// timeoutWriter buffers a handler's response so it can be dropped if the handler times out
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.wroteHeader = true
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	if !w.wroteHeader {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.wroteHeader
+}
+
+func (w *timeoutWriter) Flush() {}
+
+// flushTo copies the buffered headers, status, and body to the real writer
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	for key, values := range w.header {
+		dst.Header()[key] = values
+	}
+
+	dst.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		dst.Write(w.body.Bytes())
+	} else {
+		dst.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"stock-analyzer/internal/domain"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTickerLength matches the width of the ticker columns
+const maxTickerLength = 10
+
+// GetWatchlist returns the authenticated user's watched tickers with their latest ratings
+func (h *Handlers) GetWatchlist(c *gin.Context) {
+	if h.watchlistRepo == nil {
+		HandleError(c, apperrors.ErrNotImplemented.WithDetails("watchlists are not supported by this deployment"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	tickers, err := h.watchlistRepo.GetWatchlist(ctx, c.GetString(principalContextKey))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	items := make([]domain.WatchlistItem, 0, len(tickers))
+	if len(tickers) > 0 {
+		latest, err := h.stockRepo.GetLatestRatingsByTicker(ctx)
+		if err != nil {
+			HandleError(c, err)
+			return
+		}
+
+		for _, ticker := range tickers {
+			item := domain.WatchlistItem{Ticker: ticker}
+			if rating, ok := latest[ticker]; ok {
+				rounded := roundRatings([]domain.StockRating{*rating}, h.cfg.JSONDecimalPlaces)[0]
+				item.LatestRating = &rounded
+			}
+			items = append(items, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  items,
+		"count": len(items),
+	})
+}
+
+// GetWatchlistRecommendations scores only the tickers on the authenticated user's watchlist
+func (h *Handlers) GetWatchlistRecommendations(c *gin.Context) {
+	if h.watchlistRepo == nil {
+		HandleError(c, apperrors.ErrNotImplemented.WithDetails("watchlists are not supported by this deployment"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	tickers, err := h.watchlistRepo.GetWatchlist(ctx, c.GetString(principalContextKey))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	recommendations := []domain.StockRecommendation{}
+	if len(tickers) > 0 {
+		recommendations, err = h.recommendationSvc.GenerateRecommendationsForTickers(ctx, tickers)
+		if err != nil {
+			HandleError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recommendations": roundRecommendations(recommendations, h.cfg.JSONDecimalPlaces),
+		"count":           len(recommendations),
+	})
+}
+
+// AddToWatchlist adds a ticker to the authenticated user's watchlist
+func (h *Handlers) AddToWatchlist(c *gin.Context) {
+	if h.watchlistRepo == nil {
+		HandleError(c, apperrors.ErrNotImplemented.WithDetails("watchlists are not supported by this deployment"))
+		return
+	}
+
+	ticker, err := parseWatchlistTicker(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.watchlistRepo.AddToWatchlist(c.Request.Context(), c.GetString(principalContextKey), ticker); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticker added to watchlist",
+		"ticker":  ticker,
+	})
+}
+
+// RemoveFromWatchlist removes a ticker from the authenticated user's watchlist
+func (h *Handlers) RemoveFromWatchlist(c *gin.Context) {
+	if h.watchlistRepo == nil {
+		HandleError(c, apperrors.ErrNotImplemented.WithDetails("watchlists are not supported by this deployment"))
+		return
+	}
+
+	ticker, err := parseWatchlistTicker(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.watchlistRepo.RemoveFromWatchlist(c.Request.Context(), c.GetString(principalContextKey), ticker); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseWatchlistTicker reads and uppercases the ticker path parameter
+func parseWatchlistTicker(c *gin.Context) (string, error) {
+	ticker := strings.ToUpper(strings.TrimSpace(c.Param("ticker")))
+	if ticker == "" || len(ticker) > maxTickerLength {
+		return "", apperrors.ErrValidationFailure.WithDetails(fmt.Sprintf("ticker must be 1-%d characters", maxTickerLength))
+	}
+
+	return ticker, nil
+}
@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // StockRepository defines the contract for stock data persistence.
@@ -10,18 +12,40 @@ type StockRepository interface {
 	// CreateStockRating stores a single stock rating in the database.
 	CreateStockRating(ctx context.Context, rating *StockRating) error
 
+	// UpsertStockRating stores a stock rating, updating the existing row when the
+	// same ticker, brokerage, rating and time were already ingested.
+	UpsertStockRating(ctx context.Context, rating *StockRating) error
+
 	// CreateStockRatingsBatch efficiently stores multiple stock ratings in a single transaction.
 	CreateStockRatingsBatch(ctx context.Context, ratings []*StockRating) (int, error)
 
+	// CreateStockRatingsBatchReturningIDs stores ratings like CreateStockRatingsBatch but
+	// returns the IDs of the newly inserted ratings. Skipped duplicates and ratings that
+	// overwrote a stored row are not included.
+	CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*StockRating) ([]uuid.UUID, error)
+
 	// GetStockRatings retrieves paginated stock ratings with optional filtering and sorting.
 	GetStockRatings(ctx context.Context, filters FilterOptions) (*PaginatedResponse[StockRating], error)
 
+	// StreamStockRatings calls fn for each rating matching the filters' search and sort,
+	// ignoring pagination. Iteration stops at the first error returned by fn.
+	StreamStockRatings(ctx context.Context, filters FilterOptions, fn func(StockRating) error) error
+
 	// GetStockRatingsByTicker retrieves all ratings for a specific stock ticker.
 	GetStockRatingsByTicker(ctx context.Context, ticker string) ([]StockRating, error)
 
+	// GetRatingsByDateRange retrieves every rating with a time in [from, to), oldest first.
+	GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]StockRating, error)
+
 	// GetUniqueTickers retrieves all unique stock tickers that have ratings.
 	GetUniqueTickers(ctx context.Context) ([]string, error)
 
+	// GetRatingsCountByBrokerage returns how many ratings each brokerage has issued.
+	GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error)
+
+	// GetRatingDistribution returns how many ratings exist for each rating_to value.
+	GetRatingDistribution(ctx context.Context) (map[string]int, error)
+
 	// CreateEnrichedStockData stores additional analysis data for a stock.
 	CreateEnrichedStockData(ctx context.Context, data *EnrichedStockData) error
 
@@ -35,10 +59,46 @@ type StockRepository interface {
 	DeleteOldEnrichedData(ctx context.Context, olderThan time.Time) (int64, error)
 }
 
+// AuditRepository defines the contract for persisting admin audit entries.
+type AuditRepository interface {
+	// CreateAuditEntry stores a single audit entry.
+	CreateAuditEntry(ctx context.Context, entry *AuditEntry) error
+}
+
+// WatchlistRepository persists the tickers each user is watching.
+type WatchlistRepository interface {
+	// AddToWatchlist adds a ticker to a user's watchlist; adding a watched ticker is a no-op.
+	AddToWatchlist(ctx context.Context, userID, ticker string) error
+
+	// RemoveFromWatchlist removes a ticker from a user's watchlist, returning
+	// ErrNotFound if the user was not watching it.
+	RemoveFromWatchlist(ctx context.Context, userID, ticker string) error
+
+	// GetWatchlist returns a user's watched tickers in alphabetical order.
+	GetWatchlist(ctx context.Context, userID string) ([]string, error)
+}
+
+// SchemaRepository exposes the applied database schema version.
+type SchemaRepository interface {
+	// GetSchemaVersion returns the highest applied migration version, or 0 if none is recorded.
+	GetSchemaVersion(ctx context.Context) (int, error)
+}
+
+// HealthChecker reports whether a backing store is reachable.
+type HealthChecker interface {
+	// Ping verifies the connection, returning an error when the store is unreachable.
+	Ping(ctx context.Context) error
+}
+
 // IngestionService defines the contract for data ingestion from external APIs.
 type IngestionService interface {
-	// IngestAllData performs a complete data ingestion cycle.
-	IngestAllData(ctx context.Context) error
+	// IngestAllData performs a complete data ingestion cycle and reports how many
+	// ratings were fetched, stored, and skipped as duplicates.
+	IngestAllData(ctx context.Context) (*IngestionResult, error)
+
+	// EnrichStockData fetches additional analysis data for the given tickers and
+	// reports how many were refreshed; tickers with fresh data are skipped.
+	EnrichStockData(ctx context.Context, tickers []string) (int, error)
 }
 
 // RecommendationService defines the contract for generating stock recommendations.
@@ -46,8 +106,26 @@ type RecommendationService interface {
 	// GenerateRecommendations analyzes all available data and generates fresh stock recommendations.
 	GenerateRecommendations(ctx context.Context) ([]StockRecommendation, error)
 
+	// GenerateAllRecommendations scores every candidate like GenerateRecommendations
+	// but returns the complete ranked list instead of only the top recommendations.
+	GenerateAllRecommendations(ctx context.Context) ([]StockRecommendation, error)
+
+	// GenerateRecommendationsForTickers scores only the given tickers, such as a watchlist.
+	GenerateRecommendationsForTickers(ctx context.Context, tickers []string) ([]StockRecommendation, error)
+
 	// GetCachedRecommendations retrieves the latest generated recommendations from cache.
 	GetCachedRecommendations(ctx context.Context) ([]StockRecommendation, error)
+
+	// GetCachedAllRecommendations retrieves the complete ranked list behind the cached
+	// recommendations, so paginated reads share one generation and one ordering.
+	GetCachedAllRecommendations(ctx context.Context) ([]StockRecommendation, error)
+
+	// RefreshRecommendations regenerates recommendations and replaces the cached set.
+	RefreshRecommendations(ctx context.Context) ([]StockRecommendation, error)
+
+	// Subscribe delivers the recommendation set of every cache refresh until the
+	// returned unsubscribe func is called.
+	Subscribe() (<-chan []StockRecommendation, func())
 }
 
 // PriceBar represents a single price bar/candle from market data.
@@ -70,6 +148,26 @@ type Snapshot struct {
 	PrevDailyBar *PriceBar `json:"prev_daily_bar,omitempty"` // Previous day's bar
 }
 
+// CurrentPrice returns the most recent price in the snapshot, preferring the latest
+// trade, then the minute bar close, then the daily bar close. It reports false when
+// none of them are present, e.g. for symbols that have not traded.
+func (s *Snapshot) CurrentPrice() (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+
+	switch {
+	case s.LatestTrade != nil:
+		return s.LatestTrade.Price, true
+	case s.MinuteBar != nil:
+		return s.MinuteBar.Close, true
+	case s.DailyBar != nil:
+		return s.DailyBar.Close, true
+	default:
+		return 0, false
+	}
+}
+
 // Trade represents a single trade execution.
 type Trade struct {
 	Timestamp string  `json:"timestamp"` // ISO 8601 timestamp of the trade
@@ -91,9 +189,14 @@ type AlpacaService interface {
 	// GetHistoricalBars fetches historical price data for technical analysis.
 	GetHistoricalBars(ctx context.Context, symbol string, timeframe string, start, end time.Time) ([]PriceBar, error)
 
-	// GetSnapshot fetches current market snapshot for real-time data.
+	// GetSnapshot fetches current market snapshot for real-time data. Cached
+	// snapshots older than the context's WithMaxSnapshotAge bound are refetched.
 	GetSnapshot(ctx context.Context, symbol string) (*Snapshot, error)
 
+	// GetSnapshots fetches current market snapshots for several symbols at once.
+	// Symbols without snapshot data are omitted from the result.
+	GetSnapshots(ctx context.Context, symbols []string) (map[string]*Snapshot, error)
+
 	// GetRecentBars fetches the most recent bars for a symbol.
 	GetRecentBars(ctx context.Context, symbol string) ([]PriceBar, error)
 
@@ -101,11 +204,44 @@ type AlpacaService interface {
 	IsMarketHours() bool
 }
 
+// maxSnapshotAgeKey is the context key for the snapshot age bound
+type maxSnapshotAgeKey struct{}
+
+// WithMaxSnapshotAge returns a context asking GetSnapshot not to serve cached
+// snapshots older than maxAge, e.g. so a stream polling every few seconds
+// does not replay one cached snapshot until the cache expires
+func WithMaxSnapshotAge(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, maxSnapshotAgeKey{}, maxAge)
+}
+
+// MaxSnapshotAge returns the snapshot age bound stored in ctx, if any
+func MaxSnapshotAge(ctx context.Context) (time.Duration, bool) {
+	maxAge, ok := ctx.Value(maxSnapshotAgeKey{}).(time.Duration)
+	return maxAge, ok
+}
+
+// SortOrder is a validated sort direction for data queries.
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// SQL returns the ORDER BY keyword for the direction. Anything other than
+// SortAscending, including the zero value, sorts newest first.
+func (o SortOrder) SQL() string {
+	if o == SortAscending {
+		return "ASC"
+	}
+	return "DESC"
+}
+
 // FilterOptions defines filtering and pagination options for data queries.
 type FilterOptions struct {
-	Page     int    `json:"page"`      // Page number (1-based)
-	Limit    int    `json:"limit"`     // Items per page
-	Search   string `json:"search"`    // Search term for full-text search
-	SortBy   string `json:"sort_by"`   // Field to sort by
-	SortDesc bool   `json:"sort_desc"` // Sort direction
+	Page   int       `json:"page"`    // Page number (1-based)
+	Limit  int       `json:"limit"`   // Items per page
+	Search string    `json:"search"`  // Search term for full-text search
+	SortBy string    `json:"sort_by"` // Field to sort by
+	Order  SortOrder `json:"order"`   // Sort direction, validated by the caller
 }
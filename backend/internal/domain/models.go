@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +22,13 @@ type StockRating struct {
 	TargetTo   *float64  `json:"target_to" db:"target_to"`     // New price target (nullable)
 	Time       time.Time `json:"time" db:"time"`               // When the rating was issued
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`   // When this record was created
+	Source     string    `json:"-" db:"source"`                // Ingestion source, used for conflict resolution on write
+}
+
+// ConflictKey identifies a rating under the stock_ratings unique index on
+// (ticker, brokerage, rating_to, time). Ratings sharing a key are stored as one row.
+func (r *StockRating) ConflictKey() string {
+	return fmt.Sprintf("%s|%s|%s|%d", r.Ticker, r.Brokerage, r.RatingTo, r.Time.UnixNano())
 }
 
 // EnrichedStockData represents additional data for recommendation analysis.
@@ -48,6 +56,7 @@ type StockRecommendation struct {
 	Ticker          string    `json:"ticker"`           // Stock symbol
 	Company         string    `json:"company"`          // Full company name
 	Score           float64   `json:"score"`            // Recommendation score (0.0-1.0)
+	NormalizedScore *float64  `json:"normalized_score"` // Score min-max scaled to 0-1 across the generated set (nullable)
 	Rationale       string    `json:"rationale"`        // Human-readable explanation
 	LatestRating    string    `json:"latest_rating"`    // Most recent analyst rating
 	TargetPrice     *float64  `json:"target_price"`     // Analyst price target (nullable)
@@ -64,14 +73,24 @@ type PaginatedResponse[T any] struct {
 	Pagination Pagination `json:"pagination"` // Pagination metadata
 }
 
+// NewPaginatedResponse builds a paginated response whose data is never nil, so
+// an empty page serializes as [] rather than null.
+func NewPaginatedResponse[T any](data []T, pagination Pagination) *PaginatedResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return &PaginatedResponse[T]{Data: data, Pagination: pagination}
+}
+
 // Pagination represents pagination metadata.
 // Used consistently across all paginated endpoints to provide
 // navigation information to clients.
 type Pagination struct {
-	Page       int `json:"page"`        // Current page number (1-based)
-	Limit      int `json:"limit"`       // Items per page
-	TotalItems int `json:"total_items"` // Total number of items across all pages
-	TotalPages int `json:"total_pages"` // Total number of pages
+	Page       int    `json:"page"`            // Current page number (1-based)
+	Limit      int    `json:"limit"`           // Items per page
+	TotalItems int    `json:"total_items"`     // Total number of items across all pages
+	TotalPages int    `json:"total_pages"`     // Total number of pages
+	Order      string `json:"order,omitempty"` // Applied sort direction ("asc" or "desc")
 }
 
 // APIResponse represents the external API response format.
@@ -99,3 +118,35 @@ type APIStockRating struct {
 	TargetTo   string `json:"target_to"`   // New target as string
 	Time       string `json:"time"`        // Rating time as ISO string
 }
+
+// BrokerageCount is the number of ratings issued by a single brokerage.
+type BrokerageCount struct {
+	Brokerage string `json:"brokerage"`
+	Count     int    `json:"count"`
+}
+
+// IngestionResult summarizes a completed ingestion run.
+type IngestionResult struct {
+	TotalFetched      int            `json:"total_fetched"`           // Ratings received from the upstream API across all pages
+	Inserted          int            `json:"inserted"`                // Ratings newly stored across all pages
+	SkippedDuplicates int            `json:"skipped_duplicates"`      // Fetched ratings that repeated within a page or were already stored
+	NewByTicker       map[string]int `json:"new_by_ticker,omitempty"` // New ratings per ticker; only populated in diff mode
+	AutoEnriched      []string       `json:"auto_enriched,omitempty"` // Never-enriched tickers with new ratings that were enriched after the run
+}
+
+// WatchlistItem is a watched ticker with its most recent analyst rating.
+type WatchlistItem struct {
+	Ticker       string       `json:"ticker"`        // Watched stock symbol
+	LatestRating *StockRating `json:"latest_rating"` // Most recent rating, or null if none is stored
+}
+
+// AuditEntry records an administrative action performed through the API.
+// Entries are always logged and optionally persisted to the audit_log table.
+type AuditEntry struct {
+	Actor     string    `json:"actor" db:"actor"`           // Authenticated principal, or "anonymous"
+	Action    string    `json:"action" db:"action"`         // Action name, e.g. "ingest.trigger"
+	Target    string    `json:"target" db:"target"`         // Resource the action applied to
+	Status    int       `json:"status" db:"status"`         // HTTP status code of the response
+	RequestID string    `json:"request_id" db:"request_id"` // Correlation ID of the request
+	Timestamp time.Time `json:"timestamp" db:"created_at"`  // When the action was performed
+}
@@ -0,0 +1,91 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"stock-analyzer/internal/domain"
+	apperrors "stock-analyzer/pkg/errors"
+)
+
+// fileSourcePrefix marks an API URL that names a local JSON file instead of an HTTP endpoint
+const fileSourcePrefix = "file://"
+
+// fetchPageFunc fetches the page identified by nextPage, or the first page when it is nil
+type fetchPageFunc func(ctx context.Context, nextPage *string) (*domain.APIResponse, http.Header, error)
+
+// pageFetcher returns how one ingestion run fetches pages: over HTTP, or from the
+// local JSON file named by a file:// API URL, which is read once per run
+func (s *Service) pageFetcher() (fetchPageFunc, error) {
+	path, isFile := strings.CutPrefix(s.apiURL, fileSourcePrefix)
+	if !isFile {
+		return s.fetchPage, nil
+	}
+
+	pages, err := readFilePages(path)
+	if err != nil {
+		return nil, err
+	}
+	return pages.fetch, nil
+}
+
+// filePages serves the pages of a local ratings file. Pages are chained in file
+// order with their index as the next_page token, whatever tokens the file holds.
+type filePages []domain.APIResponse
+
+// readFilePages reads a ratings file holding either one APIResponse object or an
+// array of them, one per page
+func readFilePages(path string) (filePages, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to read ratings file")
+	}
+
+	var pages filePages
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &pages)
+	} else {
+		pages = make(filePages, 1)
+		err = json.Unmarshal(data, &pages[0])
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, fmt.Sprintf("failed to parse ratings file %s", path))
+	}
+
+	return pages, nil
+}
+
+// fetch returns the page at the index named by nextPage, linking it to the next one
+func (p filePages) fetch(ctx context.Context, nextPage *string) (*domain.APIResponse, http.Header, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	index := 0
+	if nextPage != nil {
+		var err error
+		index, err = strconv.Atoi(*nextPage)
+		if err != nil || index < 1 || index >= len(p) {
+			return nil, nil, apperrors.New(apperrors.ErrCodeUpstreamAPI,
+				fmt.Sprintf("ratings file has no page %q", *nextPage))
+		}
+	}
+
+	if len(p) == 0 {
+		return &domain.APIResponse{}, nil, nil
+	}
+
+	page := p[index]
+	page.NextPage = nil
+	if index+1 < len(p) {
+		next := strconv.Itoa(index + 1)
+		page.NextPage = &next
+	}
+	return &page, nil, nil
+}
@@ -0,0 +1,101 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stock-analyzer/internal/domain"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRatingsFile marshals v into a temporary file and returns its file:// URL
+func writeRatingsFile(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return fileSourcePrefix + path
+}
+
+func TestIngestAllData_FileSourceMultiplePages(t *testing.T) {
+	t.Log("Testing IngestAllData: a file:// source ingests every page of a JSON array")
+	stockRepo := &MockStockRepository{}
+
+	// Tokens in the file are ignored; pages are chained in file order
+	pages := []*domain.APIResponse{
+		createMockAPIResponse(createMockAPIItems(3), stringPtr("ignored")),
+		createMockAPIResponse(nil, nil),
+		createMockAPIResponse(createMockAPIItemsFrom(3, 2), nil),
+	}
+	service := NewService(stockRepo, writeRatingsFile(t, pages), "")
+
+	var tickers []string
+	recordTickers := func(args mock.Arguments) {
+		for _, rating := range args.Get(1).([]*domain.StockRating) {
+			tickers = append(tickers, rating.Ticker)
+		}
+	}
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 3
+	})).Run(recordTickers).Return(3, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Run(recordTickers).Return(2, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.TotalFetched)
+	assert.Equal(t, 5, result.Inserted)
+	assert.ElementsMatch(t, []string{"TICK0", "TICK1", "TICK2", "TICK3", "TICK4"}, tickers)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_FileSourceSinglePage(t *testing.T) {
+	t.Log("Testing IngestAllData: a file:// source also accepts a single APIResponse object")
+	stockRepo := &MockStockRepository{}
+
+	page := createMockAPIResponse(createMockAPIItems(2), stringPtr("page2"))
+	service := NewService(stockRepo, writeRatingsFile(t, page), "")
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Return(2, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Inserted)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_FileSourceErrors(t *testing.T) {
+	t.Log("Testing IngestAllData: a missing or malformed ratings file fails with an upstream error")
+	malformed := filepath.Join(t.TempDir(), "ratings.json")
+	require.NoError(t, os.WriteFile(malformed, []byte(`[{"items": "nope"}]`), 0o600))
+
+	tests := map[string]string{
+		"missing":   fileSourcePrefix + filepath.Join(t.TempDir(), "missing.json"),
+		"malformed": fileSourcePrefix + malformed,
+	}
+
+	for name, url := range tests {
+		stockRepo := &MockStockRepository{}
+		service := NewService(stockRepo, url, "")
+
+		result, err := service.IngestAllData(context.Background())
+
+		assert.Nil(t, result, name)
+		assert.ErrorIs(t, err, apperrors.ErrUpstreamAPIFailure, name)
+		stockRepo.AssertNotCalled(t, "CreateStockRatingsBatch")
+	}
+}
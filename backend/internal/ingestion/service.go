@@ -3,60 +3,296 @@ package ingestion
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
 	apperrors "stock-analyzer/pkg/errors"
 
 	"github.com/google/uuid"
 )
 
+// defaultHTTPTimeout bounds each upstream request when no client is supplied
+const defaultHTTPTimeout = 30 * time.Second
+
+// defaultEnrichmentFreshness is how long enriched data is considered fresh
+const defaultEnrichmentFreshness = 24 * time.Hour
+
+// maxConsecutiveEmptyPages bounds how many empty pages in a row are followed
+// before ingestion stops, guarding against upstreams that never end pagination
+const maxConsecutiveEmptyPages = 20
+
+// lowRateLimitRemaining is the X-RateLimit-Remaining value at or below which
+// paging slows down to lowRateLimitDelay between requests
+const lowRateLimitRemaining = 5
+
+// lowRateLimitDelay is the minimum pause between pages when the upstream
+// reports few remaining requests
+const lowRateLimitDelay = time.Second
+
+// maxPacingDelay caps any pause requested by upstream rate-limit headers
+const maxPacingDelay = time.Minute
+
+// maxSeenRatingKeys bounds how many rating keys one run remembers to drop
+// duplicates across pages; repeats beyond it are left to the unique index
+const maxSeenRatingKeys = 100_000
+
+// DefaultMaxPageTokenLength caps the next_page token sent back upstream so a
+// broken or hostile upstream cannot grow the request URL without bound
+const DefaultMaxPageTokenLength = 2048
+
+// DefaultSource names the upstream ratings API when no source name is configured
+const DefaultSource = "stock_api"
+
+// defaultMaxRetries is how many times a failed upstream request is retried
+const defaultMaxRetries = 3
+
+// defaultRetryBackoffBase is the backoff ceiling before the first retry; it
+// doubles with each further retry up to maxRetryBackoff
+const defaultRetryBackoffBase = time.Second
+
+// maxRetryBackoff caps the backoff ceiling for any single retry
+const maxRetryBackoff = 30 * time.Second
+
 // Service implements the IngestionService interface
 type Service struct {
-	stockRepo domain.StockRepository
-	apiURL    string
-	apiToken  string
-	client    *http.Client
+	stockRepo           domain.StockRepository
+	apiURL              string
+	apiToken            string
+	client              *http.Client
+	enrichmentFreshness time.Duration
+	pageDelay           time.Duration
+	wait                func(ctx context.Context, d time.Duration) error
+	jitter              func(ceiling time.Duration) time.Duration
+	fetchEnrichment     func(ctx context.Context, ticker string) (*domain.EnrichedStockData, error)
+	maxRetries          int
+	retryBackoffBase    time.Duration
+	timeBudget          time.Duration
+	batchSize           int
+	maxPageTokenLength  int
+	autoEnrichWorkers   int
+	source              string
+	clock               clock.Clock
+	logger              *slog.Logger
 }
 
-// NewService creates a new ingestion service
+// IngestOptions controls how IngestAllDataWithOptions stores fetched ratings
+type IngestOptions struct {
+	// Diff reports how many genuinely new ratings each ticker received. Each
+	// ticker's ratings are stored as their own batch so the counts are exact.
+	Diff bool
+}
+
+// EnrichOptions controls how EnrichStockDataWithOptions treats existing data
+type EnrichOptions struct {
+	// Force refreshes every ticker, even when its enriched data is still fresh
+	Force bool
+}
+
+// NewService creates a new ingestion service using an HTTP client with the default timeout
 func NewService(stockRepo domain.StockRepository, apiURL, apiToken string) *Service {
+	return NewServiceWithClient(stockRepo, apiURL, apiToken, nil)
+}
+
+// NewServiceWithClient creates a new ingestion service that sends upstream requests
+// through client, allowing custom transports, proxies, or TLS settings. A nil client
+// falls back to one with the default timeout.
+func NewServiceWithClient(stockRepo domain.StockRepository, apiURL, apiToken string, client *http.Client) *Service {
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
 	return &Service{
-		stockRepo: stockRepo,
-		apiURL:    apiURL,
-		apiToken:  apiToken,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		stockRepo:           stockRepo,
+		apiURL:              apiURL,
+		apiToken:            apiToken,
+		client:              client,
+		enrichmentFreshness: defaultEnrichmentFreshness,
+		wait:                sleepContext,
+		jitter:              fullJitter,
+		fetchEnrichment:     noEnrichmentSource,
+		maxRetries:          defaultMaxRetries,
+		retryBackoffBase:    defaultRetryBackoffBase,
+		maxPageTokenLength:  DefaultMaxPageTokenLength,
+		source:              DefaultSource,
+		clock:               clock.Real{},
+		logger:              slog.Default(),
 	}
 }
 
+// SetLogger replaces the logger used by the service
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetEnrichmentFreshness sets how long enriched data is reused before it is refreshed
+func (s *Service) SetEnrichmentFreshness(window time.Duration) {
+	s.enrichmentFreshness = window
+}
+
+// SetPageDelay sets the minimum pause between page requests during ingestion
+func (s *Service) SetPageDelay(delay time.Duration) {
+	s.pageDelay = delay
+}
+
+// SetRetryPolicy sets how many times failed upstream requests are retried and
+// the backoff ceiling before the first retry. Negative values are ignored.
+func (s *Service) SetRetryPolicy(maxRetries int, backoffBase time.Duration) {
+	if maxRetries >= 0 {
+		s.maxRetries = maxRetries
+	}
+	if backoffBase >= 0 {
+		s.retryBackoffBase = backoffBase
+	}
+}
+
+// SetTimeBudget bounds how long one ingestion run may spend fetching pages. Once
+// the budget is spent, paging stops and the partial result is returned with an
+// ErrCodeTimeout error. Zero or a negative budget disables the limit.
+func (s *Service) SetTimeBudget(budget time.Duration) {
+	s.timeBudget = max(budget, 0)
+}
+
+// SetBatchSize caps how many ratings are stored per repository call, splitting
+// larger pages into sub-batches so the upstream page size doesn't dictate the
+// database transaction size. Zero or a negative size stores each page whole.
+func (s *Service) SetBatchSize(size int) {
+	s.batchSize = max(size, 0)
+}
+
+// SetMaxPageTokenLength caps the length of next_page tokens accepted from the
+// upstream. A longer token aborts ingestion with an ErrCodeUpstreamAPI error.
+// Zero or a negative length restores DefaultMaxPageTokenLength.
+func (s *Service) SetMaxPageTokenLength(length int) {
+	if length <= 0 {
+		length = DefaultMaxPageTokenLength
+	}
+	s.maxPageTokenLength = length
+}
+
+// SetAutoEnrichNewTickers makes each ingestion run enrich the tickers that received
+// new ratings but have never been enriched, using up to workers concurrent
+// enrichments. Passing false or fewer than one worker disables it.
+func (s *Service) SetAutoEnrichNewTickers(enabled bool, workers int) {
+	s.autoEnrichWorkers = 0
+	if enabled {
+		s.autoEnrichWorkers = max(workers, 0)
+	}
+}
+
+// SetSource sets the source name stamped on ingested ratings, which the
+// repository uses to resolve conflicts between sources by priority
+func (s *Service) SetSource(name string) {
+	if name != "" {
+		s.source = name
+	}
+}
+
+// SetClock replaces the clock used for record timestamps and enrichment freshness
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // IngestAllData fetches and stores all data from the external API
-func (s *Service) IngestAllData(ctx context.Context) error {
+func (s *Service) IngestAllData(ctx context.Context) (*domain.IngestionResult, error) {
+	return s.IngestAllDataWithOptions(ctx, IngestOptions{})
+}
+
+// IngestAllDataWithOptions fetches and stores all data from the external API and
+// reports what was stored. If the time budget runs out, the ratings stored so far
+// are returned along with the error.
+func (s *Service) IngestAllDataWithOptions(ctx context.Context, opts IngestOptions) (*domain.IngestionResult, error) {
+	fetch, err := s.pageFetcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data from API: %w", err)
+	}
+
 	var nextPage *string
-	totalIngested := 0
+	result := &domain.IngestionResult{}
+	if opts.Diff {
+		result.NewByTicker = make(map[string]int)
+	}
+	emptyPages := 0
+	seenPages := make(map[string]bool)
+	var pacing time.Duration
+
+	// The time budget only bounds fetching and pacing, so a fetched page is always stored
+	fetchCtx := ctx
+	if s.timeBudget > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, s.timeBudget)
+		defer cancel()
+	}
+	pagesFetched := 0
+	newTickers := make(map[string]bool)
+	seenRatings := make(map[string]bool)
 
 	for {
+		if nextPage != nil {
+			if err := s.wait(fetchCtx, pacing); err != nil {
+				if timeBudgetExhausted(ctx, fetchCtx) {
+					return result, s.timeBudgetError(result, pagesFetched)
+				}
+				return nil, err
+			}
+		}
+
 		// Fetch data from API
-		apiResponse, err := s.fetchDataFromAPI(ctx, nextPage)
+		apiResponse, headers, err := fetch(fetchCtx, nextPage)
 		if err != nil {
-			return fmt.Errorf("failed to fetch data from API: %w", err)
+			if timeBudgetExhausted(ctx, fetchCtx) {
+				return result, s.timeBudgetError(result, pagesFetched)
+			}
+			return nil, fmt.Errorf("failed to fetch data from API: %w", err)
+		}
+		pagesFetched++
+		pacing = s.pacingDelay(headers)
+
+		// Only a missing next page ends pagination; sparse pages may be empty
+		hasNextPage := apiResponse.NextPage != nil && *apiResponse.NextPage != ""
+		if hasNextPage {
+			if seenPages[*apiResponse.NextPage] {
+				return nil, apperrors.New(apperrors.ErrCodeUpstreamAPI,
+					fmt.Sprintf("pagination loop detected: next_page %q was already fetched", *apiResponse.NextPage))
+			}
+			seenPages[*apiResponse.NextPage] = true
 		}
 
 		if len(apiResponse.Items) == 0 {
-			break
+			if !hasNextPage {
+				break
+			}
+
+			emptyPages++
+			if emptyPages >= maxConsecutiveEmptyPages {
+				s.logger.Warn("stopping ingestion after consecutive empty pages", "empty_pages", emptyPages)
+				break
+			}
+
+			nextPage = apiResponse.NextPage
+			continue
 		}
+		emptyPages = 0
 
 		// Transform API response to domain models
 		ratings, err := s.transformAPIRatings(apiResponse.Items)
 		if err != nil {
-			return fmt.Errorf("failed to transform API ratings: %w", err)
+			return nil, fmt.Errorf("failed to transform API ratings: %w", err)
 		}
+		ratings = dropSeenRatings(ratings, seenRatings, maxSeenRatingKeys)
 
 		// Convert to pointers for the repository call
 		ratingPointers := make([]*domain.StockRating, len(ratings))
@@ -65,31 +301,207 @@ func (s *Service) IngestAllData(ctx context.Context) error {
 		}
 
 		// Store ratings in batches
-		insertedCount, err := s.stockRepo.CreateStockRatingsBatch(ctx, ratingPointers)
+		insertedCount, err := s.storeRatings(ctx, ratingPointers, opts, result.NewByTicker, newTickers)
 		if err != nil {
-			return fmt.Errorf("failed to store ratings batch: %w", err)
+			return nil, fmt.Errorf("failed to store ratings batch: %w", err)
 		}
 
-		totalIngested += insertedCount
-		fmt.Printf("Ingested batch of %d ratings (total: %d)\n", insertedCount, totalIngested)
+		// Anything fetched but not inserted was a duplicate, either within the page or of a stored rating
+		result.TotalFetched += len(apiResponse.Items)
+		result.Inserted += insertedCount
+		result.SkippedDuplicates += len(apiResponse.Items) - insertedCount
+		s.logger.Info("ingested ratings batch", "inserted", insertedCount, "total", result.Inserted)
 
 		// Check if there's more data
-		if apiResponse.NextPage == nil || *apiResponse.NextPage == "" {
+		if !hasNextPage {
 			break
 		}
 
 		nextPage = apiResponse.NextPage
 	}
 
-	fmt.Printf("Data ingestion completed. Total ratings ingested: %d\n", totalIngested)
-	return nil
+	if s.autoEnrichWorkers > 0 {
+		for ticker, inserted := range result.NewByTicker {
+			if inserted > 0 {
+				newTickers[ticker] = true
+			}
+		}
+		result.AutoEnriched = s.enrichNewTickers(ctx, slices.Sorted(maps.Keys(newTickers)))
+	}
+
+	if opts.Diff {
+		s.logger.Info("data ingestion completed", "fetched", result.TotalFetched, "total", result.Inserted,
+			"skipped_duplicates", result.SkippedDuplicates, "new_by_ticker", result.NewByTicker)
+	} else {
+		s.logger.Info("data ingestion completed", "fetched", result.TotalFetched, "total", result.Inserted,
+			"skipped_duplicates", result.SkippedDuplicates)
+	}
+	return result, nil
+}
+
+// timeBudgetExhausted reports whether fetchCtx ended because the run's time
+// budget ran out rather than because the caller's ctx was cancelled
+func timeBudgetExhausted(ctx, fetchCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded)
+}
+
+// timeBudgetError logs and describes an ingestion run cut short by its time budget
+func (s *Service) timeBudgetError(result *domain.IngestionResult, pagesFetched int) error {
+	s.logger.Warn("ingestion time budget exhausted, returning partial results",
+		"budget", s.timeBudget, "pages", pagesFetched, "fetched", result.TotalFetched, "inserted", result.Inserted)
+	return apperrors.New(apperrors.ErrCodeTimeout, fmt.Sprintf(
+		"ingestion time budget of %s exhausted after %d pages; %d ratings fetched and %d stored before paging stopped",
+		s.timeBudget, pagesFetched, result.TotalFetched, result.Inserted))
+}
+
+// storeRatings stores a page's ratings in sub-batches of at most batchSize and
+// returns how many were newly inserted. In diff mode new ratings are counted per
+// ticker in newByTicker; with auto-enrichment the tickers of new ratings are
+// marked in newTickers.
+func (s *Service) storeRatings(ctx context.Context, ratings []*domain.StockRating, opts IngestOptions, newByTicker map[string]int, newTickers map[string]bool) (int, error) {
+	// Every rating on the page may have repeated one from an earlier page
+	if len(ratings) == 0 {
+		return 0, nil
+	}
+
+	size := s.batchSize
+	if size == 0 {
+		size = len(ratings)
+	}
+
+	total := 0
+	for batch := range slices.Chunk(ratings, size) {
+		var inserted int
+		var err error
+		switch {
+		case opts.Diff:
+			inserted, err = s.storeRatingsByTicker(ctx, batch, newByTicker)
+		case s.autoEnrichWorkers > 0:
+			inserted, err = s.storeRatingsTrackingTickers(ctx, batch, newTickers)
+		default:
+			inserted, err = s.stockRepo.CreateStockRatingsBatch(ctx, batch)
+		}
+		total += inserted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// storeRatingsTrackingTickers stores ratings as one batch and marks the ticker of
+// every newly inserted rating in newTickers
+func (s *Service) storeRatingsTrackingTickers(ctx context.Context, ratings []*domain.StockRating, newTickers map[string]bool) (int, error) {
+	insertedIDs, err := s.stockRepo.CreateStockRatingsBatchReturningIDs(ctx, ratings)
+	if err != nil {
+		return 0, err
+	}
+
+	tickerByID := make(map[uuid.UUID]string, len(ratings))
+	for _, rating := range ratings {
+		tickerByID[rating.RatingID] = rating.Ticker
+	}
+	for _, id := range insertedIDs {
+		newTickers[tickerByID[id]] = true
+	}
+
+	return len(insertedIDs), nil
+}
+
+// enrichNewTickers enriches the given tickers that have never been enriched, or whose
+// record holds no data, running up to autoEnrichWorkers enrichments at once, and
+// returns the enriched tickers in order. Failures are logged rather than returned so
+// enrichment never fails the ingestion run that triggered it.
+func (s *Service) enrichNewTickers(ctx context.Context, tickers []string) []string {
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		enriched []string
+	)
+
+	queue := make(chan string)
+	for range min(s.autoEnrichWorkers, len(tickers)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ticker := range queue {
+				data, err := s.stockRepo.GetEnrichedStockData(ctx, ticker)
+				if err == nil && hasEnrichedData(data) {
+					continue
+				}
+				if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+					s.logger.Warn("failed to check enrichment for new ticker", "ticker", ticker, "error", err)
+					continue
+				}
+
+				if err := s.enrichTicker(ctx, ticker); err != nil {
+					s.logger.Warn("failed to enrich new ticker", "ticker", ticker, "error", err)
+					continue
+				}
+				mutex.Lock()
+				enriched = append(enriched, ticker)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+enqueue:
+	for _, ticker := range tickers {
+		select {
+		case queue <- ticker:
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	slices.Sort(enriched)
+	if len(enriched) > 0 {
+		s.logger.Info("enriched new tickers", "tickers", enriched)
+	}
+	return enriched
+}
+
+// storeRatingsByTicker stores each ticker's ratings as a separate batch, adding
+// the number of newly inserted ratings per ticker to newByTicker
+func (s *Service) storeRatingsByTicker(ctx context.Context, ratings []*domain.StockRating, newByTicker map[string]int) (int, error) {
+	var tickers []string
+	byTicker := make(map[string][]*domain.StockRating)
+	for _, rating := range ratings {
+		if _, exists := byTicker[rating.Ticker]; !exists {
+			tickers = append(tickers, rating.Ticker)
+		}
+		byTicker[rating.Ticker] = append(byTicker[rating.Ticker], rating)
+	}
+
+	total := 0
+	for _, ticker := range tickers {
+		inserted, err := s.stockRepo.CreateStockRatingsBatch(ctx, byTicker[ticker])
+		if err != nil {
+			return total, err
+		}
+
+		newByTicker[ticker] += inserted
+		total += inserted
+	}
+
+	return total, nil
 }
 
 // fetchDataFromAPI makes HTTP request to the external API
 func (s *Service) fetchDataFromAPI(ctx context.Context, nextPage *string) (*domain.APIResponse, error) {
+	apiResponse, _, err := s.fetchPage(ctx, nextPage)
+	return apiResponse, err
+}
+
+// fetchPage requests one page from the external API and returns it together
+// with the response headers, which carry the upstream's rate-limit state
+func (s *Service) fetchPage(ctx context.Context, nextPage *string) (*domain.APIResponse, http.Header, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", s.apiURL, nil)
 	if err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to create API request")
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to create API request")
 	}
 
 	// Add authorization header
@@ -98,51 +510,135 @@ func (s *Service) fetchDataFromAPI(ctx context.Context, nextPage *string) (*doma
 
 	// Add next_page parameter if provided
 	if nextPage != nil && *nextPage != "" {
+		if len(*nextPage) > s.maxPageTokenLength {
+			return nil, nil, apperrors.New(apperrors.ErrCodeUpstreamAPI,
+				fmt.Sprintf("next_page token of %d bytes exceeds the %d byte limit", len(*nextPage), s.maxPageTokenLength))
+		}
+
 		q := req.URL.Query()
 		q.Add("next_page", *nextPage)
 		req.URL.RawQuery = q.Encode()
 	}
 
 	// Make the request with retry logic
-	resp, err := s.makeRequestWithRetry(ctx, req, 3)
+	resp, err := s.makeRequestWithRetry(ctx, req, s.maxRetries)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, apperrors.New(apperrors.ErrCodeUpstreamAPI,
+		return nil, nil, apperrors.New(apperrors.ErrCodeUpstreamAPI,
 			fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(body)))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to read API response body")
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to read API response body")
 	}
 
 	var apiResponse domain.APIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to unmarshal API response")
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrCodeUpstreamAPI, "failed to unmarshal API response")
 	}
 
-	return &apiResponse, nil
+	return &apiResponse, resp.Header, nil
 }
 
-// makeRequestWithRetry implements exponential backoff retry logic
+// pacingDelay returns how long to pause before the next page: the configured page
+// delay, raised to honor Retry-After or a nearly exhausted X-RateLimit-Remaining
+func (s *Service) pacingDelay(headers http.Header) time.Duration {
+	delay := s.pageDelay
+
+	if retryAfter := parseRetryAfter(headers.Get("Retry-After"), s.clock.Now()); retryAfter > delay {
+		delay = retryAfter
+	}
+
+	if remaining, err := strconv.Atoi(headers.Get("X-RateLimit-Remaining")); err == nil &&
+		remaining <= lowRateLimitRemaining && delay < lowRateLimitDelay {
+		delay = lowRateLimitDelay
+	}
+
+	if delay > maxPacingDelay {
+		delay = maxPacingDelay
+	}
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date, which is measured from now
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if retryAt, err := http.ParseTime(value); err == nil && retryAt.After(now) {
+		return retryAt.Sub(now)
+	}
+
+	return 0
+}
+
+// sleepContext pauses for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryBackoffCeiling returns the longest wait before the given retry (1-based):
+// base, 2*base, 4*base, ... capped at maxRetryBackoff
+func retryBackoffCeiling(base time.Duration, retry int) time.Duration {
+	backoff := maxRetryBackoff
+	if shift := retry - 1; shift < 32 {
+		backoff = min(base<<uint(shift), maxRetryBackoff)
+	}
+	return backoff
+}
+
+// fullJitter picks a uniformly random wait in [0, ceiling] so concurrent
+// ingestions don't retry in lockstep. The math/rand global source is seeded
+// randomly at startup, so runs don't share a sequence.
+func fullJitter(ceiling time.Duration) time.Duration {
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// makeRequestWithRetry retries server errors, 429 responses and transport
+// failures with exponential backoff and full jitter, giving up early if ctx is
+// cancelled. A 429 carrying Retry-After waits that long instead, up to maxPacingDelay.
 func (s *Service) makeRequestWithRetry(ctx context.Context, req *http.Request, maxRetries int) (*http.Response, error) {
 	var lastErr error
+	var retryAfter time.Duration
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s, etc.
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(backoff):
+			delay := retryAfter
+			if delay <= 0 {
+				delay = s.jitter(retryBackoffCeiling(s.retryBackoffBase, attempt))
+			}
+			if err := s.wait(ctx, min(delay, maxPacingDelay)); err != nil {
+				return nil, err
 			}
 		}
+		retryAfter = 0
 
 		resp, err := s.client.Do(req)
 		if err != nil {
@@ -150,6 +646,13 @@ func (s *Service) makeRequestWithRetry(ctx context.Context, req *http.Request, m
 			continue
 		}
 
+		if resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), s.clock.Now())
+			resp.Body.Close()
+			lastErr = fmt.Errorf("rate limited: %d", resp.StatusCode)
+			continue
+		}
+
 		// Success or non-retryable error
 		if resp.StatusCode < 500 {
 			return resp, nil
@@ -207,23 +710,17 @@ func (s *Service) transformAPIRatings(apiRatings []domain.APIStockRating) ([]dom
 			TargetFrom: targetFrom,
 			TargetTo:   targetTo,
 			Time:       parsedTime,
-			CreatedAt:  time.Now(),
+			CreatedAt:  s.clock.Now(),
+			Source:     s.source,
 		}
 
-		// Create unique key to prevent duplicates
-		uniqueKey := fmt.Sprintf("%s-%s-%s-%s-%s",
-			rating.Ticker,
-			rating.Brokerage,
-			rating.RatingTo,
-			rating.Time.Format(time.RFC3339),
-			rating.Action)
-
 		// Only add if this combination doesn't exist yet
+		uniqueKey := ratingKey(rating)
 		if _, exists := uniqueRatings[uniqueKey]; !exists {
 			uniqueRatings[uniqueKey] = rating
 		} else {
-			fmt.Printf("🔄 Skipping duplicate rating: %s - %s - %s\n",
-				rating.Ticker, rating.Brokerage, rating.RatingTo)
+			s.logger.Debug("skipping duplicate rating",
+				"ticker", rating.Ticker, "brokerage", rating.Brokerage, "rating_to", rating.RatingTo)
 		}
 	}
 
@@ -232,10 +729,41 @@ func (s *Service) transformAPIRatings(apiRatings []domain.APIStockRating) ([]dom
 		ratings = append(ratings, rating)
 	}
 
-	fmt.Printf("Filtered ratings: %d → %d\n", len(apiRatings), len(ratings))
+	s.logger.Info("filtered duplicate ratings", "received", len(apiRatings), "unique", len(ratings))
 	return ratings, nil
 }
 
+// ratingKey identifies a rating for duplicate detection during ingestion
+func ratingKey(rating domain.StockRating) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%s",
+		rating.Ticker,
+		rating.Brokerage,
+		rating.RatingTo,
+		rating.Time.Format(time.RFC3339),
+		rating.Action)
+}
+
+// dropSeenRatings removes ratings already seen on an earlier page of the run and
+// records the keys of the rest in seen. Ratings are keyed by the database conflict
+// key, so a rating differing only in fields the upsert ignores, such as action, still
+// counts as seen. Once seen holds limit keys no more are
+// recorded, keeping memory bounded on very large runs; later repeats are then
+// only caught by the database's unique index.
+func dropSeenRatings(ratings []domain.StockRating, seen map[string]bool, limit int) []domain.StockRating {
+	kept := ratings[:0]
+	for _, rating := range ratings {
+		key := rating.ConflictKey()
+		if seen[key] {
+			continue
+		}
+		if len(seen) < limit {
+			seen[key] = true
+		}
+		kept = append(kept, rating)
+	}
+	return kept
+}
+
 // parsePrice extracts numeric value from price string
 func (s *Service) parsePrice(priceStr string) (float64, error) {
 	cleaned := strings.TrimSpace(priceStr)
@@ -246,8 +774,86 @@ func (s *Service) parsePrice(priceStr string) (float64, error) {
 	return strconv.ParseFloat(cleaned, 64)
 }
 
-// EnrichStockData fetches additional data for stocks from external sources
-func (s *Service) EnrichStockData(ctx context.Context, tickers []string) error {
-	fmt.Printf("Enriching data for %d tickers (not implemented)\n", len(tickers))
+// Ensure Service satisfies the domain contract, including enrichment
+var _ domain.IngestionService = (*Service)(nil)
+
+// EnrichStockData fetches additional data for stocks whose enriched data is stale and
+// returns how many tickers were refreshed
+func (s *Service) EnrichStockData(ctx context.Context, tickers []string) (int, error) {
+	return s.EnrichStockDataWithOptions(ctx, tickers, EnrichOptions{})
+}
+
+// EnrichStockDataWithOptions fetches additional data for stocks from external sources,
+// skipping tickers refreshed within the freshness window unless opts.Force is set. It
+// returns how many tickers were refreshed, including those refreshed before an error.
+func (s *Service) EnrichStockDataWithOptions(ctx context.Context, tickers []string, opts EnrichOptions) (int, error) {
+	enriched, skipped := 0, 0
+
+	for _, ticker := range tickers {
+		if !opts.Force {
+			fresh, err := s.isEnrichmentFresh(ctx, ticker)
+			if err != nil {
+				return enriched, err
+			}
+			if fresh {
+				skipped++
+				continue
+			}
+		}
+
+		if err := s.enrichTicker(ctx, ticker); err != nil {
+			return enriched, err
+		}
+		enriched++
+	}
+
+	s.logger.Info("enrichment completed", "refreshed", enriched, "fresh", skipped)
+	return enriched, nil
+}
+
+// isEnrichmentFresh reports whether a ticker's enriched data is younger than the freshness
+// window. Records holding no data, such as the empty placeholders older versions stored,
+// are never fresh so they cannot hide that the ticker was never really enriched.
+func (s *Service) isEnrichmentFresh(ctx context.Context, ticker string) (bool, error) {
+	data, err := s.stockRepo.GetEnrichedStockData(ctx, ticker)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check enrichment freshness for %s: %w", ticker, err)
+	}
+
+	if !hasEnrichedData(data) {
+		return false, nil
+	}
+
+	return s.clock.Now().Sub(data.UpdatedAt) < s.enrichmentFreshness, nil
+}
+
+// hasEnrichedData reports whether an enriched data record holds any data
+func hasEnrichedData(data *domain.EnrichedStockData) bool {
+	return len(data.HistoricalPrices) > 0 || len(data.NewsSentiment) > 0
+}
+
+// enrichTicker fetches and stores the enriched data for a single ticker. Nothing is
+// stored when the fetch fails, so a failed enrichment never counts as fresh data.
+func (s *Service) enrichTicker(ctx context.Context, ticker string) error {
+	data, err := s.fetchEnrichment(ctx, ticker)
+	if err != nil {
+		return err
+	}
+	data.Ticker = ticker
+
+	if err := s.stockRepo.CreateEnrichedStockData(ctx, data); err != nil {
+		return fmt.Errorf("failed to store enriched data for %s: %w", ticker, err)
+	}
+
 	return nil
 }
+
+// noEnrichmentSource is the default enrichment fetch. No external enrichment sources
+// are wired up yet, so enrichment reports that it is not implemented rather than
+// storing empty records.
+func noEnrichmentSource(ctx context.Context, ticker string) (*domain.EnrichedStockData, error) {
+	return nil, apperrors.ErrNotImplemented.WithDetails("no enrichment source is configured")
+}
@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
 	apperrors "stock-analyzer/pkg/errors"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -29,26 +32,72 @@ func (m *MockStockRepository) CreateStockRating(ctx context.Context, rating *dom
 	return args.Error(0)
 }
 
+func (m *MockStockRepository) UpsertStockRating(ctx context.Context, rating *domain.StockRating) error {
+	args := m.Called(ctx, rating)
+	return args.Error(0)
+}
+
 func (m *MockStockRepository) CreateStockRatingsBatch(ctx context.Context, ratings []*domain.StockRating) (int, error) {
 	args := m.Called(ctx, ratings)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockStockRepository) CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ratings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 func (m *MockStockRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
 	args := m.Called(ctx, filters)
 	return args.Get(0).(*domain.PaginatedResponse[domain.StockRating]), args.Error(1)
 }
 
+func (m *MockStockRepository) StreamStockRatings(ctx context.Context, filters domain.FilterOptions, fn func(domain.StockRating) error) error {
+	args := m.Called(ctx, filters)
+	if ratings, ok := args.Get(0).([]domain.StockRating); ok {
+		for _, rating := range ratings {
+			if err := fn(rating); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockStockRepository) GetStockRatingsByTicker(ctx context.Context, ticker string) ([]domain.StockRating, error) {
 	args := m.Called(ctx, ticker)
 	return args.Get(0).([]domain.StockRating), args.Error(1)
 }
 
+func (m *MockStockRepository) GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]domain.StockRating, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.StockRating), args.Error(1)
+}
+
 func (m *MockStockRepository) GetUniqueTickers(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockStockRepository) GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockStockRepository) GetRatingDistribution(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func (m *MockStockRepository) CreateEnrichedStockData(ctx context.Context, data *domain.EnrichedStockData) error {
 	args := m.Called(ctx, data)
 	return args.Error(0)
@@ -80,9 +129,15 @@ func createMockAPIResponse(items []domain.APIStockRating, nextPage *string) *dom
 }
 
 func createMockAPIItems(count int) []domain.APIStockRating {
-	items := make([]domain.APIStockRating, count)
-	for i := 0; i < count; i++ {
-		items[i] = domain.APIStockRating{
+	return createMockAPIItemsFrom(0, count)
+}
+
+// createMockAPIItemsFrom creates count distinct items numbered from start, so
+// separate pages don't repeat each other's ratings
+func createMockAPIItemsFrom(start, count int) []domain.APIStockRating {
+	items := make([]domain.APIStockRating, 0, count)
+	for i := start; i < start+count; i++ {
+		items = append(items, domain.APIStockRating{
 			Ticker:     fmt.Sprintf("TICK%d", i),
 			Company:    fmt.Sprintf("Company %d", i),
 			Brokerage:  "Test Brokerage",
@@ -92,7 +147,7 @@ func createMockAPIItems(count int) []domain.APIStockRating {
 			TargetFrom: "150.00",
 			TargetTo:   "180.00",
 			Time:       time.Now().Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
-		}
+		})
 	}
 	return items
 }
@@ -138,12 +193,60 @@ func TestIngestAllData_Success_SinglePage(t *testing.T) {
 		return len(ratings) == 5
 	})).Return(5, nil)
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.NoError(t, err)
 	stockRepo.AssertExpectations(t)
 }
 
+// countingTransport counts requests before delegating to the default transport
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewServiceWithClient_UsesInjectedClient(t *testing.T) {
+	t.Log("Testing NewServiceWithClient: upstream requests go through the supplied client")
+	stockRepo := &MockStockRepository{}
+
+	page1 := createMockAPIResponse(createMockAPIItems(2), stringPtr("page2"))
+	page2 := createMockAPIResponse(createMockAPIItemsFrom(2, 1), nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("next_page") == "page2" {
+			json.NewEncoder(w).Encode(page2)
+			return
+		}
+		json.NewEncoder(w).Encode(page1)
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	service := NewServiceWithClient(stockRepo, server.URL, "test-token", &http.Client{Transport: transport})
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(1, nil).Once()
+
+	_, err := service.IngestAllData(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), transport.requests.Load())
+	stockRepo.AssertExpectations(t)
+}
+
+func TestNewServiceWithClient_NilClientUsesDefault(t *testing.T) {
+	t.Log("Testing NewServiceWithClient: a nil client falls back to the default timeout")
+	service := NewServiceWithClient(&MockStockRepository{}, "http://example.invalid", "test-token", nil)
+
+	require.NotNil(t, service.client)
+	assert.Equal(t, defaultHTTPTimeout, service.client.Timeout)
+}
+
 func TestIngestAllData_Success_MultiplePage(t *testing.T) {
 	t.Log("Testing IngestAllData: success with multiple pages of data")
 	stockRepo := &MockStockRepository{}
@@ -152,7 +255,7 @@ func TestIngestAllData_Success_MultiplePage(t *testing.T) {
 	page1Items := createMockAPIItems(3)
 	page1Response := createMockAPIResponse(page1Items, stringPtr("page2"))
 
-	page2Items := createMockAPIItems(2)
+	page2Items := createMockAPIItemsFrom(3, 2)
 	page2Response := createMockAPIResponse(page2Items, nil)
 
 	requestCount := 0
@@ -175,15 +278,610 @@ func TestIngestAllData_Success_MultiplePage(t *testing.T) {
 		return len(ratings) == 3
 	})).Return(3, nil).Once()
 
-	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
-		return len(ratings) == 2
-	})).Return(2, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Return(2, nil).Once()
+
+	_, err := service.IngestAllData(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requestCount)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_ResultAggregatesPages(t *testing.T) {
+	t.Log("Testing IngestAllData: result totals fetched, inserted and skipped ratings across pages")
+	stockRepo := &MockStockRepository{}
+
+	// Page 1 repeats one rating within the page; page 2 contains one already stored rating
+	page1Items := createMockAPIItems(3)
+	page1Items = append(page1Items, page1Items[0])
+	page1Response := createMockAPIResponse(page1Items, stringPtr("page2"))
+	page2Response := createMockAPIResponse(createMockAPIItemsFrom(3, 2), nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("next_page") == "page2" {
+			json.NewEncoder(w).Encode(page2Response)
+		} else {
+			json.NewEncoder(w).Encode(page1Response)
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 3
+	})).Return(3, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Return(1, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.TotalFetched)
+	assert.Equal(t, 4, result.Inserted)
+	assert.Equal(t, 2, result.SkippedDuplicates)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_AutoEnrichesOnlyNewTickers(t *testing.T) {
+	t.Log("Testing IngestAllData: auto-enrichment runs only for never-enriched tickers that received new ratings")
+	stockRepo := &MockStockRepository{}
+
+	// TICK0 and TICK1 get new ratings, TICK2's rating was already stored
+	response := createMockAPIResponse(createMockAPIItems(3), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetAutoEnrichNewTickers(true, 2)
+	stubEnrichmentSource(service)
+
+	// Rating IDs are generated during the run, so they are filled in once the batch arrives
+	insertedIDs := make([]uuid.UUID, 2)
+	stockRepo.On("CreateStockRatingsBatchReturningIDs", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, rating := range args.Get(1).([]*domain.StockRating) {
+				switch rating.Ticker {
+				case "TICK0":
+					insertedIDs[0] = rating.RatingID
+				case "TICK1":
+					insertedIDs[1] = rating.RatingID
+				}
+			}
+		}).
+		Return(insertedIDs, nil).Once()
+
+	// TICK1 was enriched before, so only TICK0 is new to the system
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "TICK0").Return(nil, apperrors.ErrNotFound)
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "TICK1").Return(&domain.EnrichedStockData{Ticker: "TICK1", HistoricalPrices: enrichedPrices()}, nil)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.MatchedBy(func(data *domain.EnrichedStockData) bool {
+		return data.Ticker == "TICK0"
+	})).Return(nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Inserted)
+	assert.Equal(t, []string{"TICK0"}, result.AutoEnriched)
+	stockRepo.AssertNotCalled(t, "GetEnrichedStockData", mock.Anything, "TICK2")
+	stockRepo.AssertNotCalled(t, "CreateStockRatingsBatch", mock.Anything, mock.Anything)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_AutoEnrichFailureDoesNotFailRun(t *testing.T) {
+	t.Log("Testing IngestAllData: a failed auto-enrichment is logged without failing ingestion")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse(createMockAPIItems(1), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetAutoEnrichNewTickers(true, 4)
+	stubEnrichmentSource(service)
+
+	insertedIDs := make([]uuid.UUID, 1)
+	stockRepo.On("CreateStockRatingsBatchReturningIDs", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			insertedIDs[0] = args.Get(1).([]*domain.StockRating)[0].RatingID
+		}).
+		Return(insertedIDs, nil)
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "TICK0").Return(nil, apperrors.ErrNotFound)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.Anything).Return(fmt.Errorf("connection reset"))
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Inserted)
+	assert.Empty(t, result.AutoEnriched)
+}
+
+func TestIngestAllData_AutoEnrichDisabled(t *testing.T) {
+	t.Log("Testing IngestAllData: without auto-enrichment the plain batch insert is used and nothing is enriched")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse(createMockAPIItems(2), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetAutoEnrichNewTickers(false, 4)
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil)
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Nil(t, result.AutoEnriched)
+	stockRepo.AssertNotCalled(t, "GetEnrichedStockData", mock.Anything, mock.Anything)
+}
+
+func TestIngestAllData_BatchSizeSplitsPage(t *testing.T) {
+	t.Log("Testing IngestAllData: a page larger than the batch size is stored in sub-batches")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse(createMockAPIItems(2500), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetBatchSize(1000)
+
+	var batchSizes []int
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			batchSizes = append(batchSizes, len(args.Get(1).([]*domain.StockRating)))
+		}).
+		Return(1000, nil).Twice()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			batchSizes = append(batchSizes, len(args.Get(1).([]*domain.StockRating)))
+		}).
+		Return(500, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1000, 1000, 500}, batchSizes)
+	assert.Equal(t, 2500, result.Inserted)
+	stockRepo.AssertNumberOfCalls(t, "CreateStockRatingsBatch", 3)
+}
+
+func TestIngestAllData_BatchSizeFailureStopsRun(t *testing.T) {
+	t.Log("Testing IngestAllData: a failed sub-batch stops the run without storing the rest of the page")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse(createMockAPIItems(5), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetBatchSize(2)
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(0, apperrors.ErrDatabaseFailure).Once()
+
+	_, err := service.IngestAllData(context.Background())
+
+	require.Error(t, err)
+	stockRepo.AssertNumberOfCalls(t, "CreateStockRatingsBatch", 2)
+}
+
+func TestIngestAllData_DedupsAcrossPages(t *testing.T) {
+	t.Log("Testing IngestAllData: a rating repeated on a later page is inserted once")
+	stockRepo := &MockStockRepository{}
+
+	// Page 2 repeats page 1's second item alongside one new item
+	page1Items := createMockAPIItems(2)
+	page2Items := []domain.APIStockRating{page1Items[1], createMockAPIItemsFrom(2, 1)[0]}
+	page1Response := createMockAPIResponse(page1Items, stringPtr("page2"))
+	page2Response := createMockAPIResponse(page2Items, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("next_page") == "page2" {
+			json.NewEncoder(w).Encode(page2Response)
+			return
+		}
+		json.NewEncoder(w).Encode(page1Response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	var stored []string
+	recordTickers := func(args mock.Arguments) {
+		for _, rating := range args.Get(1).([]*domain.StockRating) {
+			stored = append(stored, rating.Ticker)
+		}
+	}
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Run(recordTickers).Return(2, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 1
+	})).Run(recordTickers).Return(1, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"TICK0", "TICK1", "TICK2"}, stored)
+	assert.Equal(t, 4, result.TotalFetched)
+	assert.Equal(t, 3, result.Inserted)
+	assert.Equal(t, 1, result.SkippedDuplicates)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_PageOfOnlySeenRatingsSkipsStore(t *testing.T) {
+	t.Log("Testing IngestAllData: a page that only repeats earlier ratings is not sent to the repository")
+	stockRepo := &MockStockRepository{}
+
+	items := createMockAPIItems(2)
+	page1Response := createMockAPIResponse(items, stringPtr("page2"))
+	page2Response := createMockAPIResponse(items, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("next_page") == "page2" {
+			json.NewEncoder(w).Encode(page2Response)
+			return
+		}
+		json.NewEncoder(w).Encode(page1Response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Inserted)
+	assert.Equal(t, 2, result.SkippedDuplicates)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestDropSeenRatings_CapsRememberedKeys(t *testing.T) {
+	t.Log("Testing utility: dropSeenRatings stops remembering keys at the cap but still drops known repeats")
+	service := NewService(&MockStockRepository{}, "", "")
+	ratings, err := service.transformAPIRatings(createMockAPIItems(3))
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	kept := dropSeenRatings(slices.Clone(ratings), seen, 2)
+	assert.Len(t, kept, 3)
+	assert.Len(t, seen, 2)
+
+	// The two remembered ratings are dropped; the one past the cap gets through again
+	kept = dropSeenRatings(slices.Clone(ratings), seen, 2)
+	require.Len(t, kept, 1)
+	assert.False(t, seen[kept[0].ConflictKey()])
+}
+
+func TestDropSeenRatings_KeysOnConflictKey(t *testing.T) {
+	t.Log("Testing utility: dropSeenRatings treats ratings sharing the database conflict key as seen")
+	service := NewService(&MockStockRepository{}, "", "")
+	ratings, err := service.transformAPIRatings(createMockAPIItems(1))
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	require.Len(t, dropSeenRatings(slices.Clone(ratings), seen, 10), 1)
+
+	// A later page re-reporting the rating with another action maps to the same row
+	reworded := slices.Clone(ratings)
+	reworded[0].Action = "reiterated by"
+	assert.Empty(t, dropSeenRatings(reworded, seen, 10))
+}
+
+// recordWaits replaces the service's pause with one that records requested delays
+func recordWaits(service *Service) *[]time.Duration {
+	waits := &[]time.Duration{}
+	service.wait = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return waits
+}
+
+func TestIngestAllData_PageDelay(t *testing.T) {
+	t.Log("Testing IngestAllData: the configured delay is applied between pages only")
+	stockRepo := &MockStockRepository{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("next_page") {
+		case "":
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), stringPtr("page2")))
+		case "page2":
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), stringPtr("page3")))
+		default:
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), nil))
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetPageDelay(250 * time.Millisecond)
+	waits := recordWaits(service)
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, *waits)
+}
+
+func TestIngestAllData_RateLimitHeadersSlowPaging(t *testing.T) {
+	t.Log("Testing IngestAllData: low X-RateLimit-Remaining and Retry-After lengthen the pause")
+	stockRepo := &MockStockRepository{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("next_page") {
+		case "":
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), stringPtr("page2")))
+		case "page2":
+			w.Header().Set("X-RateLimit-Remaining", "2")
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), stringPtr("page3")))
+		case "page3":
+			w.Header().Set("Retry-After", "3")
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), stringPtr("page4")))
+		default:
+			json.NewEncoder(w).Encode(createMockAPIResponse(createMockAPIItems(1), nil))
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetPageDelay(10 * time.Millisecond)
+	waits := recordWaits(service)
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, err := service.IngestAllData(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, lowRateLimitDelay, 3 * time.Second}, *waits)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Log("Testing utility: parseRetryAfter")
+
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
+
+func TestIngestAllDataWithOptions_DiffCountsPerTicker(t *testing.T) {
+	t.Log("Testing IngestAllDataWithOptions: reports new ratings per ticker in diff mode")
+	stockRepo := &MockStockRepository{}
+
+	items := createMockAPIItems(5)
+	for i := range items {
+		if i < 3 {
+			items[i].Ticker = "AAPL"
+		} else {
+			items[i].Ticker = "MSFT"
+		}
+	}
+	response := createMockAPIResponse(items, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	// Two of the three AAPL ratings are already stored; both MSFT ratings are new
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 3 && ratings[0].Ticker == "AAPL"
+	})).Return(1, nil).Once()
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2 && ratings[0].Ticker == "MSFT"
+	})).Return(2, nil).Once()
+
+	result, err := service.IngestAllDataWithOptions(context.Background(), IngestOptions{Diff: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+	assert.Equal(t, map[string]int{"AAPL": 1, "MSFT": 2}, result.NewByTicker)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllDataWithOptions_WithoutDiff(t *testing.T) {
+	t.Log("Testing IngestAllDataWithOptions: stores each page as one batch without diff mode")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse(createMockAPIItems(4), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 4
+	})).Return(3, nil).Once()
+
+	result, err := service.IngestAllDataWithOptions(context.Background(), IngestOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+	assert.Nil(t, result.NewByTicker)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_EmptyMiddlePage(t *testing.T) {
+	t.Log("Testing IngestAllData: continues past an empty page that has a next page")
+	stockRepo := &MockStockRepository{}
+
+	page1Response := createMockAPIResponse(createMockAPIItems(3), stringPtr("page2"))
+	page2Response := createMockAPIResponse([]domain.APIStockRating{}, stringPtr("page3"))
+	page3Response := createMockAPIResponse(createMockAPIItemsFrom(3, 2), nil)
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("next_page") {
+		case "page2":
+			json.NewEncoder(w).Encode(page2Response)
+		case "page3":
+			json.NewEncoder(w).Encode(page3Response)
+		default:
+			json.NewEncoder(w).Encode(page1Response)
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 3
+	})).Return(3, nil).Once()
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.MatchedBy(func(ratings []*domain.StockRating) bool {
+		return len(ratings) == 2
+	})).Return(2, nil).Once()
+
+	_, err := service.IngestAllData(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requestCount)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestIngestAllData_PaginationLoop(t *testing.T) {
+	t.Log("Testing IngestAllData: stops when the upstream repeats a next page token")
+	stockRepo := &MockStockRepository{}
+
+	response := createMockAPIResponse([]domain.APIStockRating{}, stringPtr("page2"))
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+
+	_, err := service.IngestAllData(context.Background())
+
+	require.Error(t, err)
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeUpstreamAPI, appErr.Code)
+	assert.Equal(t, 2, requestCount)
+	stockRepo.AssertNotCalled(t, "CreateStockRatingsBatch")
+}
+
+func TestIngestAllData_OversizedNextPageToken(t *testing.T) {
+	t.Log("Testing IngestAllData: an oversized next_page token aborts with an upstream error before it is sent")
+	stockRepo := &MockStockRepository{}
+
+	oversized := strings.Repeat("x", DefaultMaxPageTokenLength+1)
+	response := createMockAPIResponse(createMockAPIItems(2), &oversized)
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil).Once()
+
+	result, err := service.IngestAllData(context.Background())
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeUpstreamAPI, appErr.Code)
+	assert.Contains(t, appErr.Message, "exceeds the 2048 byte limit")
+	assert.Equal(t, 1, requestCount)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestFetchDataFromAPI_PageTokenLimit(t *testing.T) {
+	t.Log("Testing fetchDataFromAPI: tokens up to the configured limit are sent, longer ones are refused")
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(createMockAPIResponse(nil, nil))
+	}))
+	defer server.Close()
+
+	service := NewService(&MockStockRepository{}, server.URL, "test-token")
+	service.SetMaxPageTokenLength(8)
+
+	token := "12345678"
+	_, err := service.fetchDataFromAPI(context.Background(), &token)
+	require.NoError(t, err)
+
+	token += "9"
+	_, err = service.fetchDataFromAPI(context.Background(), &token)
+	assert.ErrorIs(t, err, apperrors.ErrUpstreamAPIFailure)
+	assert.Equal(t, 1, requestCount)
+
+	service.SetMaxPageTokenLength(0)
+	assert.Equal(t, DefaultMaxPageTokenLength, service.maxPageTokenLength)
+}
+
+func TestIngestAllData_TooManyEmptyPages(t *testing.T) {
+	t.Log("Testing IngestAllData: stops after too many consecutive empty pages")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(createMockAPIResponse([]domain.APIStockRating{}, stringPtr(fmt.Sprintf("page%d", requestCount+1))))
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.NoError(t, err)
-	assert.Equal(t, 2, requestCount)
-	stockRepo.AssertExpectations(t)
+	assert.Equal(t, maxConsecutiveEmptyPages, requestCount)
+	stockRepo.AssertNotCalled(t, "CreateStockRatingsBatch")
 }
 
 func TestIngestAllData_EmptyResponse(t *testing.T) {
@@ -200,7 +898,7 @@ func TestIngestAllData_EmptyResponse(t *testing.T) {
 
 	service := NewService(stockRepo, server.URL, "test-token")
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.NoError(t, err)
 	stockRepo.AssertNotCalled(t, "CreateStockRatingsBatch")
@@ -218,7 +916,7 @@ func TestIngestAllData_APIError(t *testing.T) {
 
 	service := NewService(stockRepo, server.URL, "test-token")
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch data from API")
@@ -255,7 +953,7 @@ func TestIngestAllData_TransformationError(t *testing.T) {
 
 	service := NewService(stockRepo, server.URL, "test-token")
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to transform API ratings")
@@ -280,13 +978,78 @@ func TestIngestAllData_RepositoryError(t *testing.T) {
 	// Mock repository error
 	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(0, apperrors.ErrDatabaseFailure)
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to store ratings batch")
 	stockRepo.AssertExpectations(t)
 }
 
+func TestIngestAllData_TimeBudgetReturnsPartialResult(t *testing.T) {
+	t.Log("Testing IngestAllData: paging stops when the time budget runs out, returning what was stored")
+	stockRepo := &MockStockRepository{}
+
+	// Every page is slow and points at another, so only the budget ends the run
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := requestCount.Add(1)
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+
+		response := createMockAPIResponse(createMockAPIItemsFrom(int(page)*2, 2), stringPtr(fmt.Sprintf("page%d", page+1)))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetTimeBudget(250 * time.Millisecond)
+
+	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(2, nil)
+
+	start := time.Now()
+	result, err := service.IngestAllData(context.Background())
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeTimeout, appErr.Code)
+	assert.Contains(t, err.Error(), "after 2 pages")
+	assert.Less(t, time.Since(start), time.Second)
+
+	require.NotNil(t, result)
+	assert.Equal(t, 4, result.TotalFetched)
+	assert.Equal(t, 4, result.Inserted)
+	stockRepo.AssertNumberOfCalls(t, "CreateStockRatingsBatch", 2)
+}
+
+func TestIngestAllData_TimeBudgetIgnoresCallerCancellation(t *testing.T) {
+	t.Log("Testing IngestAllData: a caller cancellation is not reported as an exhausted time budget")
+	stockRepo := &MockStockRepository{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetTimeBudget(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, err := service.IngestAllData(ctx)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.NotErrorIs(t, err, apperrors.ErrRequestTimeout)
+}
+
 func TestIngestAllData_ContextCancellation(t *testing.T) {
 	t.Log("Testing IngestAllData: handles context cancellation")
 	stockRepo := &MockStockRepository{}
@@ -308,7 +1071,7 @@ func TestIngestAllData_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
-	err := service.IngestAllData(ctx)
+	_, err := service.IngestAllData(ctx)
 
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "context deadline exceeded") || strings.Contains(err.Error(), "context canceled"))
@@ -526,6 +1289,279 @@ func TestMakeRequestWithRetry_NonRetryableError(t *testing.T) {
 	resp.Body.Close()
 }
 
+func TestMakeRequestWithRetry_JitteredBackoffWithinBounds(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: each retry waits a jittered time within the capped exponential ceiling")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	waits := recordWaits(service)
+
+	var ceilings []time.Duration
+	service.jitter = func(ceiling time.Duration) time.Duration {
+		ceilings = append(ceilings, ceiling)
+		return fullJitter(ceiling)
+	}
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = service.makeRequestWithRetry(context.Background(), req, 7)
+	require.Error(t, err)
+
+	assert.Equal(t, 8, requestCount)
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		16 * time.Second, maxRetryBackoff, maxRetryBackoff,
+	}, ceilings)
+	require.Len(t, *waits, len(ceilings))
+	for i, wait := range *waits {
+		assert.GreaterOrEqual(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, ceilings[i])
+	}
+}
+
+func TestMakeRequestWithRetry_RateLimitedHonorsRetryAfter(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: a 429 with Retry-After in seconds waits that long, then succeeds")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	waits := recordWaits(service)
+	service.jitter = func(time.Duration) time.Duration {
+		t.Fatal("jittered backoff should not be used when Retry-After is present")
+		return 0
+	}
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := service.makeRequestWithRetry(context.Background(), req, 3)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requestCount)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *waits)
+}
+
+func TestMakeRequestWithRetry_RateLimitedHonorsRetryAfterDate(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: a 429 with an HTTP-date Retry-After waits until that time")
+	stockRepo := &MockStockRepository{}
+
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount == 1 {
+			w.Header().Set("Retry-After", now.Add(12*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetClock(clock.NewFake(now))
+	waits := recordWaits(service)
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := service.makeRequestWithRetry(context.Background(), req, 3)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{12 * time.Second}, *waits)
+}
+
+func TestMakeRequestWithRetry_RateLimitedWithoutRetryAfter(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: a 429 without Retry-After falls back to the jittered backoff")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	waits := recordWaits(service)
+	service.jitter = func(ceiling time.Duration) time.Duration { return ceiling / 2 }
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := service.makeRequestWithRetry(context.Background(), req, 3)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requestCount)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, *waits)
+}
+
+func TestMakeRequestWithRetry_RateLimitedExhaustsRetries(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: persistent 429s fail as an upstream error after max retries")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	recordWaits(service)
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := service.makeRequestWithRetry(context.Background(), req, 2)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, 3, requestCount)
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeUpstreamAPI, appErr.Code)
+	assert.Contains(t, err.Error(), "rate limited: 429")
+}
+
+func TestMakeRequestWithRetry_StopsWhenContextCancelled(t *testing.T) {
+	t.Log("Testing makeRequestWithRetry: a cancelled context ends the backoff wait")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.jitter = func(ceiling time.Duration) time.Duration { return ceiling }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := service.makeRequestWithRetry(ctx, req, 3)
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, requestCount)
+	assert.Less(t, time.Since(start), time.Second, "should not wait out the full backoff")
+}
+
+func TestFetchDataFromAPI_HonorsConfiguredRetryPolicy(t *testing.T) {
+	t.Log("Testing fetchDataFromAPI: the configured retry count and backoff base are used")
+	stockRepo := &MockStockRepository{}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	service := NewService(stockRepo, server.URL, "test-token")
+	service.SetRetryPolicy(5, 100*time.Millisecond)
+	waits := recordWaits(service)
+	service.jitter = func(ceiling time.Duration) time.Duration { return ceiling }
+
+	_, err := service.fetchDataFromAPI(context.Background(), nil)
+
+	require.Error(t, err)
+	assert.Equal(t, 6, requestCount, "initial attempt plus 5 retries")
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, 1600 * time.Millisecond,
+	}, *waits)
+}
+
+func TestSetRetryPolicy_IgnoresNegativeValues(t *testing.T) {
+	t.Log("Testing SetRetryPolicy: negative values keep the defaults")
+	service := NewService(&MockStockRepository{}, "test-url", "test-token")
+
+	service.SetRetryPolicy(-1, -time.Second)
+	assert.Equal(t, defaultMaxRetries, service.maxRetries)
+	assert.Equal(t, defaultRetryBackoffBase, service.retryBackoffBase)
+
+	service.SetRetryPolicy(0, 0)
+	assert.Equal(t, 0, service.maxRetries)
+	assert.Equal(t, time.Duration(0), service.retryBackoffBase)
+}
+
+func TestFullJitter(t *testing.T) {
+	t.Log("Testing fullJitter: results stay within [0, ceiling] and vary")
+	assert.Equal(t, time.Duration(0), fullJitter(0))
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		d := fullJitter(time.Second)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, time.Second)
+		seen[d] = true
+	}
+	assert.Greater(t, len(seen), 1, "jitter should not be constant")
+}
+
+func TestRetryBackoffCeiling(t *testing.T) {
+	t.Log("Testing retryBackoffCeiling: doubles per retry and is capped")
+	assert.Equal(t, time.Second, retryBackoffCeiling(time.Second, 1))
+	assert.Equal(t, 4*time.Second, retryBackoffCeiling(time.Second, 3))
+	assert.Equal(t, 800*time.Millisecond, retryBackoffCeiling(200*time.Millisecond, 3))
+	assert.Equal(t, maxRetryBackoff, retryBackoffCeiling(time.Second, 6))
+	assert.Equal(t, maxRetryBackoff, retryBackoffCeiling(time.Second, 100))
+}
+
+func TestTransformAPIRatings_StampsSource(t *testing.T) {
+	t.Log("Testing transformAPIRatings: ratings carry the configured source name")
+	service := NewService(&MockStockRepository{}, "test-url", "test-token")
+	apiRatings := []domain.APIStockRating{{
+		Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Goldman Sachs",
+		Action: "upgraded by", RatingTo: "Buy", Time: "2023-12-01T10:00:00Z",
+	}}
+
+	ratings, err := service.transformAPIRatings(apiRatings)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSource, ratings[0].Source)
+
+	service.SetSource("backfill")
+	ratings, err = service.transformAPIRatings(apiRatings)
+	require.NoError(t, err)
+	assert.Equal(t, "backfill", ratings[0].Source)
+}
+
 func TestTransformAPIRatings_Success(t *testing.T) {
 	t.Log("Testing transformAPIRatings: successful transformation")
 	stockRepo := &MockStockRepository{}
@@ -643,15 +1679,138 @@ func TestTransformAPIRatings_InvalidTargetPrice(t *testing.T) {
 	assert.Equal(t, "AAPL", ratings[0].Ticker)
 }
 
-func TestEnrichStockData_NotImplemented(t *testing.T) {
-	t.Log("Testing EnrichStockData: confirms it runs without error (as it's not implemented)")
+func TestEnrichStockData_SkipsFreshTickers(t *testing.T) {
+	t.Log("Testing EnrichStockData: skips tickers whose enriched data is still fresh")
+	stockRepo := &MockStockRepository{}
+	service := NewService(stockRepo, "test-url", "test-token")
+	service.SetEnrichmentFreshness(time.Hour)
+	stubEnrichmentSource(service)
+
+	fresh := &domain.EnrichedStockData{Ticker: "AAPL", HistoricalPrices: enrichedPrices(), UpdatedAt: time.Now().Add(-10 * time.Minute)}
+	stale := &domain.EnrichedStockData{Ticker: "GOOGL", HistoricalPrices: enrichedPrices(), UpdatedAt: time.Now().Add(-2 * time.Hour)}
+
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").Return(fresh, nil)
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "GOOGL").Return(stale, nil)
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "MSFT").Return(nil, apperrors.ErrNotFound)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.MatchedBy(func(data *domain.EnrichedStockData) bool {
+		return data.Ticker == "GOOGL" || data.Ticker == "MSFT"
+	})).Return(nil).Twice()
+
+	refreshed, err := service.EnrichStockData(context.Background(), []string{"AAPL", "GOOGL", "MSFT"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refreshed)
+	stockRepo.AssertExpectations(t)
+	stockRepo.AssertNotCalled(t, "CreateEnrichedStockData", mock.Anything, mock.MatchedBy(func(data *domain.EnrichedStockData) bool {
+		return data.Ticker == "AAPL"
+	}))
+}
+
+func TestEnrichStockData_FreshnessBoundaryWithFakeClock(t *testing.T) {
+	t.Log("Testing EnrichStockData: data updated exactly one window ago is stale on the injected clock")
+	stockRepo := &MockStockRepository{}
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	service := NewService(stockRepo, "test-url", "test-token")
+	service.SetEnrichmentFreshness(time.Hour)
+	stubEnrichmentSource(service)
+	service.SetClock(clock.NewFake(now))
+
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").
+		Return(&domain.EnrichedStockData{Ticker: "AAPL", HistoricalPrices: enrichedPrices(), UpdatedAt: now.Add(-time.Hour + time.Second)}, nil)
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "GOOGL").
+		Return(&domain.EnrichedStockData{Ticker: "GOOGL", HistoricalPrices: enrichedPrices(), UpdatedAt: now.Add(-time.Hour)}, nil)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.MatchedBy(func(data *domain.EnrichedStockData) bool {
+		return data.Ticker == "GOOGL"
+	})).Return(nil).Once()
+
+	refreshed, err := service.EnrichStockData(context.Background(), []string{"AAPL", "GOOGL"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestEnrichStockData_EmptyRecordIsNeverFresh(t *testing.T) {
+	t.Log("Testing EnrichStockData: a recent record without any data is refreshed rather than treated as fresh")
+	stockRepo := &MockStockRepository{}
+	service := NewService(stockRepo, "test-url", "test-token")
+	service.SetEnrichmentFreshness(time.Hour)
+	stubEnrichmentSource(service)
+
+	placeholder := &domain.EnrichedStockData{
+		Ticker:           "AAPL",
+		HistoricalPrices: map[string]interface{}{},
+		NewsSentiment:    map[string]interface{}{},
+		UpdatedAt:        time.Now().Add(-time.Minute),
+	}
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").Return(placeholder, nil)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.MatchedBy(func(data *domain.EnrichedStockData) bool {
+		return data.Ticker == "AAPL" && len(data.HistoricalPrices) > 0
+	})).Return(nil).Once()
+
+	_, err := service.EnrichStockData(context.Background(), []string{"AAPL"})
+
+	assert.NoError(t, err)
+	stockRepo.AssertExpectations(t)
+}
+
+func TestEnrichStockData_ForceRefresh(t *testing.T) {
+	t.Log("Testing EnrichStockDataWithOptions: force refreshes fresh tickers without checking freshness")
+	stockRepo := &MockStockRepository{}
+	service := NewService(stockRepo, "test-url", "test-token")
+	stubEnrichmentSource(service)
+
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	refreshed, err := service.EnrichStockDataWithOptions(context.Background(), []string{"AAPL", "GOOGL"}, EnrichOptions{Force: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refreshed)
+	stockRepo.AssertExpectations(t)
+	stockRepo.AssertNotCalled(t, "GetEnrichedStockData", mock.Anything, mock.Anything)
+}
+
+func TestEnrichStockData_RepositoryError(t *testing.T) {
+	t.Log("Testing EnrichStockData: database errors during the freshness check are returned")
+	stockRepo := &MockStockRepository{}
+	service := NewService(stockRepo, "test-url", "test-token")
+
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").Return(nil, apperrors.ErrDatabaseFailure)
+
+	_, err := service.EnrichStockData(context.Background(), []string{"AAPL"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to check enrichment freshness for AAPL")
+	stockRepo.AssertNotCalled(t, "CreateEnrichedStockData", mock.Anything, mock.Anything)
+}
+
+func TestEnrichStockData_NotImplementedWithoutSource(t *testing.T) {
+	t.Log("Testing EnrichStockData: without an enrichment source stale tickers fail as not implemented and nothing is stored")
 	stockRepo := &MockStockRepository{}
 	service := NewService(stockRepo, "test-url", "test-token")
 
-	err := service.EnrichStockData(context.Background(), []string{"AAPL", "GOOGL"})
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").Return(nil, apperrors.ErrNotFound)
+
+	_, err := service.EnrichStockData(context.Background(), []string{"AAPL"})
+
+	assert.ErrorIs(t, err, apperrors.ErrNotImplemented)
+	stockRepo.AssertNotCalled(t, "CreateEnrichedStockData", mock.Anything, mock.Anything)
+}
+
+func TestIngestionService_InterfaceIncludesEnrichment(t *testing.T) {
+	t.Log("Testing IngestionService: enrichment is callable through the domain interface")
+	stockRepo := &MockStockRepository{}
+	concrete := NewService(stockRepo, "test-url", "test-token")
+	stubEnrichmentSource(concrete)
+	var service domain.IngestionService = concrete
+
+	stockRepo.On("GetEnrichedStockData", mock.Anything, "AAPL").Return(nil, apperrors.ErrNotFound)
+	stockRepo.On("CreateEnrichedStockData", mock.Anything, mock.AnythingOfType("*domain.EnrichedStockData")).Return(nil)
+
+	_, err := service.EnrichStockData(context.Background(), []string{"AAPL"})
 
-	// The method should return nil as it's not implemented yet
 	assert.NoError(t, err)
+	stockRepo.AssertExpectations(t)
 }
 
 // Benchmark tests for expensive operations
@@ -676,7 +1835,7 @@ func BenchmarkIngestAllData(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		err := service.IngestAllData(context.Background())
+		_, err := service.IngestAllData(context.Background())
 		require.NoError(b, err)
 	}
 }
@@ -738,7 +1897,7 @@ func TestConcurrentIngestAllData(t *testing.T) {
 	// Run multiple ingestion operations concurrently
 	for i := 0; i < concurrency; i++ {
 		go func() {
-			err := service.IngestAllData(context.Background())
+			_, err := service.IngestAllData(context.Background())
 			done <- err
 		}()
 	}
@@ -775,7 +1934,7 @@ func TestIngestAllData_VeryLargeResponse(t *testing.T) {
 		return len(ratings) == 10000
 	})).Return(10000, nil)
 
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 
 	assert.NoError(t, err)
 	stockRepo.AssertExpectations(t)
@@ -801,7 +1960,7 @@ func TestIngestAllData_SlowAPI(t *testing.T) {
 	stockRepo.On("CreateStockRatingsBatch", mock.Anything, mock.Anything).Return(5, nil)
 
 	start := time.Now()
-	err := service.IngestAllData(context.Background())
+	_, err := service.IngestAllData(context.Background())
 	duration := time.Since(start)
 
 	assert.NoError(t, err)
@@ -813,3 +1972,15 @@ func TestIngestAllData_SlowAPI(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+// enrichedPrices returns a small price history for enriched data fixtures
+func enrichedPrices() map[string]interface{} {
+	return map[string]interface{}{"data": []map[string]interface{}{{"close": 100.0}}}
+}
+
+// stubEnrichmentSource makes service fetch enrichedPrices for every ticker
+func stubEnrichmentSource(service *Service) {
+	service.fetchEnrichment = func(ctx context.Context, ticker string) (*domain.EnrichedStockData, error) {
+		return &domain.EnrichedStockData{HistoricalPrices: enrichedPrices()}, nil
+	}
+}
@@ -0,0 +1,49 @@
+package recommendation
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Generation phases reported by generationPhaseDuration
+const (
+	phaseFetchLatest = "fetch_latest"
+	phaseFilter      = "filter"
+	phaseScoring     = "scoring"
+)
+
+var (
+	// generationPhaseDuration tracks how long each phase of GenerateRecommendations takes
+	generationPhaseDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "recommendation_generation_phase_duration_seconds",
+			Help:    "Recommendation generation latency in seconds, partitioned by phase.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"phase"},
+	)
+
+	// generationsTotal counts completed recommendation generations
+	generationsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "recommendation_generations_total",
+			Help: "Total number of completed recommendation generations.",
+		},
+	)
+
+	// lastCandidates is the number of candidates that passed filtering in the latest generation
+	lastCandidates = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "recommendation_candidates",
+			Help: "Number of candidate stocks that passed filtering in the latest generation.",
+		},
+	)
+
+	// lastRecommendations is the number of recommendations returned by the latest generation
+	lastRecommendations = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "recommendation_results",
+			Help: "Number of recommendations returned by the latest generation.",
+		},
+	)
+)
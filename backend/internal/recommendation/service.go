@@ -3,75 +3,265 @@ package recommendation
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
 	apperrors "stock-analyzer/pkg/errors"
+	"stock-analyzer/pkg/locale"
 )
 
+// topRecommendations is how many of the highest-scored recommendations
+// GenerateRecommendations returns
+const topRecommendations = 10
+
 // Service implements the RecommendationService interface
 type Service struct {
 	stockRepo domain.StockRepository
+	clock     clock.Clock
+	// alpacaSvc supplies current prices for price-based factors; nil skips them
+	alpacaSvc domain.AlpacaService
 	cache     *recommendationCache
+	logger    *slog.Logger
+	// cacheEnabled is false when GetCachedRecommendations should regenerate on every call
+	cacheEnabled bool
+	// targetCurrency is the ISO 4217 currency the ratings feed quotes price targets in
+	targetCurrency string
+	// scoreNormalization fills NormalizedScore on every generated set
+	scoreNormalization bool
+	// refreshes notifies subscribers whenever the cache is refreshed
+	refreshes refreshSubscribers
 }
 
-// recommendationCache provides in-memory caching for recommendations
+// recommendationCache provides in-memory caching for recommendations. It holds the
+// complete ranked set, from which the top recommendations and paginated reads are both
+// served. Rationales are rendered for the request locale, so each locale keeps its own
+// copy; all copies expire together ttl after lastUpdated.
 type recommendationCache struct {
-	recommendations []domain.StockRecommendation
-	lastUpdated     time.Time
-	mutex           sync.RWMutex
-	ttl             time.Duration
+	byLocale    map[string][]domain.StockRecommendation
+	lastUpdated time.Time
+	mutex       sync.RWMutex
+	ttl         time.Duration
 }
 
-// NewService creates a new recommendation service
-func NewService(stockRepo domain.StockRepository) *Service {
+// NewService creates a new recommendation service. When cacheEnabled is false,
+// GetCachedRecommendations regenerates recommendations on every call.
+func NewService(stockRepo domain.StockRepository, cacheEnabled bool) *Service {
 	return &Service{
 		stockRepo: stockRepo,
 		cache: &recommendationCache{
 			ttl: 5 * time.Minute, // Cache for 5 minutes
 		},
+		clock:          clock.Real{},
+		logger:         slog.Default(),
+		cacheEnabled:   cacheEnabled,
+		targetCurrency: locale.DefaultCurrency,
 	}
 }
 
-// GenerateRecommendations analyzes data and generates stock recommendations
+// SetLogger replaces the logger used by the service
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetClock replaces the clock used for rating recency and cache expiry
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetTargetCurrency sets the ISO 4217 currency the ratings feed quotes price targets
+// in. It only changes how targets are written in rationales; amounts are not converted.
+func (s *Service) SetTargetCurrency(currency string) {
+	s.targetCurrency = strings.ToUpper(currency)
+}
+
+// SetScoreNormalization toggles min-max normalization of scores across each
+// generated set into NormalizedScore. The raw Score is left unchanged.
+func (s *Service) SetScoreNormalization(enabled bool) {
+	s.scoreNormalization = enabled
+}
+
+// SetAlpacaService enables price-based scoring factors using current market snapshots.
+// Without it, recommendations are scored from analyst ratings only.
+func (s *Service) SetAlpacaService(alpacaSvc domain.AlpacaService) {
+	s.alpacaSvc = alpacaSvc
+}
+
+// observePhase records the duration of a generation phase and returns the time it ended
+func (s *Service) observePhase(ctx context.Context, phase string, start time.Time) time.Time {
+	now := time.Now()
+	elapsed := now.Sub(start)
+	generationPhaseDuration.WithLabelValues(phase).Observe(elapsed.Seconds())
+	s.logger.DebugContext(ctx, "recommendation phase completed", "phase", phase, "duration", elapsed)
+	return now
+}
+
+// GenerateRecommendations analyzes data and generates the top stock recommendations
 func (s *Service) GenerateRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	ranked, err := s.generateRecorded(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return topOf(ranked), nil
+}
+
+// GenerateAllRecommendations analyzes data and returns every recommendation,
+// ordered by score, without the top-N truncation. It does not update the
+// generation metrics, which describe the top recommendations.
+func (s *Service) GenerateAllRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	ranked, _, err := s.generateRanked(ctx)
+	return ranked, err
+}
+
+// generateRecorded generates the complete ranked set and records the generation
+// metrics for the top recommendations taken from it
+func (s *Service) generateRecorded(ctx context.Context) ([]domain.StockRecommendation, error) {
+	ranked, stats, err := s.generateRanked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordGeneration(ctx, stats.tickers, stats.candidates, len(topOf(ranked)))
+	return ranked, nil
+}
+
+// generationStats counts the inputs of one generation for the generation metrics
+type generationStats struct {
+	tickers    int
+	candidates int
+}
+
+// generateRanked scores every candidate and returns them all, highest score first
+func (s *Service) generateRanked(ctx context.Context) ([]domain.StockRecommendation, generationStats, error) {
+	phaseStart := time.Now()
+
 	// Step 1: Get the latest ratings for all tickers
 	latestRatings, err := s.stockRepo.GetLatestRatingsByTicker(ctx)
 	if err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to get latest ratings")
+		return nil, generationStats{}, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to get latest ratings")
 	}
+	phaseStart = s.observePhase(ctx, phaseFetchLatest, phaseStart)
 
 	// Step 2: Filter stocks with positive analyst ratings
 	candidates := s.filterPositiveRatings(latestRatings)
+	phaseStart = s.observePhase(ctx, phaseFilter, phaseStart)
+	stats := generationStats{tickers: len(latestRatings), candidates: len(candidates)}
+	if len(candidates) == 0 {
+		return []domain.StockRecommendation{}, stats, nil
+	}
+
+	// Steps 3-4: Score candidates and sort them by score (descending)
+	recommendations := s.scoreCandidates(ctx, candidates)
+	s.observePhase(ctx, phaseScoring, phaseStart)
+
+	return recommendations, stats, nil
+}
+
+// topOf returns the highest-scored recommendations of a ranked set
+func topOf(ranked []domain.StockRecommendation) []domain.StockRecommendation {
+	return ranked[:min(len(ranked), topRecommendations)]
+}
+
+// GenerateRecommendationsForTickers scores only the given tickers, returning every
+// one with a positive analyst rating ordered by score. Tickers are matched
+// case-insensitively; unknown tickers are ignored. Watchlist runs do not update
+// the generation metrics, which describe the full universe.
+func (s *Service) GenerateRecommendationsForTickers(ctx context.Context, tickers []string) ([]domain.StockRecommendation, error) {
+	latestRatings, err := s.stockRepo.GetLatestRatingsByTicker(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to get latest ratings")
+	}
+
+	subset := make(map[string]*domain.StockRating, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if rating, exists := latestRatings[ticker]; exists {
+			subset[ticker] = rating
+		}
+	}
+
+	candidates := s.filterPositiveRatings(subset)
 	if len(candidates) == 0 {
 		return []domain.StockRecommendation{}, nil
 	}
 
-	// Step 3: Generate recommendations (using basic analysis to avoid slowdowns)
+	return s.scoreCandidates(ctx, candidates), nil
+}
+
+// scoreCandidates builds recommendations for the candidate ratings, applies price
+// factors, and sorts them by score (descending)
+func (s *Service) scoreCandidates(ctx context.Context, candidates []*domain.StockRating) []domain.StockRecommendation {
+	loc := locale.FromContext(ctx)
+
+	// Generate recommendations (using basic analysis to avoid slowdowns)
 	var recommendations []domain.StockRecommendation
 	for _, rating := range candidates {
 		// Skip enriched data lookup for now to avoid timeouts
-		recommendation := s.createBasicRecommendation(rating)
+		recommendation := s.createBasicRecommendation(loc, rating)
 		if recommendation != nil {
 			recommendations = append(recommendations, *recommendation)
 		}
 	}
 
-	// Step 4: Sort recommendations by score (descending)
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Score > recommendations[j].Score
+	// Adjust scores with price upside when market data is available
+	s.applyPriceFactors(ctx, recommendations)
+
+	// Ties are broken by ticker so the order, and therefore every page of it, is stable
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Ticker < recommendations[j].Ticker
 	})
 
-	// Step 5: Return top 10 recommendations
-	if len(recommendations) > 10 {
-		recommendations = recommendations[:10]
+	if s.scoreNormalization {
+		normalizeScores(recommendations)
+	}
+
+	return recommendations
+}
+
+// normalizeScores sets each NormalizedScore to its Score min-max scaled across the
+// set, so the lowest score maps to 0 and the highest to 1. When every score is the
+// same there is no spread to scale, and all normalize to 1.
+func normalizeScores(recommendations []domain.StockRecommendation) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	lowest, highest := recommendations[0].Score, recommendations[0].Score
+	for _, rec := range recommendations[1:] {
+		lowest = math.Min(lowest, rec.Score)
+		highest = math.Max(highest, rec.Score)
+	}
+
+	spread := highest - lowest
+	for i := range recommendations {
+		normalized := 1.0
+		if spread > 0 {
+			normalized = (recommendations[i].Score - lowest) / spread
+		}
+		recommendations[i].NormalizedScore = &normalized
 	}
+}
 
-	return recommendations, nil
+// recordGeneration exports the candidate and result counts of a completed generation
+func (s *Service) recordGeneration(ctx context.Context, tickers, candidates, recommendations int) {
+	generationsTotal.Inc()
+	lastCandidates.Set(float64(candidates))
+	lastRecommendations.Set(float64(recommendations))
+	s.logger.DebugContext(ctx, "recommendations generated",
+		"tickers", tickers,
+		"candidates", candidates,
+		"recommendations", recommendations,
+	)
 }
 
 // filterPositiveRatings filters stocks with positive analyst ratings
@@ -134,8 +324,9 @@ func (s *Service) isUpgrade(from *string, to *string) bool {
 	return fromExists && toExists && toScore > fromScore
 }
 
-// createBasicRecommendation creates a recommendation based only on analyst rating
-func (s *Service) createBasicRecommendation(rating *domain.StockRating) *domain.StockRecommendation {
+// createBasicRecommendation creates a recommendation based only on analyst rating,
+// with the rationale written for loc
+func (s *Service) createBasicRecommendation(loc locale.Locale, rating *domain.StockRating) *domain.StockRecommendation {
 	baseScore := 0.7 // Base score for positive analyst rating
 
 	// Adjust score based on rating strength
@@ -152,7 +343,7 @@ func (s *Service) createBasicRecommendation(rating *domain.StockRating) *domain.
 
 	// Recent ratings get a small bonus
 	timeBonus := 0.0
-	if time.Since(rating.Time) < 7*24*time.Hour {
+	if s.clock.Now().Sub(rating.Time) < 7*24*time.Hour {
 		timeBonus = 0.05
 	}
 
@@ -162,45 +353,132 @@ func (s *Service) createBasicRecommendation(rating *domain.StockRating) *domain.
 		Ticker:          rating.Ticker,
 		Company:         rating.Company,
 		Score:           finalScore,
-		Rationale:       s.generateBasicRationale(rating),
+		Rationale:       s.generateBasicRationale(loc, rating),
 		LatestRating:    rating.RatingTo,
 		TargetPrice:     rating.TargetTo,
 		TechnicalSignal: "Pending Analysis",
 		SentimentScore:  nil,
-		GeneratedAt:     time.Now(),
+		GeneratedAt:     s.clock.Now(),
 	}
 }
 
-// generateBasicRationale creates a rationale based on analyst rating only
-func (s *Service) generateBasicRationale(rating *domain.StockRating) string {
+// applyPriceFactors adjusts the score and rationale of recommendations that have a
+// target price by comparing it with the current price. It is a no-op without an
+// Alpaca service, and snapshot failures leave the basic recommendations unchanged.
+func (s *Service) applyPriceFactors(ctx context.Context, recommendations []domain.StockRecommendation) {
+	if s.alpacaSvc == nil {
+		return
+	}
+
+	var symbols []string
+	for _, rec := range recommendations {
+		if rec.TargetPrice != nil {
+			symbols = append(symbols, rec.Ticker)
+		}
+	}
+	if len(symbols) == 0 {
+		return
+	}
+	loc := locale.FromContext(ctx)
+
+	snapshots, err := s.alpacaSvc.GetSnapshots(ctx, symbols)
+	if err != nil {
+		s.logger.WarnContext(ctx, "skipping price factors: failed to fetch snapshots", "error", err)
+		return
+	}
+
+	for i := range recommendations {
+		rec := &recommendations[i]
+		if rec.TargetPrice == nil {
+			continue
+		}
+
+		// A zero price would make the upside infinite
+		price, ok := snapshots[rec.Ticker].CurrentPrice()
+		if !ok || price <= 0 {
+			continue
+		}
+
+		upside := (*rec.TargetPrice - price) / price
+		switch {
+		case upside >= 0.2:
+			rec.Score += 0.05
+			rec.Rationale += fmt.Sprintf(", %s upside to target", loc.FormatPercent(upside*100, 1))
+		case upside >= 0:
+			rec.Rationale += fmt.Sprintf(", %s upside to target", loc.FormatPercent(upside*100, 1))
+		default:
+			rec.Score -= 0.1
+			rec.Rationale += ", trading above price target"
+		}
+		rec.Score = math.Max(0, math.Min(1.0, rec.Score))
+	}
+}
+
+// generateBasicRationale creates a rationale based on analyst rating only, writing
+// dates and amounts the way loc expects
+func (s *Service) generateBasicRationale(loc locale.Locale, rating *domain.StockRating) string {
 	var parts []string
 
 	// Analyst rating component
 	parts = append(parts, fmt.Sprintf("Recent %s rating by %s", rating.RatingTo, rating.Brokerage))
 
 	// Add timing context
-	daysSince := int(time.Since(rating.Time).Hours() / 24)
+	daysSince := int(s.clock.Now().Sub(rating.Time).Hours() / 24)
 	if daysSince <= 1 {
 		parts = append(parts, "issued today")
 	} else if daysSince <= 7 {
 		parts = append(parts, fmt.Sprintf("issued %d days ago", daysSince))
+	} else if !rating.Time.IsZero() {
+		parts = append(parts, "issued "+loc.FormatDate(rating.Time))
 	}
 
 	// Add target price context if available
 	if rating.TargetTo != nil {
-		parts = append(parts, fmt.Sprintf("price target $%.2f", *rating.TargetTo))
+		parts = append(parts, "price target "+loc.FormatCurrency(*rating.TargetTo, s.targetCurrency))
 	}
 
 	return strings.Join(parts, ", ")
 }
 
-// GetCachedRecommendations retrieves cached recommendations or generates new ones if cache is stale
+// GetCachedRecommendations retrieves cached recommendations or generates new ones if cache is stale.
+// Recommendations are cached per request locale; a locale first requested while the cache is
+// fresh is generated and added without extending the cache's lifetime.
 func (s *Service) GetCachedRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	if !s.cacheEnabled {
+		return s.GenerateRecommendations(ctx)
+	}
+
+	ranked, err := s.cachedRanked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return topOf(ranked), nil
+}
+
+// GetCachedAllRecommendations returns the complete ranked set from the same cache as
+// GetCachedRecommendations, so pages read while the cache is fresh share one ordering.
+// With caching disabled every call regenerates the set without updating the metrics.
+func (s *Service) GetCachedAllRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	if !s.cacheEnabled {
+		return s.GenerateAllRecommendations(ctx)
+	}
+
+	return s.cachedRanked(ctx)
+}
+
+// cachedRanked returns a copy of the cached ranked set for the request locale, refreshing
+// a stale cache or generating the set for a locale not cached yet
+func (s *Service) cachedRanked(ctx context.Context) ([]domain.StockRecommendation, error) {
+	tag := locale.FromContext(ctx).Tag
+
 	s.cache.mutex.RLock()
 
-	if time.Since(s.cache.lastUpdated) < s.cache.ttl && len(s.cache.recommendations) > 0 {
-		recommendations := make([]domain.StockRecommendation, len(s.cache.recommendations))
-		copy(recommendations, s.cache.recommendations)
+	// A zero lastUpdated means nothing has been computed yet; an empty slice is a valid cached result
+	fresh := !s.cache.lastUpdated.IsZero() && s.clock.Now().Sub(s.cache.lastUpdated) < s.cache.ttl
+	if cached, ok := s.cache.byLocale[tag]; fresh && ok {
+		recommendations := make([]domain.StockRecommendation, len(cached))
+		copy(recommendations, cached)
 		s.cache.mutex.RUnlock()
 
 		return recommendations, nil
@@ -208,47 +486,165 @@ func (s *Service) GetCachedRecommendations(ctx context.Context) ([]domain.StockR
 
 	s.cache.mutex.RUnlock()
 
-	recommendations, err := s.GenerateRecommendations(ctx)
+	if !fresh {
+		return s.refresh(ctx)
+	}
+
+	// Another locale of the current generation; the metrics already describe it
+	ranked, _, err := s.generateRanked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.mutex.Lock()
+	if s.cache.byLocale != nil {
+		s.cache.byLocale[tag] = ranked
+	}
+	s.cache.mutex.Unlock()
+
+	return slices.Clone(ranked), nil
+}
+
+// RefreshRecommendations generates recommendations and replaces the cache regardless of its age,
+// dropping the copies cached for other locales, then publishes them to subscribers. The existing
+// cache is left untouched and nothing is published if generation fails.
+func (s *Service) RefreshRecommendations(ctx context.Context) ([]domain.StockRecommendation, error) {
+	ranked, err := s.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return topOf(ranked), nil
+}
+
+// refresh replaces the cache with a newly generated ranked set and publishes its top
+// recommendations, returning a copy of the full set
+func (s *Service) refresh(ctx context.Context) ([]domain.StockRecommendation, error) {
+	ranked, err := s.generateRecorded(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	s.cache.mutex.Lock()
-	s.cache.recommendations = recommendations
-	s.cache.lastUpdated = time.Now()
+	s.cache.byLocale = map[string][]domain.StockRecommendation{
+		locale.FromContext(ctx).Tag: ranked,
+	}
+	s.cache.lastUpdated = s.clock.Now()
 	s.cache.mutex.Unlock()
 
-	return recommendations, nil
+	s.refreshes.publish(topOf(ranked))
+
+	return slices.Clone(ranked), nil
+}
+
+// Subscribe registers for the recommendation set produced by every cache refresh, including
+// refreshes triggered by GetCachedRecommendations on a stale cache. The returned func
+// unsubscribes and must be called once the subscriber is done; the channel is never closed.
+func (s *Service) Subscribe() (<-chan []domain.StockRecommendation, func()) {
+	return s.refreshes.subscribe()
 }
 
-// analyzeTechnical analyzes historical data and returns technical signal and score
+// analyzeTechnical compares a short and a long simple moving average of the closes in
+// historicalData["data"], oldest first. A short average crossing above the long one within
+// the last crossoverLookback bars is a "Golden Cross" and crossing below is a "Death Cross";
+// the score moves away from 0.5 with the gap between the averages. Anything else is
+// "Sideways", and a series too short for the long average is "Insufficient Data".
 func (s *Service) analyzeTechnical(historicalData map[string]interface{}) (string, float64) {
-	data, exists := historicalData["data"]
-	if !exists {
+	closes, ok := closePrices(historicalData["data"])
+	// One bar beyond the long window gives a previous pair of averages to cross from
+	if !ok || len(closes) <= longMovingAverageWindow {
 		return "Insufficient Data", 0.0
 	}
 
-	dataSlice, ok := data.([]map[string]interface{})
-	if !ok || len(dataSlice) < 2 {
-		return "Insufficient Data", 0.0
+	// sums[i] is the total of the first i closes, so any window average is a subtraction
+	sums := make([]float64, len(closes)+1)
+	for i, price := range closes {
+		sums[i+1] = sums[i] + price
+	}
+	average := func(end, window int) float64 {
+		return (sums[end+1] - sums[end+1-window]) / float64(window)
+	}
+	spread := func(end int) float64 {
+		return average(end, shortMovingAverageWindow) - average(end, longMovingAverageWindow)
 	}
 
-	firstClose, ok1 := dataSlice[0]["close"].(float64)
-	lastClose, ok2 := dataSlice[len(dataSlice)-1]["close"].(float64)
+	last := len(closes) - 1
+	current := spread(last)
+	first := max(longMovingAverageWindow, last-crossoverLookback+1)
+	for end := last; end >= first; end-- {
+		previous, next := spread(end-1), spread(end)
+		crossedUp := previous <= 0 && next > 0
+		crossedDown := previous >= 0 && next < 0
+		if !crossedUp && !crossedDown {
+			continue
+		}
 
-	if !ok1 || !ok2 {
-		return "Insufficient Data", 0.0
+		strength := crossoverStrength(current, average(last, longMovingAverageWindow))
+		switch {
+		case crossedUp && current > 0:
+			return "Golden Cross", 0.5 + crossoverScoreRange*strength
+		case crossedDown && current < 0:
+			return "Death Cross", 0.5 - crossoverScoreRange*strength
+		}
+		// The averages have met again since the most recent crossover
+		break
+	}
+
+	return "Sideways", 0.5
+}
+
+// Moving average windows, in bars, compared by analyzeTechnical
+const (
+	shortMovingAverageWindow = 20
+	longMovingAverageWindow  = 50
+)
+
+const (
+	// crossoverLookback is how many of the latest bars a crossover may be in
+	crossoverLookback = 5
+	// crossoverFullSpread is the gap between the averages, as a fraction of the long
+	// average, at which a crossover reaches its full score
+	crossoverFullSpread = 0.05
+	// crossoverScoreRange is how far a full-strength crossover moves the score from 0.5
+	crossoverScoreRange = 0.3
+)
+
+// crossoverStrength scales the gap between the averages to [0, 1] relative to the long average
+func crossoverStrength(spread, longAverage float64) float64 {
+	if longAverage <= 0 {
+		return 0
 	}
+	return math.Min(math.Abs(spread)/longAverage/crossoverFullSpread, 1)
+}
 
-	percentChange := (lastClose - firstClose) / firstClose * 100
+// closePrices extracts the close of every bar in data, which holds bars as stored by
+// enrichment or as decoded from JSON. It reports false if any bar lacks a numeric close.
+func closePrices(data interface{}) ([]float64, bool) {
+	var bars []map[string]interface{}
+	switch typed := data.(type) {
+	case []map[string]interface{}:
+		bars = typed
+	case []interface{}:
+		for _, item := range typed {
+			bar, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			bars = append(bars, bar)
+		}
+	default:
+		return nil, false
+	}
 
-	if percentChange > 2.0 {
-		return "Golden Cross", 0.8
-	} else if percentChange < -2.0 {
-		return "Death Cross", 0.2
-	} else {
-		return "Sideways", 0.5
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		price, ok := bar["close"].(float64)
+		if !ok {
+			return nil, false
+		}
+		closes[i] = price
 	}
+	return closes, true
 }
 
 // analyzeSentiment analyzes sentiment data and returns normalized score
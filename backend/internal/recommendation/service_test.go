@@ -0,0 +1,797 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	"stock-analyzer/pkg/locale"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStockRepository is a mock implementation of domain.StockRepository
+type MockStockRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockRepository) CreateStockRating(ctx context.Context, rating *domain.StockRating) error {
+	args := m.Called(ctx, rating)
+	return args.Error(0)
+}
+
+func (m *MockStockRepository) UpsertStockRating(ctx context.Context, rating *domain.StockRating) error {
+	args := m.Called(ctx, rating)
+	return args.Error(0)
+}
+
+func (m *MockStockRepository) CreateStockRatingsBatch(ctx context.Context, ratings []*domain.StockRating) (int, error) {
+	args := m.Called(ctx, ratings)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStockRepository) CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ratings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockStockRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
+	args := m.Called(ctx, filters)
+	return args.Get(0).(*domain.PaginatedResponse[domain.StockRating]), args.Error(1)
+}
+
+func (m *MockStockRepository) StreamStockRatings(ctx context.Context, filters domain.FilterOptions, fn func(domain.StockRating) error) error {
+	args := m.Called(ctx, filters)
+	if ratings, ok := args.Get(0).([]domain.StockRating); ok {
+		for _, rating := range ratings {
+			if err := fn(rating); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStockRepository) GetStockRatingsByTicker(ctx context.Context, ticker string) ([]domain.StockRating, error) {
+	args := m.Called(ctx, ticker)
+	return args.Get(0).([]domain.StockRating), args.Error(1)
+}
+
+func (m *MockStockRepository) GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]domain.StockRating, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.StockRating), args.Error(1)
+}
+
+func (m *MockStockRepository) GetUniqueTickers(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockStockRepository) GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockStockRepository) GetRatingDistribution(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockStockRepository) CreateEnrichedStockData(ctx context.Context, data *domain.EnrichedStockData) error {
+	args := m.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (m *MockStockRepository) GetEnrichedStockData(ctx context.Context, ticker string) (*domain.EnrichedStockData, error) {
+	args := m.Called(ctx, ticker)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EnrichedStockData), args.Error(1)
+}
+
+func (m *MockStockRepository) GetLatestRatingsByTicker(ctx context.Context) (map[string]*domain.StockRating, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]*domain.StockRating), args.Error(1)
+}
+
+func (m *MockStockRepository) DeleteOldEnrichedData(ctx context.Context, olderThan time.Time) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockAlpacaService is a mock implementation of AlpacaService
+type MockAlpacaService struct {
+	mock.Mock
+}
+
+func (m *MockAlpacaService) GetHistoricalBars(ctx context.Context, symbol string, timeframe string, start, end time.Time) ([]domain.PriceBar, error) {
+	args := m.Called(ctx, symbol, timeframe, start, end)
+	return args.Get(0).([]domain.PriceBar), args.Error(1)
+}
+
+func (m *MockAlpacaService) GetSnapshot(ctx context.Context, symbol string) (*domain.Snapshot, error) {
+	args := m.Called(ctx, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Snapshot), args.Error(1)
+}
+
+func (m *MockAlpacaService) GetSnapshots(ctx context.Context, symbols []string) (map[string]*domain.Snapshot, error) {
+	args := m.Called(ctx, symbols)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*domain.Snapshot), args.Error(1)
+}
+
+func (m *MockAlpacaService) GetRecentBars(ctx context.Context, symbol string) ([]domain.PriceBar, error) {
+	args := m.Called(ctx, symbol)
+	return args.Get(0).([]domain.PriceBar), args.Error(1)
+}
+
+func (m *MockAlpacaService) IsMarketHours() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func TestGetCachedRecommendations_EmptyDatasetCached(t *testing.T) {
+	t.Log("Testing GetCachedRecommendations: empty dataset is generated once within TTL")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{}, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		recommendations, err := service.GetCachedRecommendations(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, recommendations)
+		assert.NotNil(t, recommendations)
+	}
+
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 1)
+}
+
+func TestGetCachedRecommendations_RegeneratesAfterTTL(t *testing.T) {
+	t.Log("Testing GetCachedRecommendations: regenerates once the TTL expires")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{}, nil)
+
+	_, err := service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+
+	service.cache.lastUpdated = time.Now().Add(-2 * service.cache.ttl)
+
+	_, err = service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 2)
+}
+
+func TestGetCachedRecommendations_CacheDisabled(t *testing.T) {
+	t.Log("Testing GetCachedRecommendations: regenerates on every call when caching is disabled")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, false)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Action: "upgraded by", RatingTo: "Buy"},
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		recommendations, err := service.GetCachedRecommendations(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, recommendations, 1)
+	}
+
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 3)
+	assert.True(t, service.cache.lastUpdated.IsZero())
+}
+
+func TestSubscribe_ReceivesRefreshes(t *testing.T) {
+	t.Log("Testing Subscribe: every subscriber receives refreshed recommendations until it unsubscribes")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Action: "upgraded by", RatingTo: "Buy"},
+	}, nil)
+
+	first, unsubscribeFirst := service.Subscribe()
+	second, unsubscribeSecond := service.Subscribe()
+	defer unsubscribeSecond()
+
+	// A stale cache refreshes through GetCachedRecommendations as well
+	_, err := service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+
+	for _, updates := range []<-chan []domain.StockRecommendation{first, second} {
+		select {
+		case recommendations := <-updates:
+			require.Len(t, recommendations, 1)
+			assert.Equal(t, "AAPL", recommendations[0].Ticker)
+		default:
+			t.Fatal("expected a refresh to be published")
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	assert.Equal(t, 1, service.refreshes.count())
+
+	// Unread refreshes are replaced by the latest instead of blocking the refresh
+	_, err = service.RefreshRecommendations(context.Background())
+	require.NoError(t, err)
+	_, err = service.RefreshRecommendations(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, first)
+	assert.Len(t, second, 1)
+}
+
+func TestSubscribe_NotNotifiedOnFailedRefresh(t *testing.T) {
+	t.Log("Testing Subscribe: a failed refresh publishes nothing")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating(nil), assert.AnError)
+
+	updates, unsubscribe := service.Subscribe()
+	defer unsubscribe()
+
+	_, err := service.RefreshRecommendations(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestGenerateRecommendations_RecordsMetrics(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: updates phase timings and candidate counts")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Action: "upgraded by", RatingTo: "Buy"},
+		"MSFT": {Ticker: "MSFT", Action: "reiterated by", RatingTo: "Outperform"},
+		"XYZ":  {Ticker: "XYZ", Action: "downgraded by", RatingTo: "Sell"},
+	}, nil)
+
+	generationsBefore := testutil.ToFloat64(generationsTotal)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, generationsBefore+1, testutil.ToFloat64(generationsTotal))
+	assert.Equal(t, 2.0, testutil.ToFloat64(lastCandidates))
+	assert.Equal(t, float64(len(recommendations)), testutil.ToFloat64(lastRecommendations))
+	// One histogram series per phase: fetch_latest, filter, scoring
+	assert.Equal(t, 3, testutil.CollectAndCount(generationPhaseDuration))
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func priceFactorRatings() map[string]*domain.StockRating {
+	return map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Brokerage: "Goldman Sachs", Action: "upgraded by", RatingTo: "Buy", TargetTo: float64Ptr(240)},
+		"MSFT": {Ticker: "MSFT", Brokerage: "Morgan Stanley", Action: "reiterated by", RatingTo: "Buy", TargetTo: float64Ptr(380)},
+		"NVDA": {Ticker: "NVDA", Brokerage: "Citi", Action: "initiated by", RatingTo: "Buy"},
+	}
+}
+
+func TestGenerateRecommendations_WithoutAlpacaService(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: nil Alpaca service uses the basic analyst-only path")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(priceFactorRatings(), nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 3)
+
+	for _, rec := range recommendations {
+		assert.InDelta(t, 0.85, rec.Score, 1e-9, rec.Ticker)
+		assert.NotContains(t, rec.Rationale, "upside", rec.Ticker)
+	}
+}
+
+func TestGenerateRecommendations_WithAlpacaService(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: Alpaca snapshots adjust scores by price upside")
+	stockRepo := new(MockStockRepository)
+	alpacaSvc := new(MockAlpacaService)
+	service := NewService(stockRepo, true)
+	service.SetAlpacaService(alpacaSvc)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(priceFactorRatings(), nil)
+	alpacaSvc.On("GetSnapshots", mock.Anything, mock.MatchedBy(func(symbols []string) bool {
+		return assert.ElementsMatch(t, []string{"AAPL", "MSFT"}, symbols)
+	})).Return(map[string]*domain.Snapshot{
+		"AAPL": {Symbol: "AAPL", LatestTrade: &domain.Trade{Price: 200}},
+		// Without a trade the minute bar is more current than the daily bar
+		"MSFT": {Symbol: "MSFT", MinuteBar: &domain.PriceBar{Close: 400}, DailyBar: &domain.PriceBar{Close: 300}},
+	}, nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 3)
+
+	byTicker := make(map[string]domain.StockRecommendation, len(recommendations))
+	for _, rec := range recommendations {
+		byTicker[rec.Ticker] = rec
+	}
+
+	assert.Equal(t, "AAPL", recommendations[0].Ticker)
+	assert.InDelta(t, 0.9, byTicker["AAPL"].Score, 1e-9)
+	assert.Contains(t, byTicker["AAPL"].Rationale, "20.0% upside to target")
+	assert.InDelta(t, 0.75, byTicker["MSFT"].Score, 1e-9)
+	assert.Contains(t, byTicker["MSFT"].Rationale, "trading above price target")
+	assert.InDelta(t, 0.85, byTicker["NVDA"].Score, 1e-9)
+	alpacaSvc.AssertExpectations(t)
+}
+
+func TestGenerateRecommendations_ZeroPriceSkipsUpside(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: a zero snapshot price leaves the score untouched")
+	stockRepo := new(MockStockRepository)
+	alpacaSvc := new(MockAlpacaService)
+	service := NewService(stockRepo, true)
+	service.SetAlpacaService(alpacaSvc)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(priceFactorRatings(), nil)
+	alpacaSvc.On("GetSnapshots", mock.Anything, mock.Anything).Return(map[string]*domain.Snapshot{
+		"AAPL": {Symbol: "AAPL", LatestTrade: &domain.Trade{Price: 0}, DailyBar: &domain.PriceBar{Close: 200}},
+	}, nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	for _, rec := range recommendations {
+		assert.InDelta(t, 0.85, rec.Score, 1e-9, rec.Ticker)
+		assert.NotContains(t, rec.Rationale, "upside", rec.Ticker)
+	}
+}
+
+func TestGenerateRecommendations_SnapshotFailureFallsBack(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: snapshot errors keep the basic scores")
+	stockRepo := new(MockStockRepository)
+	alpacaSvc := new(MockAlpacaService)
+	service := NewService(stockRepo, true)
+	service.SetAlpacaService(alpacaSvc)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(priceFactorRatings(), nil)
+	alpacaSvc.On("GetSnapshots", mock.Anything, mock.Anything).Return(nil, errors.New("alpaca unavailable"))
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 3)
+	for _, rec := range recommendations {
+		assert.InDelta(t, 0.85, rec.Score, 1e-9, rec.Ticker)
+	}
+}
+
+func TestCreateBasicRecommendation_RecencyBonusWithFakeClock(t *testing.T) {
+	t.Log("Testing createBasicRecommendation: recency bonus applies strictly within seven days")
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	service := NewService(new(MockStockRepository), true)
+	service.SetClock(clock.NewFake(now))
+
+	tests := []struct {
+		age       time.Duration
+		score     float64
+		rationale string
+	}{
+		{2 * time.Hour, 0.9, "issued today"},
+		{3 * 24 * time.Hour, 0.9, "issued 3 days ago"},
+		{7*24*time.Hour - time.Second, 0.9, "issued 6 days ago"},
+		{7 * 24 * time.Hour, 0.85, "Recent Buy rating by Citi"},
+		{30 * 24 * time.Hour, 0.85, "Recent Buy rating by Citi"},
+	}
+
+	for _, tt := range tests {
+		rating := &domain.StockRating{Ticker: "AAPL", Brokerage: "Citi", RatingTo: "Buy", Time: now.Add(-tt.age)}
+		rec := service.createBasicRecommendation(locale.Default, rating)
+
+		assert.InDelta(t, tt.score, rec.Score, 1e-9, tt.age.String())
+		assert.Contains(t, rec.Rationale, tt.rationale, tt.age.String())
+		assert.Equal(t, now, rec.GeneratedAt)
+	}
+}
+
+func TestGetCachedRecommendations_TTLWithFakeClock(t *testing.T) {
+	t.Log("Testing GetCachedRecommendations: cache expires exactly at the TTL on the injected clock")
+	stockRepo := new(MockStockRepository)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC))
+	service := NewService(stockRepo, true)
+	service.SetClock(fakeClock)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{}, nil)
+
+	_, err := service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+
+	fakeClock.Advance(service.cache.ttl - time.Second)
+	_, err = service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 1)
+
+	fakeClock.Advance(time.Second)
+	_, err = service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 2)
+}
+
+func TestGenerateRecommendations_LocalizedRationale(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: rationales use the request locale for amounts, percentages and dates")
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	stockRepo := new(MockStockRepository)
+	alpacaSvc := new(MockAlpacaService)
+	service := NewService(stockRepo, true)
+	service.SetClock(clock.NewFake(now))
+	service.SetAlpacaService(alpacaSvc)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Brokerage: "Citi", Action: "upgraded by", RatingTo: "Buy",
+			TargetTo: float64Ptr(1500), Time: time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)},
+	}, nil)
+	alpacaSvc.On("GetSnapshots", mock.Anything, []string{"AAPL"}).Return(map[string]*domain.Snapshot{
+		"AAPL": {Symbol: "AAPL", LatestTrade: &domain.Trade{Price: 1250}},
+	}, nil)
+
+	ctx := locale.WithLocale(context.Background(), locale.Parse("de-DE,de;q=0.9"))
+	recommendations, err := service.GenerateRecommendations(ctx)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Recent Buy rating by Citi, issued 03.02.2025, price target 1.500,00 $, 20,0 % upside to target",
+		recommendations[0].Rationale)
+
+	// Without a locale the rationale keeps the en-US/USD format
+	recommendations, err = service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Recent Buy rating by Citi, issued Feb 3, 2025, price target $1,500.00, 20.0% upside to target",
+		recommendations[0].Rationale)
+}
+
+func TestGenerateRecommendations_TargetCurrency(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: price targets are written in the configured feed currency")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+	service.SetTargetCurrency("eur")
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"SAP": {Ticker: "SAP", Brokerage: "Citi", Action: "upgraded by", RatingTo: "Buy", TargetTo: float64Ptr(210.5)},
+	}, nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Contains(t, recommendations[0].Rationale, "price target €210.50")
+
+	ctx := locale.WithLocale(context.Background(), locale.Parse("fr"))
+	recommendations, err = service.GenerateRecommendations(ctx)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Contains(t, recommendations[0].Rationale, "price target 210,50 €")
+}
+
+func TestGetCachedRecommendations_CachesPerLocale(t *testing.T) {
+	t.Log("Testing GetCachedRecommendations: each locale is cached separately and expires with the cache")
+	stockRepo := new(MockStockRepository)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC))
+	service := NewService(stockRepo, true)
+	service.SetClock(fakeClock)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Brokerage: "Citi", Action: "upgraded by", RatingTo: "Buy", TargetTo: float64Ptr(1500)},
+	}, nil)
+
+	german := locale.WithLocale(context.Background(), locale.Parse("de"))
+
+	english, err := service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+	localized, err := service.GetCachedRecommendations(german)
+	require.NoError(t, err)
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 2)
+
+	assert.Contains(t, english[0].Rationale, "$1,500.00")
+	assert.Contains(t, localized[0].Rationale, "1.500,00 $")
+
+	fakeClock.Advance(service.cache.ttl - time.Second)
+	for _, ctx := range []context.Context{context.Background(), german} {
+		_, err = service.GetCachedRecommendations(ctx)
+		require.NoError(t, err)
+	}
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 2)
+
+	// Adding the German copy did not extend the lifetime of the cache
+	fakeClock.Advance(time.Second)
+	localized, err = service.GetCachedRecommendations(german)
+	require.NoError(t, err)
+	assert.Contains(t, localized[0].Rationale, "1.500,00 $")
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 3)
+}
+
+func TestGenerateAllRecommendations_NormalizesScores(t *testing.T) {
+	t.Log("Testing GenerateAllRecommendations: normalization maps the lowest score to 0 and the highest to 1")
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+	service.SetClock(clock.NewFake(now))
+	service.SetScoreNormalization(true)
+
+	old := now.Add(-30 * 24 * time.Hour)
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Action: "upgraded by", RatingTo: "Strong Buy", Time: now},
+		"MSFT": {Ticker: "MSFT", Action: "reiterated by", RatingTo: "Buy", Time: old},
+		"NVDA": {Ticker: "NVDA", Action: "initiated by", RatingTo: "Outperform", Time: old},
+		"INTC": {Ticker: "INTC", Action: "upgraded by", RatingTo: "Hold", Time: old},
+	}, nil)
+
+	recommendations, err := service.GenerateAllRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recommendations, 4)
+
+	expected := map[string]struct{ raw, normalized float64 }{
+		"AAPL": {0.95, 1},
+		"MSFT": {0.85, 0.6},
+		"NVDA": {0.8, 0.4},
+		"INTC": {0.7, 0},
+	}
+	for _, rec := range recommendations {
+		require.NotNil(t, rec.NormalizedScore, rec.Ticker)
+		assert.InDelta(t, expected[rec.Ticker].raw, rec.Score, 1e-9, rec.Ticker)
+		assert.InDelta(t, expected[rec.Ticker].normalized, *rec.NormalizedScore, 1e-9, rec.Ticker)
+	}
+	assert.Equal(t, "AAPL", recommendations[0].Ticker)
+	assert.Equal(t, "INTC", recommendations[3].Ticker)
+}
+
+func TestGenerateRecommendations_NormalizationDisabled(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: normalized scores are omitted unless enabled")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(priceFactorRatings(), nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, recommendations)
+	for _, rec := range recommendations {
+		assert.Nil(t, rec.NormalizedScore, rec.Ticker)
+	}
+}
+
+func TestNormalizeScores_EqualScores(t *testing.T) {
+	t.Log("Testing normalizeScores: a set without spread normalizes every score to 1")
+	recommendations := []domain.StockRecommendation{{Ticker: "AAPL", Score: 0.85}, {Ticker: "MSFT", Score: 0.85}}
+
+	normalizeScores(recommendations)
+
+	for _, rec := range recommendations {
+		require.NotNil(t, rec.NormalizedScore, rec.Ticker)
+		assert.Equal(t, 1.0, *rec.NormalizedScore, rec.Ticker)
+		assert.Equal(t, 0.85, rec.Score, rec.Ticker)
+	}
+
+	normalizeScores(nil)
+}
+
+func TestGenerateRecommendationsForTickers_Watchlist(t *testing.T) {
+	t.Log("Testing GenerateRecommendationsForTickers: scores only positive ratings in the watchlist")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"AAPL": {Ticker: "AAPL", Brokerage: "Citi", Action: "upgraded by", RatingTo: "Strong Buy"},
+		"MSFT": {Ticker: "MSFT", Brokerage: "Citi", Action: "reiterated by", RatingTo: "Buy"},
+		"XYZ":  {Ticker: "XYZ", Brokerage: "Citi", Action: "downgraded by", RatingTo: "Sell"},
+		"NVDA": {Ticker: "NVDA", Brokerage: "Citi", Action: "upgraded by", RatingTo: "Buy"},
+	}, nil)
+
+	generationsBefore := testutil.ToFloat64(generationsTotal)
+
+	recommendations, err := service.GenerateRecommendationsForTickers(context.Background(), []string{"msft", "AAPL", "XYZ", "UNKNOWN"})
+	require.NoError(t, err)
+
+	require.Len(t, recommendations, 2)
+	assert.Equal(t, "AAPL", recommendations[0].Ticker)
+	assert.Equal(t, "MSFT", recommendations[1].Ticker)
+	assert.Greater(t, recommendations[0].Score, recommendations[1].Score)
+	assert.Equal(t, generationsBefore, testutil.ToFloat64(generationsTotal))
+}
+
+func TestGenerateRecommendationsForTickers_NoMatches(t *testing.T) {
+	t.Log("Testing GenerateRecommendationsForTickers: a watchlist without positive ratings returns an empty list")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(map[string]*domain.StockRating{
+		"XYZ": {Ticker: "XYZ", Action: "downgraded by", RatingTo: "Sell"},
+	}, nil)
+
+	recommendations, err := service.GenerateRecommendationsForTickers(context.Background(), []string{"XYZ"})
+	require.NoError(t, err)
+	assert.NotNil(t, recommendations)
+	assert.Empty(t, recommendations)
+}
+
+func manyPositiveRatings(n int) map[string]*domain.StockRating {
+	ratings := make(map[string]*domain.StockRating, n)
+	for i := 0; i < n; i++ {
+		ticker := fmt.Sprintf("TICK%02d", i)
+		ratings[ticker] = &domain.StockRating{Ticker: ticker, Brokerage: "Citi", Action: "upgraded by", RatingTo: "Buy"}
+	}
+	return ratings
+}
+
+func TestGenerateRecommendations_TruncatesToTop(t *testing.T) {
+	t.Log("Testing GenerateRecommendations: only the top recommendations are returned")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(manyPositiveRatings(25), nil)
+
+	recommendations, err := service.GenerateRecommendations(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, recommendations, topRecommendations)
+}
+
+func TestGenerateAllRecommendations_ReturnsFullRankedList(t *testing.T) {
+	t.Log("Testing GenerateAllRecommendations: every candidate is returned, ordered by score")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	ratings := manyPositiveRatings(25)
+	ratings["TICK07"].RatingTo = "Strong Buy"
+	ratings["XYZ"] = &domain.StockRating{Ticker: "XYZ", Action: "downgraded by", RatingTo: "Sell"}
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(ratings, nil)
+
+	recommendations, err := service.GenerateAllRecommendations(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, recommendations, 25)
+	assert.Equal(t, "TICK07", recommendations[0].Ticker)
+	for i := 1; i < len(recommendations); i++ {
+		assert.GreaterOrEqual(t, recommendations[i-1].Score, recommendations[i].Score)
+	}
+}
+
+func TestGenerateAllRecommendations_TiesOrderedByTicker(t *testing.T) {
+	t.Log("Testing GenerateAllRecommendations: equal scores are ordered by ticker so pages are stable")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(manyPositiveRatings(25), nil)
+
+	recommendations, err := service.GenerateAllRecommendations(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, recommendations, 25)
+	for i, rec := range recommendations {
+		assert.Equal(t, fmt.Sprintf("TICK%02d", i), rec.Ticker)
+	}
+}
+
+func TestGetCachedAllRecommendations_ServedFromCache(t *testing.T) {
+	t.Log("Testing GetCachedAllRecommendations: pages share the cached generation and do not update metrics")
+	stockRepo := new(MockStockRepository)
+	service := NewService(stockRepo, true)
+
+	stockRepo.On("GetLatestRatingsByTicker", mock.Anything).Return(manyPositiveRatings(25), nil).Once()
+
+	top, err := service.GetCachedRecommendations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, top, topRecommendations)
+	generationsBefore := testutil.ToFloat64(generationsTotal)
+
+	for i := 0; i < 2; i++ {
+		all, err := service.GetCachedAllRecommendations(context.Background())
+		require.NoError(t, err)
+		require.Len(t, all, 25)
+		assert.Equal(t, top, all[:topRecommendations])
+	}
+
+	stockRepo.AssertNumberOfCalls(t, "GetLatestRatingsByTicker", 1)
+	assert.Equal(t, generationsBefore, testutil.ToFloat64(generationsTotal))
+	assert.Equal(t, float64(topRecommendations), testutil.ToFloat64(lastRecommendations))
+}
+
+// priceBars builds historical data bars, oldest first, from closing prices
+func priceBars(closes ...float64) []map[string]interface{} {
+	bars := make([]map[string]interface{}, len(closes))
+	for i, price := range closes {
+		bars[i] = map[string]interface{}{"close": price, "volume": 1000.0}
+	}
+	return bars
+}
+
+// priceRun returns count closes starting at start and moving by step each bar
+func priceRun(start, step float64, count int) []float64 {
+	closes := make([]float64, count)
+	for i := range closes {
+		closes[i] = start + step*float64(i)
+	}
+	return closes
+}
+
+func TestAnalyzeTechnical_MovingAverageCrossover(t *testing.T) {
+	t.Log("Testing analyzeTechnical: 20 vs 50 bar moving average crossovers within the last five bars")
+	service := NewService(new(MockStockRepository), true)
+
+	// A flat series leaves the averages equal, so one move on the last bar crosses them;
+	// a final close of 200 gives a spread of 100*(1/20-1/50) = 3 over a long average of 102
+	flatThen := func(last float64) []float64 {
+		return append(priceRun(100, 0, 59), last)
+	}
+	// A decline followed by a sharp rally, and its mirror image
+	valley := func(rally int) []float64 {
+		return append(priceRun(160, -1, 60), priceRun(105, 5, rally)...)
+	}
+	peak := func(selloff int) []float64 {
+		return append(priceRun(100, 1, 60), priceRun(154, -5, selloff)...)
+	}
+
+	tests := []struct {
+		name   string
+		data   map[string]interface{}
+		signal string
+		score  float64
+	}{
+		{"no data", map[string]interface{}{}, "Insufficient Data", 0},
+		{"not a bar list", map[string]interface{}{"data": "bars"}, "Insufficient Data", 0},
+		{"long window only", map[string]interface{}{"data": priceBars(priceRun(100, 1, 50)...)}, "Insufficient Data", 0},
+		{"bar without close", map[string]interface{}{"data": append(priceBars(flatThen(200)...), map[string]interface{}{"open": 1.0})}, "Insufficient Data", 0},
+		{"flat", map[string]interface{}{"data": priceBars(flatThen(100)...)}, "Sideways", 0.5},
+		{"cross up on last bar", map[string]interface{}{"data": priceBars(flatThen(200)...)}, "Golden Cross", 0.5 + 0.3*(3.0/102/0.05)},
+		{"cross down on last bar", map[string]interface{}{"data": priceBars(flatThen(0)...)}, "Death Cross", 0.5 - 0.3*(3.0/98/0.05)},
+		{"wide cross is capped", map[string]interface{}{"data": priceBars(flatThen(1000)...)}, "Golden Cross", 0.8},
+		{"rally crossing up", map[string]interface{}{"data": priceBars(valley(14)...)}, "Golden Cross", 0.668632},
+		{"rally crossed too long ago", map[string]interface{}{"data": priceBars(valley(18)...)}, "Sideways", 0.5},
+		{"selloff crossing down", map[string]interface{}{"data": priceBars(peak(14)...)}, "Death Cross", 0.327815},
+		{"selloff crossed too long ago", map[string]interface{}{"data": priceBars(peak(18)...)}, "Sideways", 0.5},
+		{"steady uptrend", map[string]interface{}{"data": priceBars(priceRun(100, 1, 100)...)}, "Sideways", 0.5},
+	}
+
+	for _, tt := range tests {
+		signal, score := service.analyzeTechnical(tt.data)
+
+		assert.Equal(t, tt.signal, signal, tt.name)
+		assert.InDelta(t, tt.score, score, 1e-6, tt.name)
+	}
+}
+
+func TestAnalyzeTechnical_DecodedJSON(t *testing.T) {
+	t.Log("Testing analyzeTechnical: bars decoded from stored JSON are analyzed like in-memory bars")
+	service := NewService(new(MockStockRepository), true)
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"data": priceBars(append(priceRun(100, 0, 59), 200)...),
+	})
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+
+	signal, score := service.analyzeTechnical(decoded)
+
+	assert.Equal(t, "Golden Cross", signal)
+	assert.InDelta(t, 0.5+0.3*(3.0/102/0.05), score, 1e-6)
+}
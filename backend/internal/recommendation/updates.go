@@ -0,0 +1,60 @@
+package recommendation
+
+import (
+	"sync"
+
+	"stock-analyzer/internal/domain"
+)
+
+// refreshSubscribers fans each refreshed recommendation set out to the subscribers
+// registered through Subscribe
+type refreshSubscribers struct {
+	mutex       sync.Mutex
+	subscribers map[chan []domain.StockRecommendation]struct{}
+}
+
+// subscribe registers a subscriber and returns its channel with the func that removes it
+func (r *refreshSubscribers) subscribe() (<-chan []domain.StockRecommendation, func()) {
+	// A buffer of one holds the latest set while the subscriber is busy
+	updates := make(chan []domain.StockRecommendation, 1)
+
+	r.mutex.Lock()
+	if r.subscribers == nil {
+		r.subscribers = make(map[chan []domain.StockRecommendation]struct{})
+	}
+	r.subscribers[updates] = struct{}{}
+	r.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			r.mutex.Lock()
+			delete(r.subscribers, updates)
+			r.mutex.Unlock()
+		})
+	}
+	return updates, unsubscribe
+}
+
+// publish hands recommendations to every subscriber without blocking. A subscriber
+// that has not taken the previous set yet gets it replaced, so slow readers only
+// ever see the latest refresh.
+func (r *refreshSubscribers) publish(recommendations []domain.StockRecommendation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for updates := range r.subscribers {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- recommendations
+	}
+}
+
+// count reports how many subscribers are registered
+func (r *refreshSubscribers) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.subscribers)
+}
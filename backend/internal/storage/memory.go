@@ -0,0 +1,533 @@
+package storage
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository implements the StockRepository interface with in-process maps for
+// local development and tests. Nothing outlives the process. Filtering, sorting,
+// pagination and conflict handling follow PostgresRepository, so the API behaves the
+// same on either backend; strings sort by byte order rather than a database collation.
+type MemoryRepository struct {
+	mutex            sync.RWMutex
+	clock            clock.Clock
+	logger           *slog.Logger
+	sourcePriorities map[string]int
+
+	// ratings keeps insertion order, which breaks ties when sorting
+	ratings    []*memoryRating
+	byKey      map[string]*memoryRating
+	ids        map[uuid.UUID]bool
+	enriched   map[string]memoryEnrichedData
+	auditLog   []domain.AuditEntry
+	watchlists map[string]map[string]bool
+}
+
+// memoryRating is a stored rating with the priority of the source that wrote it
+type memoryRating struct {
+	rating   domain.StockRating
+	priority int
+}
+
+// memoryEnrichedData keeps enriched data as JSON, as the database does, so reads
+// decode the same types and never share maps with the caller
+type memoryEnrichedData struct {
+	historicalPrices []byte
+	newsSentiment    []byte
+	updatedAt        time.Time
+}
+
+// NewMemoryRepository creates an empty MemoryRepository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		clock:      clock.Real{},
+		logger:     slog.Default(),
+		byKey:      make(map[string]*memoryRating),
+		ids:        make(map[uuid.UUID]bool),
+		enriched:   make(map[string]memoryEnrichedData),
+		watchlists: make(map[string]map[string]bool),
+	}
+}
+
+// SetLogger replaces the logger used by the repository
+func (r *MemoryRepository) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// SetClock replaces the clock that stamps created_at and updated_at
+func (r *MemoryRepository) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// SetSourcePriorities sets each ingestion source's priority, with the same conflict
+// rules as PostgresRepository.SetSourcePriorities
+func (r *MemoryRepository) SetSourcePriorities(priorities map[string]int) {
+	r.sourcePriorities = priorities
+}
+
+// CreateStockRating stores a new stock rating, failing like the database insert when
+// its ID or unique key is already stored
+func (r *MemoryRepository) CreateStockRating(ctx context.Context, rating *domain.StockRating) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkConflict(rating, true); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to create stock rating")
+	}
+
+	r.insert(rating, r.sourcePriorities[rating.Source])
+	return nil
+}
+
+// UpsertStockRating stores a stock rating or replaces the mutable fields of the one
+// stored for the same ticker, brokerage, rating and time. Like the database upsert, a
+// stored rating from a higher-priority source is kept.
+func (r *MemoryRepository) UpsertStockRating(ctx context.Context, rating *domain.StockRating) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	priority := r.sourcePriorities[rating.Source]
+	if stored, ok := r.byKey[rating.ConflictKey()]; ok {
+		if stored.priority <= priority {
+			stored.update(rating)
+			stored.priority = priority
+		}
+		return nil
+	}
+
+	if err := r.checkConflict(rating, false); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to upsert stock rating")
+	}
+
+	r.insert(rating, priority)
+	return nil
+}
+
+// CreateStockRatingsBatch stores multiple stock ratings at once
+func (r *MemoryRepository) CreateStockRatingsBatch(ctx context.Context, ratings []*domain.StockRating) (int, error) {
+	insertedIDs, err := r.CreateStockRatingsBatchReturningIDs(ctx, ratings)
+	return len(insertedIDs), err
+}
+
+// CreateStockRatingsBatchReturningIDs stores multiple stock ratings and returns the IDs
+// of the ones newly inserted. Like the database batch insert, repeated keys within the
+// batch are dropped and a conflicting rating overwrites the stored one only when its
+// source has a higher priority. A batch reusing a stored ID is rejected as a whole.
+func (r *MemoryRepository) CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
+	if len(ratings) == 0 {
+		return nil, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Resolve the batch before writing so a rejected batch leaves nothing behind
+	seen := make(map[string]bool, len(ratings))
+	batchIDs := make(map[uuid.UUID]bool, len(ratings))
+	var accepted []*domain.StockRating
+	for _, rating := range ratings {
+		key := rating.ConflictKey()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, conflict := r.byKey[key]; !conflict {
+			if r.ids[rating.RatingID] || batchIDs[rating.RatingID] {
+				return nil, apperrors.Wrap(errDuplicateRatingID(rating.RatingID), apperrors.ErrCodeDatabase, "failed to insert ratings")
+			}
+			batchIDs[rating.RatingID] = true
+		}
+		accepted = append(accepted, rating)
+	}
+
+	var insertedIDs []uuid.UUID
+	overwrittenCount := 0
+	for _, rating := range accepted {
+		priority := r.sourcePriorities[rating.Source]
+		stored, conflict := r.byKey[rating.ConflictKey()]
+		switch {
+		case !conflict:
+			r.insert(rating, priority)
+			insertedIDs = append(insertedIDs, rating.RatingID)
+		case stored.priority < priority:
+			stored.update(rating)
+			stored.priority = priority
+			overwrittenCount++
+		}
+	}
+
+	r.logger.Info("memory batch insert completed", "attempted", len(ratings), "inserted", len(insertedIDs), "overwritten", overwrittenCount)
+	return insertedIDs, nil
+}
+
+// checkConflict reports a stored rating with the same ID or, when checkKey is set,
+// the same unique key. The caller must hold the mutex.
+func (r *MemoryRepository) checkConflict(rating *domain.StockRating, checkKey bool) error {
+	if r.ids[rating.RatingID] {
+		return errDuplicateRatingID(rating.RatingID)
+	}
+	if _, ok := r.byKey[rating.ConflictKey()]; checkKey && ok {
+		return fmt.Errorf("duplicate key %s", rating.ConflictKey())
+	}
+	return nil
+}
+
+// insert stores a copy of rating stamped with the current time. The caller must hold
+// the mutex and have checked for conflicts.
+func (r *MemoryRepository) insert(rating *domain.StockRating, priority int) {
+	stored := &memoryRating{rating: cloneRating(*rating), priority: priority}
+	stored.rating.CreatedAt = r.clock.Now()
+	// Like the database, reads never return the source
+	stored.rating.Source = ""
+
+	r.ratings = append(r.ratings, stored)
+	r.byKey[rating.ConflictKey()] = stored
+	r.ids[rating.RatingID] = true
+}
+
+// update copies the fields an upsert may change from rating
+func (m *memoryRating) update(rating *domain.StockRating) {
+	updated := cloneRating(*rating)
+	m.rating.Company = updated.Company
+	m.rating.Action = updated.Action
+	m.rating.RatingFrom = updated.RatingFrom
+	m.rating.TargetFrom = updated.TargetFrom
+	m.rating.TargetTo = updated.TargetTo
+}
+
+func errDuplicateRatingID(id uuid.UUID) error {
+	return fmt.Errorf("duplicate rating_id %s", id)
+}
+
+// cloneRating copies a rating together with the values behind its pointer fields
+func cloneRating(rating domain.StockRating) domain.StockRating {
+	if rating.RatingFrom != nil {
+		from := *rating.RatingFrom
+		rating.RatingFrom = &from
+	}
+	if rating.TargetFrom != nil {
+		target := *rating.TargetFrom
+		rating.TargetFrom = &target
+	}
+	if rating.TargetTo != nil {
+		target := *rating.TargetTo
+		rating.TargetTo = &target
+	}
+	return rating
+}
+
+// GetStockRatings retrieves paginated stock ratings with optional filtering
+func (r *MemoryRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
+	page, limit, offset := pageBounds(filters)
+	matching := r.filterRatings(filters)
+
+	var ratings []domain.StockRating
+	if offset < len(matching) {
+		ratings = matching[offset:min(offset+limit, len(matching))]
+	}
+
+	return domain.NewPaginatedResponse(ratings, domain.Pagination{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: len(matching),
+		TotalPages: (len(matching) + limit - 1) / limit,
+	}), nil
+}
+
+// StreamStockRatings invokes fn for every rating matching the filters' search and
+// sort, without pagination. Iteration stops at the first error returned by fn,
+// which is returned unchanged.
+func (r *MemoryRepository) StreamStockRatings(ctx context.Context, filters domain.FilterOptions, fn func(domain.StockRating) error) error {
+	for _, rating := range r.filterRatings(filters) {
+		if err := fn(rating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterRatings returns copies of the ratings matching the search, in the requested
+// order. Search is a case-insensitive substring match on company, ticker and
+// brokerage, like ILIKE; unknown sort fields sort by time.
+func (r *MemoryRepository) filterRatings(filters domain.FilterOptions) []domain.StockRating {
+	search := strings.ToLower(filters.Search)
+	matching := r.selectRatings(func(rating *domain.StockRating) bool {
+		return search == "" ||
+			strings.Contains(strings.ToLower(rating.Company), search) ||
+			strings.Contains(strings.ToLower(rating.Ticker), search) ||
+			strings.Contains(strings.ToLower(rating.Brokerage), search)
+	})
+
+	sortBy := filters.SortBy
+	if _, ok := sortColumnFor(sortBy); !ok {
+		sortBy = "time"
+	}
+	descending := filters.Order != domain.SortAscending
+
+	slices.SortStableFunc(matching, func(a, b domain.StockRating) int {
+		var result int
+		if sortBy == "target_to" && (a.TargetTo == nil || b.TargetTo == nil) {
+			// Missing targets stay last in either direction, like NULLS LAST
+			if result = compareNils(a.TargetTo == nil, b.TargetTo == nil); result != 0 {
+				return result
+			}
+		} else {
+			result = compareRatingField(a, b, sortBy)
+		}
+		if result == 0 {
+			// Ties are broken by ID, like the database's rating_id tiebreaker
+			result = bytes.Compare(a.RatingID[:], b.RatingID[:])
+		}
+		if descending {
+			return -result
+		}
+		return result
+	})
+	return matching
+}
+
+// compareRatingField compares two ratings by a whitelisted sort field
+func compareRatingField(a, b domain.StockRating, field string) int {
+	switch field {
+	case "ticker":
+		return strings.Compare(a.Ticker, b.Ticker)
+	case "company":
+		return strings.Compare(a.Company, b.Company)
+	case "brokerage":
+		return strings.Compare(a.Brokerage, b.Brokerage)
+	case "action":
+		return strings.Compare(a.Action, b.Action)
+	case "rating_to":
+		return strings.Compare(a.RatingTo, b.RatingTo)
+	case "target_to":
+		return cmp.Compare(*a.TargetTo, *b.TargetTo)
+	default:
+		return a.Time.Compare(b.Time)
+	}
+}
+
+// compareNils orders a missing value after a present one
+func compareNils(aMissing, bMissing bool) int {
+	switch {
+	case aMissing == bMissing:
+		return 0
+	case aMissing:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// selectRatings returns copies of the stored ratings accepted by keep, in insertion order
+func (r *MemoryRepository) selectRatings(keep func(*domain.StockRating) bool) []domain.StockRating {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var ratings []domain.StockRating
+	for _, stored := range r.ratings {
+		if keep(&stored.rating) {
+			ratings = append(ratings, cloneRating(stored.rating))
+		}
+	}
+	return ratings
+}
+
+// GetStockRatingsByTicker retrieves all ratings for a specific ticker, newest first
+func (r *MemoryRepository) GetStockRatingsByTicker(ctx context.Context, ticker string) ([]domain.StockRating, error) {
+	ratings := r.selectRatings(func(rating *domain.StockRating) bool {
+		return rating.Ticker == ticker
+	})
+	slices.SortStableFunc(ratings, func(a, b domain.StockRating) int {
+		return b.Time.Compare(a.Time)
+	})
+	return ratings, nil
+}
+
+// GetRatingsByDateRange retrieves all ratings with a time at or after from and
+// before to, oldest first, without pagination
+func (r *MemoryRepository) GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]domain.StockRating, error) {
+	ratings := r.selectRatings(func(rating *domain.StockRating) bool {
+		return !rating.Time.Before(from) && rating.Time.Before(to)
+	})
+	slices.SortStableFunc(ratings, func(a, b domain.StockRating) int {
+		return a.Time.Compare(b.Time)
+	})
+	return ratings, nil
+}
+
+// GetUniqueTickers retrieves all unique ticker symbols in alphabetical order
+func (r *MemoryRepository) GetUniqueTickers(ctx context.Context) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var tickers []string
+	for _, stored := range r.ratings {
+		tickers = append(tickers, stored.rating.Ticker)
+	}
+	slices.Sort(tickers)
+	return slices.Compact(tickers), nil
+}
+
+// GetRatingsCountByBrokerage counts ratings per brokerage
+func (r *MemoryRepository) GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error) {
+	return r.countRatings(func(rating *domain.StockRating) string { return rating.Brokerage }), nil
+}
+
+// GetRatingDistribution counts ratings per target rating (Buy, Hold, Sell, ...)
+func (r *MemoryRepository) GetRatingDistribution(ctx context.Context) (map[string]int, error) {
+	return r.countRatings(func(rating *domain.StockRating) string { return rating.RatingTo }), nil
+}
+
+// countRatings counts the stored ratings grouped by the value group returns
+func (r *MemoryRepository) countRatings(group func(*domain.StockRating) string) map[string]int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	counts := make(map[string]int)
+	for _, stored := range r.ratings {
+		counts[group(&stored.rating)]++
+	}
+	return counts
+}
+
+// CreateEnrichedStockData stores enriched stock data, replacing any stored for the ticker
+func (r *MemoryRepository) CreateEnrichedStockData(ctx context.Context, data *domain.EnrichedStockData) error {
+	histPricesJSON, err := json.Marshal(data.HistoricalPrices)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeValidation, "failed to marshal historical prices")
+	}
+
+	sentimentJSON, err := json.Marshal(data.NewsSentiment)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeValidation, "failed to marshal news sentiment")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.enriched[data.Ticker] = memoryEnrichedData{
+		historicalPrices: histPricesJSON,
+		newsSentiment:    sentimentJSON,
+		updatedAt:        r.clock.Now(),
+	}
+	return nil
+}
+
+// GetEnrichedStockData retrieves enriched data for a ticker
+func (r *MemoryRepository) GetEnrichedStockData(ctx context.Context, ticker string) (*domain.EnrichedStockData, error) {
+	r.mutex.RLock()
+	stored, ok := r.enriched[ticker]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, apperrors.ErrNotFound.WithDetails(fmt.Sprintf("enriched data for ticker %s not found", ticker))
+	}
+
+	data := domain.EnrichedStockData{Ticker: ticker, UpdatedAt: stored.updatedAt}
+	if err := unmarshalNullableJSON(stored.historicalPrices, &data.HistoricalPrices); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to unmarshal historical prices")
+	}
+	if err := unmarshalNullableJSON(stored.newsSentiment, &data.NewsSentiment); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to unmarshal news sentiment")
+	}
+
+	return &data, nil
+}
+
+// GetLatestRatingsByTicker gets the most recent rating for each ticker
+func (r *MemoryRepository) GetLatestRatingsByTicker(ctx context.Context) (map[string]*domain.StockRating, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make(map[string]*domain.StockRating)
+	for _, stored := range r.ratings {
+		if latest, ok := result[stored.rating.Ticker]; ok && !stored.rating.Time.After(latest.Time) {
+			continue
+		}
+		rating := cloneRating(stored.rating)
+		result[rating.Ticker] = &rating
+	}
+	return result, nil
+}
+
+// DeleteOldEnrichedData removes enriched stock data records older than a given time
+func (r *MemoryRepository) DeleteOldEnrichedData(ctx context.Context, olderThan time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for ticker, data := range r.enriched {
+		if data.updatedAt.Before(olderThan) {
+			delete(r.enriched, ticker)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// CreateAuditEntry stores an admin audit entry
+func (r *MemoryRepository) CreateAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.auditLog = append(r.auditLog, *entry)
+	return nil
+}
+
+// AddToWatchlist adds a ticker to a user's watchlist, ignoring tickers already watched
+func (r *MemoryRepository) AddToWatchlist(ctx context.Context, userID, ticker string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.watchlists[userID] == nil {
+		r.watchlists[userID] = make(map[string]bool)
+	}
+	r.watchlists[userID][ticker] = true
+	return nil
+}
+
+// RemoveFromWatchlist removes a ticker from a user's watchlist
+func (r *MemoryRepository) RemoveFromWatchlist(ctx context.Context, userID, ticker string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.watchlists[userID][ticker] {
+		return apperrors.ErrNotFound.WithDetails(fmt.Sprintf("%s is not on the watchlist", ticker))
+	}
+	delete(r.watchlists[userID], ticker)
+	return nil
+}
+
+// GetWatchlist returns a user's watched tickers in alphabetical order
+func (r *MemoryRepository) GetWatchlist(ctx context.Context, userID string) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tickers := []string{}
+	for ticker := range r.watchlists[userID] {
+		tickers = append(tickers, ticker)
+	}
+	slices.Sort(tickers)
+	return tickers, nil
+}
+
+// Ping always succeeds, since the data lives in the process
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRatingFor returns a fresh copy of the nth parity rating
+func memoryRatingFor(n int) *domain.StockRating {
+	rating := parityRatings()[n-1]
+	return &rating
+}
+
+// assertErrorCode checks that err is an AppError with the given code
+func assertErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, code, appErr.Code)
+}
+
+func TestMemoryRepository_CreateConflicts(t *testing.T) {
+	t.Log("Testing MemoryRepository.CreateStockRating: rejects a repeated ID or unique key")
+
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.CreateStockRating(ctx, memoryRatingFor(1)))
+
+	sameID := memoryRatingFor(2)
+	sameID.RatingID = memoryRatingFor(1).RatingID
+	err := repo.CreateStockRating(ctx, sameID)
+	assertErrorCode(t, err, apperrors.ErrCodeDatabase)
+
+	sameKey := memoryRatingFor(1)
+	sameKey.RatingID = uuid.New()
+	err = repo.CreateStockRating(ctx, sameKey)
+	assertErrorCode(t, err, apperrors.ErrCodeDatabase)
+
+	ratings, err := repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Len(t, ratings, 1)
+}
+
+func TestMemoryRepository_Upsert(t *testing.T) {
+	t.Log("Testing MemoryRepository.UpsertStockRating: updates the mutable fields of the stored rating")
+
+	fake := clock.NewFake(parityCreatedAt)
+	repo := NewMemoryRepository()
+	repo.SetClock(fake)
+	ctx := context.Background()
+	require.NoError(t, repo.UpsertStockRating(ctx, memoryRatingFor(1)))
+
+	fake.Advance(time.Hour)
+	updated := memoryRatingFor(1)
+	updated.RatingID = uuid.New()
+	updated.Company = "Apple"
+	updated.TargetTo = float64Ptr(210)
+	require.NoError(t, repo.UpsertStockRating(ctx, updated))
+
+	ratings, err := repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	assert.Equal(t, memoryRatingFor(1).RatingID, ratings[0].RatingID)
+	assert.Equal(t, "Apple", ratings[0].Company)
+	assert.Equal(t, 210.0, *ratings[0].TargetTo)
+	assert.Equal(t, parityCreatedAt, ratings[0].CreatedAt)
+}
+
+func TestMemoryRepository_UpsertSourcePriority(t *testing.T) {
+	t.Log("Testing MemoryRepository.UpsertStockRating: a lower priority source does not replace the stored rating")
+
+	repo := NewMemoryRepository()
+	repo.SetSourcePriorities(map[string]int{"primary": 2, "backfill": 1})
+	ctx := context.Background()
+
+	stored := memoryRatingFor(1)
+	stored.Source = "primary"
+	require.NoError(t, repo.CreateStockRating(ctx, stored))
+
+	lower := memoryRatingFor(1)
+	lower.Source = "backfill"
+	lower.Company = "ignored"
+	require.NoError(t, repo.UpsertStockRating(ctx, lower))
+
+	ratings, err := repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	assert.Equal(t, "Apple Inc.", ratings[0].Company)
+
+	// The stored source may still correct its own rating
+	correction := memoryRatingFor(1)
+	correction.Source = "primary"
+	correction.Company = "Apple"
+	require.NoError(t, repo.UpsertStockRating(ctx, correction))
+
+	ratings, err = repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	assert.Equal(t, "Apple", ratings[0].Company)
+}
+
+func TestMemoryRepository_BatchSourcePriority(t *testing.T) {
+	t.Log("Testing MemoryRepository.CreateStockRatingsBatchReturningIDs: only a higher priority source overwrites")
+
+	repo := NewMemoryRepository()
+	repo.SetSourcePriorities(map[string]int{"primary": 2, "secondary": 1})
+	ctx := context.Background()
+
+	first := memoryRatingFor(1)
+	first.Source = "secondary"
+	ids, err := repo.CreateStockRatingsBatchReturningIDs(ctx, []*domain.StockRating{first, memoryRatingFor(1), memoryRatingFor(2)})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{memoryRatingFor(1).RatingID, memoryRatingFor(2).RatingID}, ids)
+
+	lower := memoryRatingFor(1)
+	lower.RatingID = uuid.New()
+	lower.Company = "ignored"
+	higher := memoryRatingFor(2)
+	higher.RatingID = uuid.New()
+	higher.Source = "primary"
+	higher.Company = "Microsoft"
+	count, err := repo.CreateStockRatingsBatch(ctx, []*domain.StockRating{lower, higher})
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	latest, err := repo.GetLatestRatingsByTicker(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Inc.", latest["AAPL"].Company)
+	assert.Equal(t, "Microsoft", latest["MSFT"].Company)
+	assert.Empty(t, latest["AAPL"].Source)
+}
+
+func TestMemoryRepository_BatchRejectsStoredID(t *testing.T) {
+	t.Log("Testing MemoryRepository.CreateStockRatingsBatch: a batch reusing a stored ID stores nothing")
+
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.CreateStockRating(ctx, memoryRatingFor(1)))
+
+	reused := memoryRatingFor(3)
+	reused.RatingID = memoryRatingFor(1).RatingID
+	_, err := repo.CreateStockRatingsBatch(ctx, []*domain.StockRating{memoryRatingFor(2), reused})
+	assertErrorCode(t, err, apperrors.ErrCodeDatabase)
+
+	tickers, err := repo.GetUniqueTickers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, tickers)
+}
+
+func TestMemoryRepository_SortTiesByID(t *testing.T) {
+	t.Log("Testing MemoryRepository.GetStockRatings: equal sort values are ordered by rating ID")
+
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	for _, n := range []int{5, 3, 1, 2} {
+		require.NoError(t, repo.CreateStockRating(ctx, memoryRatingFor(n)))
+	}
+
+	idsOf := func(page *domain.PaginatedResponse[domain.StockRating]) []uuid.UUID {
+		ids := make([]uuid.UUID, len(page.Data))
+		for i, rating := range page.Data {
+			ids[i] = rating.RatingID
+		}
+		return ids
+	}
+	idsFor := func(numbers ...int) []uuid.UUID {
+		ids := make([]uuid.UUID, len(numbers))
+		for i, n := range numbers {
+			ids[i] = memoryRatingFor(n).RatingID
+		}
+		return ids
+	}
+
+	page, err := repo.GetStockRatings(ctx, domain.FilterOptions{Page: 1, Limit: 10, SortBy: "ticker", Order: domain.SortAscending})
+	require.NoError(t, err)
+	assert.Equal(t, idsFor(1, 3, 2, 5), idsOf(page))
+
+	page, err = repo.GetStockRatings(ctx, domain.FilterOptions{Page: 1, Limit: 10, SortBy: "ticker", Order: domain.SortDescending})
+	require.NoError(t, err)
+	assert.Equal(t, idsFor(5, 2, 3, 1), idsOf(page))
+
+	page, err = repo.GetStockRatings(ctx, domain.FilterOptions{Page: 1, Limit: 10, SortBy: "target_to", Order: domain.SortAscending})
+	require.NoError(t, err)
+	assert.Equal(t, idsFor(1, 2, 3, 5), idsOf(page))
+}
+
+func TestMemoryRepository_CopiesRatings(t *testing.T) {
+	t.Log("Testing MemoryRepository: stored ratings do not alias the caller's values")
+
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	rating := memoryRatingFor(1)
+	require.NoError(t, repo.CreateStockRating(ctx, rating))
+
+	*rating.TargetTo = 1
+	ratings, err := repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, *ratings[0].TargetTo)
+
+	*ratings[0].TargetTo = 2
+	ratings, err = repo.GetStockRatingsByTicker(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, *ratings[0].TargetTo)
+}
+
+func TestMemoryRepository_EnrichedData(t *testing.T) {
+	t.Log("Testing MemoryRepository enriched data: round trips through JSON and expires by updated_at")
+
+	fake := clock.NewFake(parityBase)
+	repo := NewMemoryRepository()
+	repo.SetClock(fake)
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateEnrichedStockData(ctx, &domain.EnrichedStockData{
+		Ticker:           "AAPL",
+		HistoricalPrices: map[string]interface{}{"close": 190},
+	}))
+	fake.Advance(2 * time.Hour)
+	require.NoError(t, repo.CreateEnrichedStockData(ctx, &domain.EnrichedStockData{Ticker: "MSFT"}))
+
+	data, err := repo.GetEnrichedStockData(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"close": 190.0}, data.HistoricalPrices)
+	assert.Nil(t, data.NewsSentiment)
+	assert.Equal(t, parityBase, data.UpdatedAt)
+
+	deleted, err := repo.DeleteOldEnrichedData(ctx, parityBase.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = repo.GetEnrichedStockData(ctx, "AAPL")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	_, err = repo.GetEnrichedStockData(ctx, "MSFT")
+	assert.NoError(t, err)
+}
+
+func TestMemoryRepository_Watchlist(t *testing.T) {
+	t.Log("Testing MemoryRepository watchlists: sorted per user, removing an unwatched ticker is not found")
+
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.AddToWatchlist(ctx, "alice", "MSFT"))
+	require.NoError(t, repo.AddToWatchlist(ctx, "alice", "AAPL"))
+	require.NoError(t, repo.AddToWatchlist(ctx, "alice", "AAPL"))
+
+	tickers, err := repo.GetWatchlist(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, tickers)
+
+	tickers, err = repo.GetWatchlist(ctx, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, tickers)
+
+	require.NoError(t, repo.RemoveFromWatchlist(ctx, "alice", "AAPL"))
+	err = repo.RemoveFromWatchlist(ctx, "alice", "AAPL")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
@@ -0,0 +1,367 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"stock-analyzer/internal/domain"
+	"stock-analyzer/pkg/clock"
+	apperrors "stock-analyzer/pkg/errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The parity suite runs each repository call against MemoryRepository seeded with
+// parityRatings and against PostgresRepository on sqlmock. The mock checks the SQL
+// Postgres would run and answers with the rows the database would return for the
+// same data, so both implementations must produce the same result.
+
+var (
+	parityBase      = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	parityCreatedAt = parityBase.Add(time.Hour)
+)
+
+// ratingSelectColumns are the columns scanned by scanStockRating
+var ratingSelectColumns = []string{
+	"rating_id", "ticker", "company", "brokerage", "action",
+	"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+}
+
+// parityRatings returns the shared fixture, one rating per hour before parityBase
+func parityRatings() []domain.StockRating {
+	rating := func(n int, ticker, company, brokerage, action, ratingTo string, targetTo *float64) domain.StockRating {
+		return domain.StockRating{
+			RatingID:  uuid.MustParse("00000000-0000-0000-0000-00000000000" + string(rune('0'+n))),
+			Ticker:    ticker,
+			Company:   company,
+			Brokerage: brokerage,
+			Action:    action,
+			RatingTo:  ratingTo,
+			TargetTo:  targetTo,
+			Time:      parityBase.Add(-time.Duration(n) * time.Hour),
+			CreatedAt: parityCreatedAt,
+		}
+	}
+
+	return []domain.StockRating{
+		rating(1, "AAPL", "Apple Inc.", "Goldman Sachs", "upgraded by", "Buy", float64Ptr(200)),
+		rating(2, "MSFT", "Microsoft Corp.", "Morgan Stanley", "target raised by", "Overweight", float64Ptr(450)),
+		rating(3, "AAPL", "Apple Inc.", "Morgan Stanley", "reiterated by", "Hold", nil),
+		rating(4, "NVDA", "NVIDIA Corp.", "Goldman Sachs", "initiated by", "Buy", float64Ptr(150)),
+		rating(5, "PINE", "Pineapple Energy", "Jefferies", "downgraded by", "Sell", nil),
+	}
+}
+
+// pickRatings returns the fixture ratings with the given 1-based numbers, in that order
+func pickRatings(numbers ...int) []domain.StockRating {
+	all := parityRatings()
+	picked := make([]domain.StockRating, len(numbers))
+	for i, n := range numbers {
+		picked[i] = all[n-1]
+	}
+	return picked
+}
+
+// ratingRows builds the rows a stock_ratings SELECT returns for ratings
+func ratingRows(ratings []domain.StockRating) *sqlmock.Rows {
+	rows := sqlmock.NewRows(ratingSelectColumns)
+	for _, rating := range ratings {
+		rows.AddRow(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+			rating.Action, columnValue(rating.RatingFrom), rating.RatingTo, columnValue(rating.TargetFrom),
+			columnValue(rating.TargetTo), rating.Time, rating.CreatedAt)
+	}
+	return rows
+}
+
+// columnValue is the column value of a nullable field: NULL when nil
+func columnValue[T any](value *T) driver.Value {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// seededMemoryRepository returns a MemoryRepository holding parityRatings
+func seededMemoryRepository(t *testing.T) *MemoryRepository {
+	repo := NewMemoryRepository()
+	repo.SetClock(clock.NewFake(parityCreatedAt))
+
+	fixture := parityRatings()
+	ratings := make([]*domain.StockRating, len(fixture))
+	for i := range fixture {
+		ratings[i] = &fixture[i]
+	}
+	inserted, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+	require.NoError(t, err)
+	require.Equal(t, len(fixture), inserted)
+	return repo
+}
+
+type parityCase struct {
+	name string
+	// call runs the repository method under test
+	call func(ctx context.Context, repo domain.StockRepository) (any, error)
+	// expectSQL registers the statements Postgres runs and the rows it returns
+	expectSQL func(mock sqlmock.Sqlmock)
+	want      any
+	wantErr   error
+}
+
+func paginated(ratings []domain.StockRating, page, limit, total int) *domain.PaginatedResponse[domain.StockRating] {
+	return domain.NewPaginatedResponse(ratings, domain.Pagination{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+const searchWhere = "WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1)"
+
+var parityCases = []parityCase{
+	{
+		name: "GetStockRatings defaults to newest first",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetStockRatings(ctx, domain.FilterOptions{Page: 1, Limit: 2})
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC LIMIT $1 OFFSET $2`).
+				WithArgs(2, 0).
+				WillReturnRows(ratingRows(pickRatings(1, 2)))
+		},
+		want: paginated(pickRatings(1, 2), 1, 2, 5),
+	},
+	{
+		name: "GetStockRatings searches case-insensitively",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetStockRatings(ctx, domain.FilterOptions{Page: 1, Limit: 20, Search: "apple", SortBy: "time"})
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings " + searchWhere).
+				WithArgs("%apple%").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings `+searchWhere+` ORDER BY "time" DESC, rating_id DESC LIMIT $2 OFFSET $3`).
+				WithArgs("%apple%", 20, 0).
+				WillReturnRows(ratingRows(pickRatings(1, 3, 5)))
+		},
+		want: paginated(pickRatings(1, 3, 5), 1, 20, 3),
+	},
+	{
+		name: "GetStockRatings sorts missing targets last",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetStockRatings(ctx, domain.FilterOptions{Page: 2, Limit: 2, SortBy: "target_to", Order: domain.SortAscending})
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "target_to" ASC NULLS LAST, rating_id ASC LIMIT $1 OFFSET $2`).
+				WithArgs(2, 2).
+				WillReturnRows(ratingRows(pickRatings(2, 3)))
+		},
+		want: paginated(pickRatings(2, 3), 2, 2, 5),
+	},
+	{
+		name: "GetStockRatings returns an empty page past the end",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetStockRatings(ctx, domain.FilterOptions{Page: 4, Limit: 2, SortBy: "unknown"})
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC LIMIT $1 OFFSET $2`).
+				WithArgs(2, 6).
+				WillReturnRows(ratingRows(nil))
+		},
+		want: paginated(nil, 4, 2, 5),
+	},
+	{
+		name: "StreamStockRatings filters and sorts without paging",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			var streamed []domain.StockRating
+			err := repo.StreamStockRatings(ctx, domain.FilterOptions{Limit: 1, Search: "GOLDMAN", SortBy: "ticker", Order: domain.SortAscending},
+				func(rating domain.StockRating) error {
+					streamed = append(streamed, rating)
+					return nil
+				})
+			return streamed, err
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings ` + searchWhere + ` ORDER BY "ticker" ASC, rating_id ASC`).
+				WithArgs("%GOLDMAN%").
+				WillReturnRows(ratingRows(pickRatings(1, 4)))
+		},
+		want: pickRatings(1, 4),
+	},
+	{
+		name: "GetStockRatingsByTicker returns newest first",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetStockRatingsByTicker(ctx, "AAPL")
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings
+		WHERE ticker = $1
+		ORDER BY time DESC`).
+				WithArgs("AAPL").
+				WillReturnRows(ratingRows(pickRatings(1, 3)))
+		},
+		want: pickRatings(1, 3),
+	},
+	{
+		name: "GetRatingsByDateRange is half-open and oldest first",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetRatingsByDateRange(ctx, parityBase.Add(-4*time.Hour), parityBase.Add(-time.Hour))
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from,
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings
+		WHERE time >= $1 AND time < $2
+		ORDER BY time ASC`).
+				WithArgs(parityBase.Add(-4*time.Hour), parityBase.Add(-time.Hour)).
+				WillReturnRows(ratingRows(pickRatings(4, 3, 2)))
+		},
+		want: pickRatings(4, 3, 2),
+	},
+	{
+		name: "GetUniqueTickers is alphabetical",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetUniqueTickers(ctx)
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT DISTINCT ticker FROM stock_ratings ORDER BY ticker").
+				WillReturnRows(sqlmock.NewRows([]string{"ticker"}).AddRow("AAPL").AddRow("MSFT").AddRow("NVDA").AddRow("PINE"))
+		},
+		want: []string{"AAPL", "MSFT", "NVDA", "PINE"},
+	},
+	{
+		name: "GetRatingsCountByBrokerage",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetRatingsCountByBrokerage(ctx)
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT brokerage, COUNT(*) FROM stock_ratings GROUP BY brokerage ORDER BY COUNT(*) DESC").
+				WillReturnRows(sqlmock.NewRows([]string{"brokerage", "count"}).
+					AddRow("Goldman Sachs", 2).AddRow("Morgan Stanley", 2).AddRow("Jefferies", 1))
+		},
+		want: map[string]int{"Goldman Sachs": 2, "Morgan Stanley": 2, "Jefferies": 1},
+	},
+	{
+		name: "GetRatingDistribution",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetRatingDistribution(ctx)
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT rating_to, COUNT(*) FROM stock_ratings GROUP BY rating_to").
+				WillReturnRows(sqlmock.NewRows([]string{"rating_to", "count"}).
+					AddRow("Buy", 2).AddRow("Overweight", 1).AddRow("Hold", 1).AddRow("Sell", 1))
+		},
+		want: map[string]int{"Buy": 2, "Overweight": 1, "Hold": 1, "Sell": 1},
+	},
+	{
+		name: "GetLatestRatingsByTicker keeps the newest per ticker",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetLatestRatingsByTicker(ctx)
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			rows := sqlmock.NewRows([]string{
+				"ticker", "rating_id", "company", "brokerage", "action",
+				"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+			})
+			for _, rating := range pickRatings(1, 2, 4, 5) {
+				rows.AddRow(rating.Ticker, rating.RatingID, rating.Company, rating.Brokerage,
+					rating.Action, columnValue(rating.RatingFrom), rating.RatingTo, columnValue(rating.TargetFrom),
+					columnValue(rating.TargetTo), rating.Time, rating.CreatedAt)
+			}
+			mock.ExpectQuery(`
+		SELECT DISTINCT ON (ticker) ticker, rating_id, company, brokerage, action,
+			   rating_from, rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings
+		ORDER BY ticker, time DESC`).
+				WillReturnRows(rows)
+		},
+		want: func() map[string]*domain.StockRating {
+			latest := make(map[string]*domain.StockRating)
+			for _, rating := range pickRatings(1, 2, 4, 5) {
+				latest[rating.Ticker] = &rating
+			}
+			return latest
+		}(),
+	},
+	{
+		name: "GetEnrichedStockData reports a missing ticker as not found",
+		call: func(ctx context.Context, repo domain.StockRepository) (any, error) {
+			return repo.GetEnrichedStockData(ctx, "AAPL")
+		},
+		expectSQL: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`
+		SELECT ticker, historical_prices, news_sentiment, updated_at
+		FROM enriched_stock_data
+		WHERE ticker = $1`).
+				WithArgs("AAPL").
+				WillReturnError(sql.ErrNoRows)
+		},
+		wantErr: apperrors.ErrNotFound,
+	},
+}
+
+// assertParity checks one implementation's result against the case's expectation
+func assertParity(t *testing.T, tc parityCase, got any, err error) {
+	if tc.wantErr != nil {
+		assert.ErrorIs(t, err, tc.wantErr)
+		return
+	}
+	require.NoError(t, err)
+	assert.Equal(t, tc.want, got)
+}
+
+func TestRepositoryParity_Memory(t *testing.T) {
+	t.Log("Testing MemoryRepository parity: filtering, sorting, pagination and lookups match the shared expectations")
+	for _, tc := range parityCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.call(context.Background(), seededMemoryRepository(t))
+			assertParity(t, tc, got, err)
+		})
+	}
+}
+
+func TestRepositoryParity_Postgres(t *testing.T) {
+	t.Log("Testing PostgresRepository parity: the same calls issue the expected SQL and map rows to the shared expectations")
+	for _, tc := range parityCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, repo := setupMockDB(t)
+			defer db.Close()
+
+			tc.expectSQL(mock)
+			got, err := tc.call(context.Background(), repo)
+			assertParity(t, tc, got, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -5,20 +5,61 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"stock-analyzer/internal/domain"
 	apperrors "stock-analyzer/pkg/errors"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// ratingInsertColumns is the number of bound parameters per stock_ratings row
+const ratingInsertColumns = 12
+
+// maxRowsPerInsert caps the rows in one multi-row INSERT, keeping the bound
+// parameters (rows * ratingInsertColumns) well under Postgres' 65535 limit
+const maxRowsPerInsert = 1000
+
+// pooledChunkSize is how many rows each worker inserts per multi-row statement
+const pooledChunkSize = 500
+
 // PostgresRepository implements the StockRepository interface for PostgreSQL/CockroachDB
 type PostgresRepository struct {
-	db *sql.DB
+	db               *sql.DB
+	logger           *slog.Logger
+	maxWorkers       int
+	sourcePriorities map[string]int
+}
+
+// ratingsQueryer runs a query on either the database or a transaction
+type ratingsQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
 // NewPostgresRepository creates a new PostgresRepository instance
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{db: db, logger: slog.Default()}
+}
+
+// SetLogger replaces the logger used by the repository
+func (r *PostgresRepository) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// SetMaxWorkers sets how many concurrent workers insert large batches.
+// Values below 2 keep the single-transaction insert path.
+func (r *PostgresRepository) SetMaxWorkers(workers int) {
+	r.maxWorkers = workers
+}
+
+// SetSourcePriorities sets each ingestion source's priority. When a batch insert
+// conflicts with a stored rating, the incoming values replace it only if their
+// source has a strictly higher priority; a single upsert also replaces ratings of
+// equal priority. Unlisted sources have priority 0.
+func (r *PostgresRepository) SetSourcePriorities(priorities map[string]int) {
+	r.sourcePriorities = priorities
 }
 
 // CreateStockRating stores a new stock rating
@@ -26,13 +67,13 @@ func (r *PostgresRepository) CreateStockRating(ctx context.Context, rating *doma
 	query := `
 		INSERT INTO stock_ratings (
 			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
 		rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-		rating.TargetTo, rating.Time)
+		rating.TargetTo, rating.Time, rating.Source, r.sourcePriorities[rating.Source])
 
 	if err != nil {
 		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to create stock rating")
@@ -41,54 +82,229 @@ func (r *PostgresRepository) CreateStockRating(ctx context.Context, rating *doma
 	return nil
 }
 
+// UpsertStockRating stores a stock rating or, when one already exists for the same
+// ticker, brokerage, rating and time, replaces its mutable fields so corrected
+// payloads can be replayed. A rating from a lower-priority source than the stored
+// one is left out, as in batch inserts; equal priorities replace so a source can
+// correct its own ratings.
+func (r *PostgresRepository) UpsertStockRating(ctx context.Context, rating *domain.StockRating) error {
+	query := `
+		INSERT INTO stock_ratings (
+			rating_id, ticker, company, brokerage, action, 
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		` + ratingConflictUpdate + "WHERE stock_ratings.source_priority <= EXCLUDED.source_priority"
+
+	_, err := r.db.ExecContext(ctx, query,
+		rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+		rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
+		rating.TargetTo, rating.Time, rating.Source, r.sourcePriorities[rating.Source])
+
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to upsert stock rating")
+	}
+
+	return nil
+}
+
 // CreateStockRatingsBatch stores multiple stock ratings in a single transaction
 func (r *PostgresRepository) CreateStockRatingsBatch(ctx context.Context, ratings []*domain.StockRating) (int, error) {
+	insertedIDs, err := r.CreateStockRatingsBatchReturningIDs(ctx, ratings)
+	return len(insertedIDs), err
+}
+
+// CreateStockRatingsBatchReturningIDs stores multiple stock ratings and returns the
+// IDs of the ones newly inserted, leaving out skipped and overwritten ratings
+func (r *PostgresRepository) CreateStockRatingsBatchReturningIDs(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
 	if len(ratings) == 0 {
-		return 0, nil
+		return nil, nil
+	}
+
+	if r.maxWorkers > 1 && len(ratings) > pooledChunkSize {
+		return r.createStockRatingsPooled(ctx, ratings)
 	}
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to begin transaction")
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to begin transaction")
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`)
+	// Insert in multi-row chunks, collecting new rows apart from overwritten ones
+	var insertedIDs []uuid.UUID
+	overwrittenCount := 0
+	for start := 0; start < len(ratings); start += maxRowsPerInsert {
+		end := start + maxRowsPerInsert
+		if end > len(ratings) {
+			end = len(ratings)
+		}
+
+		inserted, overwritten, err := r.insertRatingsChunk(ctx, tx, ratings[start:end])
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to insert ratings")
+		}
+		insertedIDs = append(insertedIDs, inserted...)
+		overwrittenCount += overwritten
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to commit transaction")
+	}
+
+	r.logger.Info("database batch insert completed", "attempted", len(ratings), "inserted", len(insertedIDs), "overwritten", overwrittenCount)
+	return insertedIDs, nil
+}
+
+// createStockRatingsPooled splits ratings into chunks and inserts them concurrently
+// with multi-row statements. Each chunk commits independently; the priority-gated
+// ON CONFLICT keeps a retried batch idempotent. On failure, the IDs inserted by
+// chunks that already committed are returned with the error.
+func (r *PostgresRepository) createStockRatingsPooled(ctx context.Context, ratings []*domain.StockRating) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg               sync.WaitGroup
+		mutex            sync.Mutex
+		insertedIDs      []uuid.UUID
+		overwrittenCount int
+		firstErr         error
+	)
+
+	semaphore := make(chan struct{}, r.maxWorkers)
+	for start := 0; start < len(ratings); start += pooledChunkSize {
+		end := start + pooledChunkSize
+		if end > len(ratings) {
+			end = len(ratings)
+		}
+
+		wg.Add(1)
+		go func(chunk []*domain.StockRating) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			inserted, overwritten, err := r.insertRatingsChunk(ctx, r.db, chunk)
+			if err != nil {
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mutex.Unlock()
+				return
+			}
+
+			mutex.Lock()
+			insertedIDs = append(insertedIDs, inserted...)
+			overwrittenCount += overwritten
+			mutex.Unlock()
+		}(ratings[start:end])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return insertedIDs, apperrors.Wrap(firstErr, apperrors.ErrCodeDatabase, "failed to insert rating chunk")
+	}
+
+	r.logger.Info("database batch insert completed", "attempted", len(ratings), "inserted", len(insertedIDs), "overwritten", overwrittenCount, "workers", r.maxWorkers)
+	return insertedIDs, nil
+}
+
+// insertRatingsChunk runs one multi-row insert and reports the IDs of the ratings
+// newly stored and how many stored ratings a higher-priority source overwrote.
+// Overwritten rows keep their original rating_id, so a returned ID that was not
+// proposed in this chunk identifies an overwrite.
+func (r *PostgresRepository) insertRatingsChunk(ctx context.Context, q ratingsQueryer, chunk []*domain.StockRating) ([]uuid.UUID, int, error) {
+	query, args := buildRatingsInsert(chunk, r.sourcePriorities)
+
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
-		return 0, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to prepare statement")
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	proposed := make(map[uuid.UUID]bool, len(chunk))
+	for _, rating := range chunk {
+		proposed[rating.RatingID] = true
 	}
-	defer stmt.Close()
 
-	insertedCount := 0
+	var inserted []uuid.UUID
+	overwritten := 0
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, err
+		}
+		if proposed[id] {
+			inserted = append(inserted, id)
+		} else {
+			overwritten++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return inserted, overwritten, nil
+}
+
+// buildRatingsInsert builds a single multi-row INSERT for ratings. A row that
+// conflicts with a stored rating under the unique index replaces it only when its
+// source has a higher priority; otherwise it is skipped. Rows repeating an earlier
+// row's unique key are dropped, since one statement may not update a row twice.
+func buildRatingsInsert(ratings []*domain.StockRating, priorities map[string]int) (string, []interface{}) {
+	var query strings.Builder
+	query.WriteString(`INSERT INTO stock_ratings (rating_id, ticker, company, brokerage, action, rating_from, rating_to, target_from, target_to, time, source, source_priority) VALUES `)
+
+	args := make([]interface{}, 0, len(ratings)*ratingInsertColumns)
+	seen := make(map[string]bool, len(ratings))
+	row := 0
 	for _, rating := range ratings {
-		result, err := stmt.ExecContext(ctx,
-			rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
-			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-			rating.TargetTo, rating.Time)
-		if err != nil {
-			return 0, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to insert rating")
+		key := rating.ConflictKey()
+		if seen[key] {
+			continue
 		}
+		seen[key] = true
 
-		if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
-			insertedCount++
+		if row > 0 {
+			query.WriteString(", ")
 		}
-	}
+		query.WriteString("(")
+		for col := 1; col <= ratingInsertColumns; col++ {
+			if col > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", row*ratingInsertColumns+col)
+		}
+		query.WriteString(")")
+		row++
 
-	if err := tx.Commit(); err != nil {
-		return 0, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to commit transaction")
+		args = append(args,
+			rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
+			rating.TargetTo, rating.Time, rating.Source, priorities[rating.Source])
 	}
 
-	fmt.Printf("Database batch: %d attempted → %d inserted\n", len(ratings), insertedCount)
-	return insertedCount, nil
+	query.WriteString(" " + ratingConflictUpdate + "WHERE stock_ratings.source_priority < EXCLUDED.source_priority RETURNING rating_id")
+	return query.String(), args
 }
 
-// GetStockRatings retrieves paginated stock ratings with optional filtering
-func (r *PostgresRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
+// ratingConflictUpdate replaces the mutable fields and source of a stored rating that
+// conflicts with a written one. Writers append a WHERE clause gating it on source priority.
+const ratingConflictUpdate = "ON CONFLICT (ticker, brokerage, rating_to, time) DO UPDATE SET " +
+	"company = EXCLUDED.company, action = EXCLUDED.action, rating_from = EXCLUDED.rating_from, " +
+	"target_from = EXCLUDED.target_from, target_to = EXCLUDED.target_to, " +
+	"source = EXCLUDED.source, source_priority = EXCLUDED.source_priority "
+
+// pageBounds returns the page, limit and row offset for the filters, defaulting
+// to the first page of 20 when they are out of range
+func pageBounds(filters domain.FilterOptions) (int, int, int) {
 	page := filters.Page
 	if page < 1 {
 		page = 1
@@ -97,42 +313,15 @@ func (r *PostgresRepository) GetStockRatings(ctx context.Context, filters domain
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
-	sortBy := filters.SortBy
-	if sortBy == "" {
-		sortBy = "time"
-	}
-	search := filters.Search
-	offset := (page - 1) * limit
-
-	// Build WHERE clause for search
-	whereClause := ""
-	args := []interface{}{}
-	argCount := 0
-
-	if search != "" {
-		whereClause = "WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1)"
-		args = append(args, "%"+search+"%")
-		argCount = 1
-	}
-
-	// Validate and build ORDER BY clause
-	validSortFields := map[string]bool{
-		"time":      true,
-		"ticker":    true,
-		"company":   true,
-		"brokerage": true,
-	}
-
-	if !validSortFields[sortBy] {
-		sortBy = "time"
-	}
+	return page, limit, (page - 1) * limit
+}
 
-	order := "desc"
-	if !filters.SortDesc {
-		order = "asc"
-	}
+// GetStockRatings retrieves paginated stock ratings with optional filtering
+func (r *PostgresRepository) GetStockRatings(ctx context.Context, filters domain.FilterOptions) (*domain.PaginatedResponse[domain.StockRating], error) {
+	page, limit, offset := pageBounds(filters)
 
-	orderClause := fmt.Sprintf("ORDER BY %s %s", sortBy, strings.ToUpper(order))
+	whereClause, args, orderClause := buildRatingsFilter(filters)
+	argCount := len(args)
 
 	// Get total count
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM stock_ratings %s", whereClause)
@@ -159,11 +348,7 @@ func (r *PostgresRepository) GetStockRatings(ctx context.Context, filters domain
 
 	var ratings []domain.StockRating
 	for rows.Next() {
-		var rating domain.StockRating
-		err := rows.Scan(
-			&rating.RatingID, &rating.Ticker, &rating.Company, &rating.Brokerage,
-			&rating.Action, &rating.RatingFrom, &rating.RatingTo, &rating.TargetFrom,
-			&rating.TargetTo, &rating.Time, &rating.CreatedAt)
+		rating, err := scanStockRating(rows)
 		if err != nil {
 			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan rating")
 		}
@@ -177,17 +362,103 @@ func (r *PostgresRepository) GetStockRatings(ctx context.Context, filters domain
 	// Calculate pagination metadata
 	totalPages := (totalCount + limit - 1) / limit
 
-	response := &domain.PaginatedResponse[domain.StockRating]{
-		Data: ratings,
-		Pagination: domain.Pagination{
-			Page:       page,
-			Limit:      limit,
-			TotalItems: totalCount,
-			TotalPages: totalPages,
-		},
+	return domain.NewPaginatedResponse(ratings, domain.Pagination{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: totalCount,
+		TotalPages: totalPages,
+	}), nil
+}
+
+// StreamStockRatings invokes fn for every rating matching the filters' search and
+// sort, without pagination or materializing the result set. Iteration stops at
+// the first error returned by fn, which is returned unchanged.
+func (r *PostgresRepository) StreamStockRatings(ctx context.Context, filters domain.FilterOptions, fn func(domain.StockRating) error) error {
+	whereClause, args, orderClause := buildRatingsFilter(filters)
+
+	query := fmt.Sprintf(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings %s %s`,
+		whereClause, orderClause)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query stock ratings")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rating, err := scanStockRating(rows)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan rating")
+		}
+
+		if err := fn(rating); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "error iterating over ratings")
 	}
 
-	return response, nil
+	return nil
+}
+
+// defaultSortColumn is used whenever a requested sort field is not whitelisted
+const defaultSortColumn = `"time"`
+
+// sortColumns maps each sortable API field to its quoted stock_ratings column
+var sortColumns = map[string]string{
+	"time":      `"time"`,
+	"ticker":    `"ticker"`,
+	"company":   `"company"`,
+	"brokerage": `"brokerage"`,
+	"action":    `"action"`,
+	"rating_to": `"rating_to"`,
+	"target_to": `"target_to"`,
+}
+
+// nullableSortFields keep missing values at the bottom in either direction
+var nullableSortFields = map[string]bool{
+	"target_to": true,
+}
+
+// sortColumnFor returns the quoted column for a sort field and whether the field
+// is whitelisted. Unknown or empty fields return the default time column, so the
+// result is always safe to interpolate into an ORDER BY clause.
+func sortColumnFor(field string) (string, bool) {
+	column, ok := sortColumns[field]
+	if !ok {
+		return defaultSortColumn, false
+	}
+	return column, true
+}
+
+// buildRatingsFilter builds the WHERE clause, its arguments, and the ORDER BY
+// clause for a ratings query from the search and sort filter options
+func buildRatingsFilter(filters domain.FilterOptions) (string, []interface{}, string) {
+	whereClause := ""
+	args := []interface{}{}
+
+	if filters.Search != "" {
+		whereClause = "WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1)"
+		args = append(args, "%"+filters.Search+"%")
+	}
+
+	// Validate and build ORDER BY clause
+	sortBy := filters.SortBy
+	column, _ := sortColumnFor(sortBy)
+
+	orderClause := fmt.Sprintf("ORDER BY %s %s", column, filters.Order.SQL())
+	if nullableSortFields[sortBy] {
+		orderClause += " NULLS LAST"
+	}
+	// Sort columns repeat values, so rating_id breaks ties to keep pages from overlapping
+	orderClause += ", rating_id " + filters.Order.SQL()
+
+	return whereClause, args, orderClause
 }
 
 // GetStockRatingsByTicker retrieves all ratings for a specific ticker
@@ -207,11 +478,39 @@ func (r *PostgresRepository) GetStockRatingsByTicker(ctx context.Context, ticker
 
 	var ratings []domain.StockRating
 	for rows.Next() {
-		var rating domain.StockRating
-		err := rows.Scan(
-			&rating.RatingID, &rating.Ticker, &rating.Company, &rating.Brokerage,
-			&rating.Action, &rating.RatingFrom, &rating.RatingTo, &rating.TargetFrom,
-			&rating.TargetTo, &rating.Time, &rating.CreatedAt)
+		rating, err := scanStockRating(rows)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan rating")
+		}
+		ratings = append(ratings, rating)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "error iterating over ratings")
+	}
+
+	return ratings, nil
+}
+
+// GetRatingsByDateRange retrieves all ratings with a time at or after from and
+// before to, oldest first, without pagination
+func (r *PostgresRepository) GetRatingsByDateRange(ctx context.Context, from, to time.Time) ([]domain.StockRating, error) {
+	query := `
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings 
+		WHERE time >= $1 AND time < $2 
+		ORDER BY time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query ratings by date range")
+	}
+	defer rows.Close()
+
+	var ratings []domain.StockRating
+	for rows.Next() {
+		rating, err := scanStockRating(rows)
 		if err != nil {
 			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan rating")
 		}
@@ -251,6 +550,60 @@ func (r *PostgresRepository) GetUniqueTickers(ctx context.Context) ([]string, er
 	return tickers, nil
 }
 
+// GetRatingsCountByBrokerage counts ratings per brokerage
+func (r *PostgresRepository) GetRatingsCountByBrokerage(ctx context.Context) (map[string]int, error) {
+	query := "SELECT brokerage, COUNT(*) FROM stock_ratings GROUP BY brokerage ORDER BY COUNT(*) DESC"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query ratings by brokerage")
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var brokerage string
+		var count int
+		if err := rows.Scan(&brokerage, &count); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan brokerage count")
+		}
+		counts[brokerage] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "error iterating over brokerage counts")
+	}
+
+	return counts, nil
+}
+
+// GetRatingDistribution counts ratings per target rating (Buy, Hold, Sell, ...)
+func (r *PostgresRepository) GetRatingDistribution(ctx context.Context) (map[string]int, error) {
+	query := "SELECT rating_to, COUNT(*) FROM stock_ratings GROUP BY rating_to"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query rating distribution")
+	}
+	defer rows.Close()
+
+	distribution := make(map[string]int)
+	for rows.Next() {
+		var rating string
+		var count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan rating count")
+		}
+		distribution[rating] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "error iterating over rating distribution")
+	}
+
+	return distribution, nil
+}
+
 // CreateEnrichedStockData stores enriched stock data
 func (r *PostgresRepository) CreateEnrichedStockData(ctx context.Context, data *domain.EnrichedStockData) error {
 	histPricesJSON, err := json.Marshal(data.HistoricalPrices)
@@ -299,11 +652,11 @@ func (r *PostgresRepository) GetEnrichedStockData(ctx context.Context, ticker st
 		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to get enriched stock data")
 	}
 
-	if err := json.Unmarshal(histPricesJSON, &data.HistoricalPrices); err != nil {
+	if err := unmarshalNullableJSON(histPricesJSON, &data.HistoricalPrices); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to unmarshal historical prices")
 	}
 
-	if err := json.Unmarshal(sentimentJSON, &data.NewsSentiment); err != nil {
+	if err := unmarshalNullableJSON(sentimentJSON, &data.NewsSentiment); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to unmarshal news sentiment")
 	}
 
@@ -327,13 +680,15 @@ func (r *PostgresRepository) GetLatestRatingsByTicker(ctx context.Context) (map[
 	result := make(map[string]*domain.StockRating)
 	for rows.Next() {
 		var rating domain.StockRating
+		var nulls nullableRatingColumns
 		err := rows.Scan(
 			&rating.Ticker, &rating.RatingID, &rating.Company, &rating.Brokerage,
-			&rating.Action, &rating.RatingFrom, &rating.RatingTo, &rating.TargetFrom,
-			&rating.TargetTo, &rating.Time, &rating.CreatedAt)
+			&rating.Action, &nulls.ratingFrom, &rating.RatingTo, &nulls.targetFrom,
+			&nulls.targetTo, &rating.Time, &rating.CreatedAt)
 		if err != nil {
 			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan latest rating")
 		}
+		nulls.applyTo(&rating)
 		result[rating.Ticker] = &rating
 	}
 
@@ -360,3 +715,93 @@ func (r *PostgresRepository) DeleteOldEnrichedData(ctx context.Context, olderTha
 
 	return rowsAffected, nil
 }
+
+// CreateAuditEntry stores an admin audit entry
+func (r *PostgresRepository) CreateAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, target, status, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.Actor, entry.Action, entry.Target, entry.Status, entry.RequestID, entry.Timestamp)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to create audit entry")
+	}
+
+	return nil
+}
+
+// AddToWatchlist adds a ticker to a user's watchlist, ignoring tickers already watched
+func (r *PostgresRepository) AddToWatchlist(ctx context.Context, userID, ticker string) error {
+	query := `
+		INSERT INTO watchlists (user_id, ticker)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, ticker) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, ticker); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to add ticker to watchlist")
+	}
+
+	return nil
+}
+
+// RemoveFromWatchlist removes a ticker from a user's watchlist
+func (r *PostgresRepository) RemoveFromWatchlist(ctx context.Context, userID, ticker string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM watchlists WHERE user_id = $1 AND ticker = $2", userID, ticker)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to remove ticker from watchlist")
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to get affected rows")
+	}
+	if removed == 0 {
+		return apperrors.ErrNotFound.WithDetails(fmt.Sprintf("%s is not on the watchlist", ticker))
+	}
+
+	return nil
+}
+
+// GetWatchlist returns a user's watched tickers in alphabetical order
+func (r *PostgresRepository) GetWatchlist(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT ticker FROM watchlists WHERE user_id = $1 ORDER BY ticker", userID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query watchlist")
+	}
+	defer rows.Close()
+
+	tickers := []string{}
+	for rows.Next() {
+		var ticker string
+		if err := rows.Scan(&ticker); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to scan watchlist ticker")
+		}
+		tickers = append(tickers, ticker)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "error iterating over watchlist")
+	}
+
+	return tickers, nil
+}
+
+// Ping checks that the database is reachable
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to ping database")
+	}
+	return nil
+}
+
+// GetSchemaVersion returns the highest migration version recorded in schema_migrations
+func (r *PostgresRepository) GetSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.ErrCodeDatabase, "failed to query schema version")
+	}
+
+	return version, nil
+}
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -10,6 +12,7 @@ import (
 
 	"stock-analyzer/internal/domain"
 	apperrors "stock-analyzer/pkg/errors"
+	"stock-analyzer/pkg/logging"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -54,11 +57,11 @@ func TestCreateStockRating_Success(t *testing.T) {
 	mock.ExpectExec(`
 		INSERT INTO stock_ratings (
 			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`).
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`).
 		WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
 			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-			rating.TargetTo, rating.Time).
+			rating.TargetTo, rating.Time, rating.Source, 0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CreateStockRating(context.Background(), rating)
@@ -84,11 +87,11 @@ func TestCreateStockRating_DatabaseError(t *testing.T) {
 	mock.ExpectExec(`
 		INSERT INTO stock_ratings (
 			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`).
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`).
 		WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
 			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-			rating.TargetTo, rating.Time).
+			rating.TargetTo, rating.Time, rating.Source, 0).
 		WillReturnError(fmt.Errorf("database connection error"))
 
 	err := repo.CreateStockRating(context.Background(), rating)
@@ -100,6 +103,404 @@ func TestCreateStockRating_DatabaseError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateStockRatingsBatch_ChunkBoundaries(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: splits large batches into multi-row chunks and sums inserted rows")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	ratings := testRatings(2*maxRowsPerInsert + 1)
+	chunks := [][]*domain.StockRating{
+		ratings[:maxRowsPerInsert],
+		ratings[maxRowsPerInsert : 2*maxRowsPerInsert],
+		ratings[2*maxRowsPerInsert:],
+	}
+	affected := []int{maxRowsPerInsert, maxRowsPerInsert - 3, 1}
+
+	mock.ExpectBegin()
+	for i, chunk := range chunks {
+		query, args := buildRatingsInsert(chunk, nil)
+		require.Len(t, args, len(chunk)*ratingInsertColumns)
+		mock.ExpectQuery(query).
+			WithArgs(toDriverValues(args)...).
+			WillReturnRows(returnedIDs(chunk[:affected[i]]...))
+	}
+	mock.ExpectCommit()
+
+	insertedCount, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+	require.NoError(t, err)
+	assert.Equal(t, 2*maxRowsPerInsert-2, insertedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatch_ChunkError(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: a failed chunk rolls back the transaction")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	ratings := testRatings(maxRowsPerInsert + 1)
+	firstQuery, _ := buildRatingsInsert(ratings[:maxRowsPerInsert], nil)
+	secondQuery, _ := buildRatingsInsert(ratings[maxRowsPerInsert:], nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(firstQuery).WillReturnRows(returnedIDs(ratings[:maxRowsPerInsert]...))
+	mock.ExpectQuery(secondQuery).WillReturnError(fmt.Errorf("connection reset"))
+	mock.ExpectRollback()
+
+	insertedCount, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+	assert.Equal(t, 0, insertedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatch_PooledDuplicates(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: pooled path sums inserted rows across chunks")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	repo.SetMaxWorkers(2)
+	mock.MatchExpectationsInOrder(false)
+
+	ratings := testRatings(pooledChunkSize + 2)
+	// The last two rows duplicate earlier ones and are skipped by ON CONFLICT
+	ratings[pooledChunkSize] = ratings[0]
+	ratings[pooledChunkSize+1] = ratings[1]
+
+	firstQuery, firstArgs := buildRatingsInsert(ratings[:pooledChunkSize], nil)
+	secondQuery, secondArgs := buildRatingsInsert(ratings[pooledChunkSize:], nil)
+
+	mock.ExpectQuery(firstQuery).
+		WithArgs(toDriverValues(firstArgs)...).
+		WillReturnRows(returnedIDs(ratings[:pooledChunkSize]...))
+	mock.ExpectQuery(secondQuery).
+		WithArgs(toDriverValues(secondArgs)...).
+		WillReturnRows(returnedIDs())
+
+	insertedCount, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+	require.NoError(t, err)
+	assert.Equal(t, pooledChunkSize, insertedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatch_PooledError(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: pooled path surfaces chunk failures")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	repo.SetMaxWorkers(2)
+	mock.MatchExpectationsInOrder(false)
+
+	ratings := testRatings(pooledChunkSize + 1)
+	firstQuery, _ := buildRatingsInsert(ratings[:pooledChunkSize], nil)
+	secondQuery, _ := buildRatingsInsert(ratings[pooledChunkSize:], nil)
+
+	mock.ExpectQuery(firstQuery).WillReturnError(fmt.Errorf("connection reset"))
+	mock.ExpectQuery(secondQuery).WillReturnRows(returnedIDs(ratings[pooledChunkSize:]...))
+
+	_, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+}
+
+func TestBuildRatingsInsert(t *testing.T) {
+	t.Log("Testing buildRatingsInsert: numbers placeholders per row and gates updates by source priority")
+	ratings := testRatings(2)
+	ratings[1].Source = "primary"
+
+	query, args := buildRatingsInsert(ratings, map[string]int{"primary": 10})
+
+	assert.Equal(t, `INSERT INTO stock_ratings (rating_id, ticker, company, brokerage, action, rating_from, rating_to, target_from, target_to, time, source, source_priority) VALUES `+
+		`($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12), ($13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24) `+
+		`ON CONFLICT (ticker, brokerage, rating_to, time) DO UPDATE SET `+
+		`company = EXCLUDED.company, action = EXCLUDED.action, rating_from = EXCLUDED.rating_from, `+
+		`target_from = EXCLUDED.target_from, target_to = EXCLUDED.target_to, `+
+		`source = EXCLUDED.source, source_priority = EXCLUDED.source_priority `+
+		`WHERE stock_ratings.source_priority < EXCLUDED.source_priority RETURNING rating_id`, query)
+	require.Len(t, args, 2*ratingInsertColumns)
+	assert.Equal(t, ratings[1].RatingID, args[ratingInsertColumns])
+	assert.Equal(t, 0, args[ratingInsertColumns-1], "unlisted sources have priority 0")
+	assert.Equal(t, "primary", args[2*ratingInsertColumns-2])
+	assert.Equal(t, 10, args[2*ratingInsertColumns-1])
+}
+
+func TestBuildRatingsInsert_DropsRepeatedKeys(t *testing.T) {
+	t.Log("Testing buildRatingsInsert: rows repeating a unique key within one statement are dropped")
+	ratings := testRatings(2)
+	repeat := *ratings[0]
+	repeat.RatingID = uuid.New()
+	repeat.Action = "reiterated by"
+	ratings = append(ratings, &repeat)
+
+	_, args := buildRatingsInsert(ratings, nil)
+
+	require.Len(t, args, 2*ratingInsertColumns)
+	assert.Equal(t, ratings[0].RatingID, args[0])
+	assert.Equal(t, ratings[1].RatingID, args[ratingInsertColumns])
+}
+
+func TestCreateStockRatingsBatch_SourcePriority(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: a higher-priority source overwrites a conflicting rating, a lower one does not")
+	priorities := map[string]int{"primary": 10, "backfill": 1}
+
+	cases := []struct {
+		name        string
+		source      string
+		overwritten bool
+	}{
+		{"higher priority overwrites", "primary", true},
+		{"lower priority is skipped", "backfill", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, repo := setupMockDB(t)
+			defer db.Close()
+			repo.SetSourcePriorities(priorities)
+
+			rating := testRatings(1)[0]
+			rating.Source = tc.source
+			query, args := buildRatingsInsert([]*domain.StockRating{rating}, priorities)
+			assert.Equal(t, priorities[tc.source], args[ratingInsertColumns-1])
+
+			// An overwritten row comes back with the stored rating's original ID;
+			// a skipped row is not returned at all
+			returned := sqlmock.NewRows([]string{"rating_id"})
+			if tc.overwritten {
+				returned.AddRow(uuid.New())
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(query).WithArgs(toDriverValues(args)...).WillReturnRows(returned)
+			mock.ExpectCommit()
+
+			insertedCount, err := repo.CreateStockRatingsBatch(context.Background(), []*domain.StockRating{rating})
+			require.NoError(t, err)
+			assert.Equal(t, 0, insertedCount, "conflicting rows are never counted as inserted")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestInsertRatingsChunk_CountsInsertedAndOverwritten(t *testing.T) {
+	t.Log("Testing insertRatingsChunk: returned IDs we proposed are inserts, others are overwrites")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	ratings := testRatings(3)
+	query, _ := buildRatingsInsert(ratings, nil)
+	mock.ExpectQuery(query).WillReturnRows(returnedIDs(ratings[0], ratings[2]).AddRow(uuid.New()))
+
+	inserted, overwritten, err := repo.insertRatingsChunk(context.Background(), db, ratings)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ratings[0].RatingID, ratings[2].RatingID}, inserted)
+	assert.Equal(t, 1, overwritten)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatchReturningIDs_OnlyInserted(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatchReturningIDs: returns only genuinely inserted IDs, not skipped or overwritten ones")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	// ratings[1] is skipped by ON CONFLICT; ratings[2] overwrites a stored row,
+	// which comes back under that row's original ID
+	ratings := testRatings(3)
+	query, args := buildRatingsInsert(ratings, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs(toDriverValues(args)...).
+		WillReturnRows(returnedIDs(ratings[0]).AddRow(uuid.New()))
+	mock.ExpectCommit()
+
+	insertedIDs, err := repo.CreateStockRatingsBatchReturningIDs(context.Background(), ratings)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ratings[0].RatingID}, insertedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatchReturningIDs_AcrossChunks(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatchReturningIDs: collects inserted IDs from every chunk")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	ratings := testRatings(maxRowsPerInsert + 2)
+	firstQuery, firstArgs := buildRatingsInsert(ratings[:maxRowsPerInsert], nil)
+	secondQuery, secondArgs := buildRatingsInsert(ratings[maxRowsPerInsert:], nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(firstQuery).
+		WithArgs(toDriverValues(firstArgs)...).
+		WillReturnRows(returnedIDs(ratings[0]))
+	mock.ExpectQuery(secondQuery).
+		WithArgs(toDriverValues(secondArgs)...).
+		WillReturnRows(returnedIDs(ratings[maxRowsPerInsert+1]))
+	mock.ExpectCommit()
+
+	insertedIDs, err := repo.CreateStockRatingsBatchReturningIDs(context.Background(), ratings)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ratings[0].RatingID, ratings[maxRowsPerInsert+1].RatingID}, insertedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatchReturningIDs_Pooled(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatchReturningIDs: pooled path returns inserted IDs from all chunks")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	repo.SetMaxWorkers(2)
+	mock.MatchExpectationsInOrder(false)
+
+	ratings := testRatings(pooledChunkSize + 2)
+	firstQuery, _ := buildRatingsInsert(ratings[:pooledChunkSize], nil)
+	secondQuery, _ := buildRatingsInsert(ratings[pooledChunkSize:], nil)
+
+	mock.ExpectQuery(firstQuery).WillReturnRows(returnedIDs(ratings[1]))
+	mock.ExpectQuery(secondQuery).WillReturnRows(returnedIDs(ratings[pooledChunkSize:]...))
+
+	insertedIDs, err := repo.CreateStockRatingsBatchReturningIDs(context.Background(), ratings)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{
+		ratings[1].RatingID, ratings[pooledChunkSize].RatingID, ratings[pooledChunkSize+1].RatingID,
+	}, insertedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRatingsBatchReturningIDs_EmptySlice(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatchReturningIDs: an empty batch touches no rows")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	insertedIDs, err := repo.CreateStockRatingsBatchReturningIDs(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, insertedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertStockRating_Success(t *testing.T) {
+	t.Log("Testing UpsertStockRating: updates mutable fields on conflict")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rating := &domain.StockRating{
+		RatingID:   uuid.New(),
+		Ticker:     "AAPL",
+		Company:    "Apple Inc.",
+		Brokerage:  "Goldman Sachs",
+		Action:     "target raised by",
+		RatingFrom: stringPtr("Buy"),
+		RatingTo:   "Buy",
+		TargetFrom: float64Ptr(180.0),
+		TargetTo:   float64Ptr(200.0),
+		Time:       time.Now(),
+	}
+
+	mock.ExpectExec(`
+		INSERT INTO stock_ratings (
+			rating_id, ticker, company, brokerage, action, 
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (ticker, brokerage, rating_to, time) DO UPDATE SET `+
+		"company = EXCLUDED.company, action = EXCLUDED.action, rating_from = EXCLUDED.rating_from, "+
+		"target_from = EXCLUDED.target_from, target_to = EXCLUDED.target_to, "+
+		"source = EXCLUDED.source, source_priority = EXCLUDED.source_priority "+
+		"WHERE stock_ratings.source_priority <= EXCLUDED.source_priority").
+		WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
+			rating.TargetTo, rating.Time, rating.Source, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpsertStockRating(context.Background(), rating)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertStockRating_DatabaseError(t *testing.T) {
+	t.Log("Testing UpsertStockRating: wraps database errors")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rating := &domain.StockRating{
+		RatingID:  uuid.New(),
+		Ticker:    "AAPL",
+		Brokerage: "Goldman Sachs",
+		RatingTo:  "Buy",
+		Time:      time.Now(),
+	}
+
+	mock.ExpectExec(`
+		INSERT INTO stock_ratings (
+			rating_id, ticker, company, brokerage, action, 
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (ticker, brokerage, rating_to, time) DO UPDATE SET ` +
+		"company = EXCLUDED.company, action = EXCLUDED.action, rating_from = EXCLUDED.rating_from, " +
+		"target_from = EXCLUDED.target_from, target_to = EXCLUDED.target_to, " +
+		"source = EXCLUDED.source, source_priority = EXCLUDED.source_priority " +
+		"WHERE stock_ratings.source_priority <= EXCLUDED.source_priority").
+		WillReturnError(fmt.Errorf("database connection error"))
+
+	err := repo.UpsertStockRating(context.Background(), rating)
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStockRating_WritesSourcePriority(t *testing.T) {
+	t.Log("Testing CreateStockRating: stores the rating's source and its configured priority")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	repo.SetSourcePriorities(map[string]int{"primary": 10})
+
+	rating := testRatings(1)[0]
+	rating.Source = "primary"
+
+	mock.ExpectExec(`
+		INSERT INTO stock_ratings (
+			rating_id, ticker, company, brokerage, action, 
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`).
+		WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
+			rating.TargetTo, rating.Time, "primary", 10).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	require.NoError(t, repo.CreateStockRating(context.Background(), rating))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertStockRating_GatedBySourcePriority(t *testing.T) {
+	t.Log("Testing UpsertStockRating: writes the source priority and only replaces ratings of equal or lower priority")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	repo.SetSourcePriorities(map[string]int{"primary": 10, "backfill": 1})
+
+	rating := testRatings(1)[0]
+	rating.Source = "backfill"
+
+	// A conflicting higher-priority row is left alone, so nothing is affected
+	mock.ExpectExec(`
+		INSERT INTO stock_ratings (
+			rating_id, ticker, company, brokerage, action, 
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (ticker, brokerage, rating_to, time) DO UPDATE SET `+
+		"company = EXCLUDED.company, action = EXCLUDED.action, rating_from = EXCLUDED.rating_from, "+
+		"target_from = EXCLUDED.target_from, target_to = EXCLUDED.target_to, "+
+		"source = EXCLUDED.source, source_priority = EXCLUDED.source_priority "+
+		"WHERE stock_ratings.source_priority <= EXCLUDED.source_priority").
+		WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
+			rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
+			rating.TargetTo, rating.Time, "backfill", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.UpsertStockRating(context.Background(), rating))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreateStockRatingsBatch_Success(t *testing.T) {
 	t.Log("Testing CreateStockRatingsBatch: successful batch insert")
 	db, mock, repo := setupMockDB(t)
@@ -126,27 +527,12 @@ func TestCreateStockRatingsBatch_Success(t *testing.T) {
 		},
 	}
 
-	mock.ExpectBegin()
-	mock.ExpectPrepare(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`)
-
-	for _, rating := range ratings {
-		mock.ExpectExec(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`).
-			WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
-				rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-				rating.TargetTo, rating.Time).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-	}
+	query, args := buildRatingsInsert(ratings, nil)
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs(toDriverValues(args)...).
+		WillReturnRows(returnedIDs(ratings...))
 	mock.ExpectCommit()
 
 	insertedCount, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
@@ -155,6 +541,56 @@ func TestCreateStockRatingsBatch_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateStockRatingsBatch_LogLevel(t *testing.T) {
+	t.Log("Testing CreateStockRatingsBatch: info batch logs are suppressed at warn level")
+
+	rating := &domain.StockRating{
+		RatingID:  uuid.New(),
+		Ticker:    "AAPL",
+		Company:   "Apple Inc.",
+		Brokerage: "Goldman Sachs",
+		Action:    "upgraded by",
+		RatingTo:  "Buy",
+		Time:      time.Now(),
+	}
+
+	insertQuery, _ := buildRatingsInsert([]*domain.StockRating{rating}, nil)
+
+	for _, tc := range []struct {
+		level     string
+		expectLog bool
+	}{
+		{"info", true},
+		{"warn", false},
+	} {
+		t.Run(tc.level, func(t *testing.T) {
+			db, mock, repo := setupMockDB(t)
+			defer db.Close()
+
+			var buf bytes.Buffer
+			repo.SetLogger(logging.New(tc.level, &buf))
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(insertQuery).WillReturnRows(returnedIDs(rating))
+			mock.ExpectCommit()
+
+			_, err := repo.CreateStockRatingsBatch(context.Background(), []*domain.StockRating{rating})
+			require.NoError(t, err)
+
+			if tc.expectLog {
+				var entry map[string]interface{}
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+				assert.Equal(t, "INFO", entry["level"])
+				assert.Equal(t, "database batch insert completed", entry["msg"])
+				assert.Equal(t, float64(1), entry["inserted"])
+			} else {
+				assert.Empty(t, buf.String())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestCreateStockRatingsBatch_EmptySlice(t *testing.T) {
 	t.Log("Testing CreateStockRatingsBatch: handles empty input slice")
 	db, mock, repo := setupMockDB(t)
@@ -221,37 +657,13 @@ func TestCreateStockRatingsBatch_DuplicateHandling(t *testing.T) {
 		},
 	}
 
-	mock.ExpectBegin()
-	mock.ExpectPrepare(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`)
-
-	// First insert succeeds
-	mock.ExpectExec(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`).
-		WithArgs(ratings[0].RatingID, ratings[0].Ticker, ratings[0].Company,
-			ratings[0].Brokerage, ratings[0].Action, ratings[0].RatingFrom,
-			ratings[0].RatingTo, ratings[0].TargetFrom, ratings[0].TargetTo, ratings[0].Time).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	query, args := buildRatingsInsert(ratings, nil)
 
-	// Second insert is ignored due to conflict
-	mock.ExpectExec(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`).
-		WithArgs(ratings[1].RatingID, ratings[1].Ticker, ratings[1].Company,
-			ratings[1].Brokerage, ratings[1].Action, ratings[1].RatingFrom,
-								ratings[1].RatingTo, ratings[1].TargetFrom, ratings[1].TargetTo, ratings[1].Time).
-		WillReturnResult(sqlmock.NewResult(0, 0)) // No rows affected due to conflict
+	// The second row conflicts with the first, so only one row is returned
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs(toDriverValues(args)...).
+		WillReturnRows(returnedIDs(ratings[0]))
 
 	mock.ExpectCommit()
 
@@ -298,11 +710,11 @@ func TestGetStockRatings_Success(t *testing.T) {
 	mock.ExpectQuery(`
 		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
 			   rating_to, target_from, target_to, time, created_at
-		FROM stock_ratings  ORDER BY time DESC LIMIT $1 OFFSET $2`).
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC LIMIT $1 OFFSET $2`).
 		WithArgs(20, 0).
 		WillReturnRows(rows)
 
-	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", SortDesc: true}
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", Order: domain.SortDescending}
 	response, err := repo.GetStockRatings(context.Background(), filters)
 
 	assert.NoError(t, err)
@@ -334,11 +746,11 @@ func TestGetStockRatings_WithSearch(t *testing.T) {
 	mock.ExpectQuery(`
 		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
 			   rating_to, target_from, target_to, time, created_at
-		FROM stock_ratings WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1) ORDER BY time DESC LIMIT $2 OFFSET $3`).
+		FROM stock_ratings WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1) ORDER BY "time" DESC, rating_id DESC LIMIT $2 OFFSET $3`).
 		WithArgs("%Apple%", 20, 0).
 		WillReturnRows(rows)
 
-	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", SortDesc: true, Search: searchTerm}
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", Order: domain.SortDescending, Search: searchTerm}
 	response, err := repo.GetStockRatings(context.Background(), filters)
 
 	assert.NoError(t, err)
@@ -361,7 +773,7 @@ func TestGetStockRatings_InvalidSortField(t *testing.T) {
 	mock.ExpectQuery(`
 		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
 			   rating_to, target_from, target_to, time, created_at
-		FROM stock_ratings  ORDER BY time DESC LIMIT $1 OFFSET $2`).
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC LIMIT $1 OFFSET $2`).
 		WithArgs(20, 0).
 		WillReturnRows(sqlmock.NewRows([]string{
 			"rating_id", "ticker", "company", "brokerage", "action",
@@ -369,15 +781,89 @@ func TestGetStockRatings_InvalidSortField(t *testing.T) {
 		}))
 
 	// Try to sort by invalid field
-	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "invalid_field", SortDesc: true}
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "invalid_field", Order: domain.SortDescending}
 	response, err := repo.GetStockRatings(context.Background(), filters)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0, response.Pagination.TotalItems)
+	assert.NotNil(t, response.Data, "empty pages must not leave data nil")
 	assert.Len(t, response.Data, 0)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetStockRatings_SortByTargetToNullsLast(t *testing.T) {
+	t.Log("Testing GetStockRatings: sorting by target_to keeps null targets last")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "target_to" ASC NULLS LAST, rating_id ASC LIMIT $1 OFFSET $2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"rating_id", "ticker", "company", "brokerage", "action",
+			"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+		}))
+
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "target_to", Order: domain.SortAscending}
+	_, err := repo.GetStockRatings(context.Background(), filters)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildRatingsFilter_SortFields(t *testing.T) {
+	t.Log("Testing buildRatingsFilter: ORDER BY clause for each sort field")
+
+	cases := []struct {
+		sortBy   string
+		order    domain.SortOrder
+		expected string
+	}{
+		{"target_to", domain.SortDescending, `ORDER BY "target_to" DESC NULLS LAST, rating_id DESC`},
+		{"target_to", domain.SortAscending, `ORDER BY "target_to" ASC NULLS LAST, rating_id ASC`},
+		{"rating_to", domain.SortDescending, `ORDER BY "rating_to" DESC, rating_id DESC`},
+		{"action", domain.SortAscending, `ORDER BY "action" ASC, rating_id ASC`},
+		{"ticker", domain.SortAscending, `ORDER BY "ticker" ASC, rating_id ASC`},
+		{"ticker", "", `ORDER BY "ticker" DESC, rating_id DESC`},
+		{"target_to; DROP TABLE stock_ratings", domain.SortDescending, `ORDER BY "time" DESC, rating_id DESC`},
+	}
+
+	for _, tc := range cases {
+		_, _, orderClause := buildRatingsFilter(domain.FilterOptions{SortBy: tc.sortBy, Order: tc.order})
+		assert.Equal(t, tc.expected, orderClause, "sort_by=%q order=%q", tc.sortBy, tc.order)
+	}
+}
+
+func TestSortColumnFor(t *testing.T) {
+	t.Log("Testing sortColumnFor: whitelisted fields map to quoted columns, others fall back to time")
+
+	cases := []struct {
+		field  string
+		column string
+		ok     bool
+	}{
+		{"time", `"time"`, true},
+		{"ticker", `"ticker"`, true},
+		{"target_to", `"target_to"`, true},
+		{"rating_to", `"rating_to"`, true},
+		{"", `"time"`, false},
+		{"updated_at", `"time"`, false},
+		{"TICKER", `"time"`, false},
+		{`ticker"; DROP TABLE stock_ratings; --`, `"time"`, false},
+	}
+
+	for _, tc := range cases {
+		column, ok := sortColumnFor(tc.field)
+		assert.Equal(t, tc.column, column, "field %q", tc.field)
+		assert.Equal(t, tc.ok, ok, "field %q", tc.field)
+	}
+}
+
 func TestGetStockRatings_CountError(t *testing.T) {
 	t.Log("Testing GetStockRatings: handles error during count query")
 	db, mock, repo := setupMockDB(t)
@@ -386,7 +872,7 @@ func TestGetStockRatings_CountError(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT(*) FROM stock_ratings ").
 		WillReturnError(fmt.Errorf("count query error"))
 
-	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", SortDesc: true}
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", Order: domain.SortDescending}
 	response, err := repo.GetStockRatings(context.Background(), filters)
 
 	assert.Error(t, err)
@@ -481,6 +967,72 @@ func TestGetStockRatingsByTicker_DatabaseError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetRatingsByDateRange_Success(t *testing.T) {
+	t.Log("Testing GetRatingsByDateRange: returns every rating in the window, oldest first")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{
+		"rating_id", "ticker", "company", "brokerage", "action",
+		"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+	}).
+		AddRow(uuid.New(), "AAPL", "Apple Inc.", "Goldman Sachs", "upgraded by",
+			"Hold", "Buy", 150.0, 180.0, from.Add(24*time.Hour), time.Now()).
+		AddRow(uuid.New(), "MSFT", "Microsoft Corp.", "Morgan Stanley", "initiated by",
+			nil, "Overweight", nil, nil, from.Add(48*time.Hour), time.Now())
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings 
+		WHERE time >= $1 AND time < $2 
+		ORDER BY time ASC`).
+		WithArgs(from, to).
+		WillReturnRows(rows)
+
+	ratings, err := repo.GetRatingsByDateRange(context.Background(), from, to)
+
+	assert.NoError(t, err)
+	require.Len(t, ratings, 2)
+	assert.Equal(t, "AAPL", ratings[0].Ticker)
+	assert.Equal(t, stringPtr("Hold"), ratings[0].RatingFrom)
+	assert.Equal(t, float64Ptr(180.0), ratings[0].TargetTo)
+	assert.Equal(t, "MSFT", ratings[1].Ticker)
+	assert.Nil(t, ratings[1].RatingFrom)
+	assert.Nil(t, ratings[1].TargetTo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatingsByDateRange_Empty(t *testing.T) {
+	t.Log("Testing GetRatingsByDateRange: empty window returns no ratings")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings 
+		WHERE time >= $1 AND time < $2 
+		ORDER BY time ASC`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"rating_id", "ticker", "company", "brokerage", "action",
+			"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+		}))
+
+	ratings, err := repo.GetRatingsByDateRange(context.Background(), from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, ratings, 0)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetUniqueTickers_Success(t *testing.T) {
 	t.Log("Testing GetUniqueTickers: successful retrieval of unique tickers")
 	db, mock, repo := setupMockDB(t)
@@ -503,6 +1055,77 @@ func TestGetUniqueTickers_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetRatingsCountByBrokerage_Success(t *testing.T) {
+	t.Log("Testing GetRatingsCountByBrokerage: counts ratings per brokerage")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"brokerage", "count"}).
+		AddRow("Goldman Sachs", 42).
+		AddRow("Morgan Stanley", 17)
+
+	mock.ExpectQuery("SELECT brokerage, COUNT(*) FROM stock_ratings GROUP BY brokerage ORDER BY COUNT(*) DESC").
+		WillReturnRows(rows)
+
+	counts, err := repo.GetRatingsCountByBrokerage(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Goldman Sachs": 42, "Morgan Stanley": 17}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatingsCountByBrokerage_DatabaseError(t *testing.T) {
+	t.Log("Testing GetRatingsCountByBrokerage: wraps query errors")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT brokerage, COUNT(*) FROM stock_ratings GROUP BY brokerage ORDER BY COUNT(*) DESC").
+		WillReturnError(fmt.Errorf("connection refused"))
+
+	_, err := repo.GetRatingsCountByBrokerage(context.Background())
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatingDistribution_Success(t *testing.T) {
+	t.Log("Testing GetRatingDistribution: counts ratings per rating_to")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"rating_to", "count"}).
+		AddRow("Buy", 120).
+		AddRow("Hold", 45).
+		AddRow("Sell", 8)
+
+	mock.ExpectQuery("SELECT rating_to, COUNT(*) FROM stock_ratings GROUP BY rating_to").
+		WillReturnRows(rows)
+
+	distribution, err := repo.GetRatingDistribution(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Buy": 120, "Hold": 45, "Sell": 8}, distribution)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatingDistribution_EmptyTable(t *testing.T) {
+	t.Log("Testing GetRatingDistribution: an empty table yields an empty, non-nil map")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT rating_to, COUNT(*) FROM stock_ratings GROUP BY rating_to").
+		WillReturnRows(sqlmock.NewRows([]string{"rating_to", "count"}))
+
+	distribution, err := repo.GetRatingDistribution(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotNil(t, distribution)
+	assert.Empty(t, distribution)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreateEnrichedStockData_Success(t *testing.T) {
 	t.Log("Testing CreateEnrichedStockData: successful creation")
 	db, mock, repo := setupMockDB(t)
@@ -649,28 +1272,33 @@ func BenchmarkCreateStockRatingsBatch(b *testing.B) {
 
 	// Mock expectations for each benchmark iteration
 	for i := 0; i < b.N; i++ {
+		query, _ := buildRatingsInsert(ratings, nil)
 		mock.ExpectBegin()
-		mock.ExpectPrepare(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`)
+		mock.ExpectQuery(query).WillReturnRows(returnedIDs(ratings...))
+		mock.ExpectCommit()
+	}
 
-		for _, rating := range ratings {
-			mock.ExpectExec(`
-		INSERT INTO stock_ratings (
-			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (ticker, brokerage, rating_to, time) DO NOTHING`).
-				WithArgs(rating.RatingID, rating.Ticker, rating.Company, rating.Brokerage,
-					rating.Action, rating.RatingFrom, rating.RatingTo, rating.TargetFrom,
-					rating.TargetTo, rating.Time).
-				WillReturnResult(sqlmock.NewResult(1, 1))
-		}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := repo.CreateStockRatingsBatch(context.Background(), ratings)
+		require.NoError(b, err)
+	}
+}
 
-		mock.ExpectCommit()
+func BenchmarkCreateStockRatingsBatch_Pooled(b *testing.B) {
+	b.Log("Benchmarking CreateStockRatingsBatch with the worker pool")
+	db, mock, repo := setupMockDBForBenchmark(b)
+	defer db.Close()
+	repo.SetMaxWorkers(4)
+	mock.MatchExpectationsInOrder(false)
+
+	ratings := testRatings(1000)
+	for i := 0; i < b.N; i++ {
+		for start := 0; start < len(ratings); start += pooledChunkSize {
+			chunk := ratings[start : start+pooledChunkSize]
+			query, _ := buildRatingsInsert(chunk, nil)
+			mock.ExpectQuery(query).WillReturnRows(returnedIDs(chunk...))
+		}
 	}
 
 	b.ResetTimer()
@@ -704,12 +1332,12 @@ func BenchmarkGetStockRatings(b *testing.B) {
 		mock.ExpectQuery(`
 		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
 			   rating_to, target_from, target_to, time, created_at
-		FROM stock_ratings  ORDER BY time DESC LIMIT $1 OFFSET $2`).
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC LIMIT $1 OFFSET $2`).
 			WithArgs(20, 0).
 			WillReturnRows(rows)
 	}
 
-	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", SortDesc: true}
+	filters := domain.FilterOptions{Page: 1, Limit: 20, SortBy: "time", Order: domain.SortDescending}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := repo.GetStockRatings(context.Background(), filters)
@@ -731,8 +1359,8 @@ func TestConcurrentCreateStockRating(t *testing.T) {
 		mock.ExpectExec(`
 		INSERT INTO stock_ratings (
 			rating_id, ticker, company, brokerage, action, 
-			rating_from, rating_to, target_from, target_to, time
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`).
+			rating_from, rating_to, target_from, target_to, time, source, source_priority
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 	}
 
@@ -762,6 +1390,116 @@ func TestConcurrentCreateStockRating(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestStreamStockRatings_InvokesCallbackPerRow(t *testing.T) {
+	t.Log("Testing StreamStockRatings: invokes the callback once per row")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"rating_id", "ticker", "company", "brokerage", "action",
+		"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+	})
+	tickers := []string{"AAPL", "GOOGL", "MSFT"}
+	for _, ticker := range tickers {
+		rows.AddRow(uuid.New(), ticker, ticker+" Inc.", "Goldman Sachs", "upgraded by",
+			nil, "Buy", nil, float64Ptr(180.0), time.Now(), time.Now())
+	}
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings WHERE (company ILIKE $1 OR ticker ILIKE $1 OR brokerage ILIKE $1) ORDER BY "ticker" ASC, rating_id ASC`).
+		WithArgs("%Inc%").
+		WillReturnRows(rows)
+
+	var streamed []string
+	filters := domain.FilterOptions{Search: "Inc", SortBy: "ticker", Order: domain.SortAscending}
+	err := repo.StreamStockRatings(context.Background(), filters, func(rating domain.StockRating) error {
+		streamed = append(streamed, rating.Ticker)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, tickers, streamed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamStockRatings_CallbackErrorStopsIteration(t *testing.T) {
+	t.Log("Testing StreamStockRatings: a callback error aborts iteration")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"rating_id", "ticker", "company", "brokerage", "action",
+		"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+	})
+	for _, ticker := range []string{"AAPL", "GOOGL", "MSFT"} {
+		rows.AddRow(uuid.New(), ticker, ticker+" Inc.", "Goldman Sachs", "upgraded by",
+			nil, "Buy", nil, nil, time.Now(), time.Now())
+	}
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC`).
+		WillReturnRows(rows)
+
+	stop := fmt.Errorf("client went away")
+	calls := 0
+	err := repo.StreamStockRatings(context.Background(), domain.FilterOptions{Order: domain.SortDescending}, func(rating domain.StockRating) error {
+		calls++
+		if rating.Ticker == "GOOGL" {
+			return stop
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 2, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamStockRatings_QueryError(t *testing.T) {
+	t.Log("Testing StreamStockRatings: wraps query errors")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings  ORDER BY "time" DESC, rating_id DESC`).
+		WillReturnError(fmt.Errorf("connection refused"))
+
+	err := repo.StreamStockRatings(context.Background(), domain.FilterOptions{Order: domain.SortDescending}, func(domain.StockRating) error {
+		t.Fatal("callback should not be invoked")
+		return nil
+	})
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+}
+
+func TestPing(t *testing.T) {
+	t.Log("Testing Ping: reports database reachability")
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db)
+
+	mock.ExpectPing()
+	assert.NoError(t, repo.Ping(context.Background()))
+
+	mock.ExpectPing().WillReturnError(fmt.Errorf("connection refused"))
+	err = repo.Ping(context.Background())
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // Helper functions
 func stringPtr(s string) *string {
 	return &s
@@ -770,3 +1508,146 @@ func stringPtr(s string) *string {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+func testRatings(n int) []*domain.StockRating {
+	ratings := make([]*domain.StockRating, n)
+	for i := range ratings {
+		ratings[i] = &domain.StockRating{
+			RatingID:  uuid.New(),
+			Ticker:    fmt.Sprintf("TICK%d", i),
+			Company:   fmt.Sprintf("Company %d", i),
+			Brokerage: "Test Brokerage",
+			Action:    "upgraded by",
+			RatingTo:  "Buy",
+			Time:      time.Now().Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return ratings
+}
+
+// returnedIDs builds the rating_id rows a batch insert returns for the given ratings
+func returnedIDs(ratings ...*domain.StockRating) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"rating_id"})
+	for _, rating := range ratings {
+		rows.AddRow(rating.RatingID)
+	}
+	return rows
+}
+
+func toDriverValues(args []interface{}) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return values
+}
+
+func TestCreateAuditEntry_Success(t *testing.T) {
+	t.Log("Testing CreateAuditEntry: successful creation")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	entry := &domain.AuditEntry{
+		Actor:     "admin@example.com",
+		Action:    "enriched.delete",
+		Target:    "/api/v1/enriched?older_than_days=7",
+		Status:    200,
+		RequestID: "req-1",
+		Timestamp: time.Now(),
+	}
+
+	mock.ExpectExec(`
+		INSERT INTO audit_log (actor, action, target, status, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`).
+		WithArgs(entry.Actor, entry.Action, entry.Target, entry.Status, entry.RequestID, entry.Timestamp).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.CreateAuditEntry(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToWatchlist_Success(t *testing.T) {
+	t.Log("Testing AddToWatchlist: inserts the ticker, ignoring one already watched")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`
+		INSERT INTO watchlists (user_id, ticker)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, ticker) DO NOTHING`).
+		WithArgs("alice", "AAPL").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.AddToWatchlist(context.Background(), "alice", "AAPL")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveFromWatchlist(t *testing.T) {
+	t.Log("Testing RemoveFromWatchlist: deletes the ticker, or reports it was not watched")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	query := "DELETE FROM watchlists WHERE user_id = $1 AND ticker = $2"
+	mock.ExpectExec(query).WithArgs("alice", "AAPL").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("alice", "MSFT").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.RemoveFromWatchlist(context.Background(), "alice", "AAPL"))
+
+	err := repo.RemoveFromWatchlist(context.Background(), "alice", "MSFT")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetWatchlist(t *testing.T) {
+	t.Log("Testing GetWatchlist: returns the user's tickers, or an empty list")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	query := "SELECT ticker FROM watchlists WHERE user_id = $1 ORDER BY ticker"
+	mock.ExpectQuery(query).WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker"}).AddRow("AAPL").AddRow("MSFT"))
+	mock.ExpectQuery(query).WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker"}))
+
+	tickers, err := repo.GetWatchlist(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, tickers)
+
+	tickers, err = repo.GetWatchlist(context.Background(), "bob")
+	require.NoError(t, err)
+	assert.NotNil(t, tickers)
+	assert.Empty(t, tickers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSchemaVersion_Success(t *testing.T) {
+	t.Log("Testing GetSchemaVersion: returns the highest applied version")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+
+	version, err := repo.GetSchemaVersion(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSchemaVersion_MissingTable(t *testing.T) {
+	t.Log("Testing GetSchemaVersion: wraps errors such as a missing schema_migrations table")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").
+		WillReturnError(fmt.Errorf(`relation "schema_migrations" does not exist`))
+
+	_, err := repo.GetSchemaVersion(context.Background())
+
+	var appErr *apperrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrCodeDatabase, appErr.Code)
+}
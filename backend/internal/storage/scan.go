@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"stock-analyzer/internal/domain"
+)
+
+// rowScanner is the Scan method shared by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullableRatingColumns receives the nullable stock_ratings columns during a scan.
+// DECIMAL targets are read through sql.NullFloat64 so NULLs and the driver's
+// textual numeric encoding are handled the same way everywhere.
+type nullableRatingColumns struct {
+	ratingFrom sql.NullString
+	targetFrom sql.NullFloat64
+	targetTo   sql.NullFloat64
+}
+
+// applyTo copies the scanned values onto a rating, leaving NULL columns as nil
+func (n nullableRatingColumns) applyTo(rating *domain.StockRating) {
+	rating.RatingFrom = nullStringPtr(n.ratingFrom)
+	rating.TargetFrom = nullFloat64Ptr(n.targetFrom)
+	rating.TargetTo = nullFloat64Ptr(n.targetTo)
+}
+
+// scanStockRating scans a row whose columns are rating_id, ticker, company, brokerage,
+// action, rating_from, rating_to, target_from, target_to, time, created_at
+func scanStockRating(row rowScanner) (domain.StockRating, error) {
+	var rating domain.StockRating
+	var nulls nullableRatingColumns
+
+	err := row.Scan(
+		&rating.RatingID, &rating.Ticker, &rating.Company, &rating.Brokerage,
+		&rating.Action, &nulls.ratingFrom, &rating.RatingTo, &nulls.targetFrom,
+		&nulls.targetTo, &rating.Time, &rating.CreatedAt)
+	if err != nil {
+		return domain.StockRating{}, err
+	}
+
+	nulls.applyTo(&rating)
+	return rating, nil
+}
+
+// nullStringPtr converts a scanned nullable string to a pointer, nil when NULL
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+// nullFloat64Ptr converts a scanned nullable number to a pointer, nil when NULL
+func nullFloat64Ptr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// unmarshalNullableJSON decodes a JSON/JSONB column into dst. A NULL column (or a
+// JSON null) leaves dst untouched instead of failing to decode.
+func unmarshalNullableJSON(data []byte, dst interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanStockRating_NullableColumns(t *testing.T) {
+	t.Log("Testing scanStockRating: nullable columns map to nil or to their values")
+
+	tests := []struct {
+		name       string
+		ratingFrom interface{}
+		targetFrom interface{}
+		targetTo   interface{}
+		wantFrom   *string
+		wantTFrom  *float64
+		wantTTo    *float64
+	}{
+		{"all null", nil, nil, nil, nil, nil, nil},
+		{"rating_from set", "Hold", nil, nil, stringPtr("Hold"), nil, nil},
+		{"target_from decimal", nil, []byte("150.25"), nil, nil, float64Ptr(150.25), nil},
+		{"target_to decimal", nil, nil, []byte("180.50"), nil, nil, float64Ptr(180.5)},
+		{"targets as floats", "Sell", 90.0, 75.5, stringPtr("Sell"), float64Ptr(90), float64Ptr(75.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupMockDB(t)
+			defer db.Close()
+
+			rows := sqlmock.NewRows([]string{
+				"rating_id", "ticker", "company", "brokerage", "action",
+				"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+			}).AddRow(uuid.New(), "AAPL", "Apple Inc.", "Goldman Sachs", "upgraded by",
+				tt.ratingFrom, "Buy", tt.targetFrom, tt.targetTo, time.Now(), time.Now())
+
+			mock.ExpectQuery(`
+		SELECT rating_id, ticker, company, brokerage, action, rating_from, 
+			   rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings 
+		WHERE ticker = $1 
+		ORDER BY time DESC`).
+				WithArgs("AAPL").
+				WillReturnRows(rows)
+
+			ratings, err := repo.GetStockRatingsByTicker(context.Background(), "AAPL")
+			require.NoError(t, err)
+			require.Len(t, ratings, 1)
+
+			assert.Equal(t, tt.wantFrom, ratings[0].RatingFrom)
+			assert.Equal(t, tt.wantTFrom, ratings[0].TargetFrom)
+			assert.Equal(t, tt.wantTTo, ratings[0].TargetTo)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLatestRatingsByTicker_NullableColumns(t *testing.T) {
+	t.Log("Testing GetLatestRatingsByTicker: nullable columns map to nil or to their values")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"ticker", "rating_id", "company", "brokerage", "action",
+		"rating_from", "rating_to", "target_from", "target_to", "time", "created_at",
+	}).
+		AddRow("AAPL", uuid.New(), "Apple Inc.", "Goldman Sachs", "upgraded by",
+			"Hold", "Buy", []byte("150.00"), []byte("180.75"), time.Now(), time.Now()).
+		AddRow("MSFT", uuid.New(), "Microsoft", "Morgan Stanley", "initiated by",
+			nil, "Overweight", nil, nil, time.Now(), time.Now())
+
+	mock.ExpectQuery(`
+		SELECT DISTINCT ON (ticker) ticker, rating_id, company, brokerage, action, 
+			   rating_from, rating_to, target_from, target_to, time, created_at
+		FROM stock_ratings 
+		ORDER BY ticker, time DESC`).
+		WillReturnRows(rows)
+
+	ratings, err := repo.GetLatestRatingsByTicker(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, stringPtr("Hold"), ratings["AAPL"].RatingFrom)
+	assert.Equal(t, float64Ptr(150), ratings["AAPL"].TargetFrom)
+	assert.Equal(t, float64Ptr(180.75), ratings["AAPL"].TargetTo)
+
+	assert.Nil(t, ratings["MSFT"].RatingFrom)
+	assert.Nil(t, ratings["MSFT"].TargetFrom)
+	assert.Nil(t, ratings["MSFT"].TargetTo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEnrichedStockData_NullJSONColumns(t *testing.T) {
+	t.Log("Testing GetEnrichedStockData: NULL JSONB columns decode to nil maps")
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"ticker", "historical_prices", "news_sentiment", "updated_at"}).
+		AddRow("AAPL", nil, []byte(`{"sentiment_score":0.4}`), time.Now())
+
+	mock.ExpectQuery(`
+		SELECT ticker, historical_prices, news_sentiment, updated_at
+		FROM enriched_stock_data 
+		WHERE ticker = $1`).
+		WithArgs("AAPL").
+		WillReturnRows(rows)
+
+	data, err := repo.GetEnrichedStockData(context.Background(), "AAPL")
+	require.NoError(t, err)
+
+	assert.Nil(t, data.HistoricalPrices)
+	assert.Equal(t, 0.4, data.NewsSentiment["sentiment_score"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnmarshalNullableJSON(t *testing.T) {
+	t.Log("Testing unmarshalNullableJSON: NULL and JSON null leave the target untouched")
+
+	var target map[string]interface{}
+	assert.NoError(t, unmarshalNullableJSON(nil, &target))
+	assert.NoError(t, unmarshalNullableJSON([]byte("null"), &target))
+	assert.Nil(t, target)
+
+	assert.NoError(t, unmarshalNullableJSON([]byte(`{"a":1}`), &target))
+	assert.Equal(t, float64(1), target["a"])
+
+	assert.Error(t, unmarshalNullableJSON([]byte(`{`), &target))
+}
@@ -0,0 +1,10 @@
+-- Track which schema migrations have been applied so deploys can be verified
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO schema_migrations (version)
+VALUES (1), (2), (3), (4), (5)
+ON CONFLICT (version) DO NOTHING;
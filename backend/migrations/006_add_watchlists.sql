@@ -0,0 +1,12 @@
+-- Per-user watchlists, keyed by the user ID the API key authenticates as
+
+CREATE TABLE IF NOT EXISTS watchlists (
+    user_id VARCHAR(255) NOT NULL,
+    ticker VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, ticker)
+);
+
+INSERT INTO schema_migrations (version)
+VALUES (6)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,9 @@
+-- Record which source supplied each rating and that source's priority, so a
+-- higher-priority source can overwrite a conflicting rating from a lower one
+
+ALTER TABLE stock_ratings ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE stock_ratings ADD COLUMN IF NOT EXISTS source_priority INT NOT NULL DEFAULT 0;
+
+INSERT INTO schema_migrations (version)
+VALUES (7)
+ON CONFLICT (version) DO NOTHING;
@@ -5,8 +5,43 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// DefaultLogoBaseURL is the logo URL template used when LOGO_BASE_URL is unset.
+// The {domain} placeholder is replaced with the company's web domain and the
+// %s placeholder with the lowercased stock symbol.
+const DefaultLogoBaseURL = "https://logo.clearbit.com/{domain}"
+
+// DefaultAlpacaBaseURL is the Alpaca market data endpoint used when ALPACA_BASE_URL is unset
+const DefaultAlpacaBaseURL = "https://data.alpaca.markets"
+
+// DefaultEnrichmentMaxTickers is how many tickers a scheduled enrichment run refreshes
+const DefaultEnrichmentMaxTickers = 10
+
+// DefaultIngestionMaxRetries is how many times a failed upstream ingestion
+// request is retried when INGESTION_MAX_RETRIES is unset
+const DefaultIngestionMaxRetries = 3
+
+// DefaultIngestionMaxPageTokenLength caps upstream next_page tokens when
+// INGESTION_MAX_PAGE_TOKEN_LENGTH is unset
+const DefaultIngestionMaxPageTokenLength = 2048
+
+// DefaultIngestionBackoffBaseMs is the backoff ceiling before the first ingestion
+// retry when INGESTION_BACKOFF_BASE_MS is unset; it doubles with each retry
+const DefaultIngestionBackoffBaseMs = 1000
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	StorageBackendPostgres = "postgres"
+	// StorageBackendMemory keeps data in process memory, for local development and tests
+	StorageBackendMemory = "memory"
+)
+
+// DefaultJSONDecimalPlaces is how many decimals prices and scores are rounded
+// to in API responses when JSON_DECIMAL_PLACES is unset.
+const DefaultJSONDecimalPlaces = 2
+
 // Config holds application configuration
 type Config struct {
 	Port        string
@@ -19,11 +54,45 @@ type Config struct {
 	StockAPIToken   string
 	AlpacaAPIKey    string
 	AlpacaAPISecret string
+	AlpacaFeed      string
+	AlpacaBaseURL   string
+	LogoBaseURL     string
 
 	// Application settings
-	MaxWorkers     int
-	RequestTimeout int
-	CacheEnabled   bool
+	StorageBackend           string // StorageBackendPostgres or StorageBackendMemory
+	MaxWorkers               int
+	RequestTimeout           int
+	RouteTimeouts            map[string]time.Duration
+	CacheEnabled             bool
+	NormalizeScores          bool
+	EnrichmentFreshnessHours int
+	EnrichmentMaxTickers     int
+	AuditLogPersist          bool
+	JSONDecimalPlaces        int
+	MaintenanceMode          bool
+	MaintenanceStateFile     string
+	IngestionDiff            bool
+	SnapshotCacheTTLSeconds  int
+	StreamIntervalSeconds    int
+	MaxSnapshotStreams       int
+	IngestPageDelayMS        int
+	IngestionMaxRetries      int
+	IngestionBackoffBaseMs   int
+	IngestionBudgetSeconds   int // 0 leaves ingestion runs unbounded
+	IngestionBatchSize       int // 0 stores each upstream page as one batch
+	IngestionPageTokenMaxLen int
+	IngestionSource          string
+	RatingsCurrency          string // ISO 4217 currency the ratings feed quotes price targets in
+	AutoEnrichNewTickers     bool
+	SourcePriorities         map[string]int // source name -> priority; higher wins on conflict
+	ReadinessRequireIngest   bool
+	HealthCheckCacheSeconds  int // 0 pings the database on every health probe
+	StrictPagination         bool
+	ExportEmptyHeaderOnly    bool
+	UserAPIKeys              map[string]string // user ID -> API key for per-user endpoints
+	APIKey                   string            // shared key for write endpoints; empty leaves them open
+	FrontendURL              string
+	CORSAllowedOrigins       []string // extra origins allowed by CORS, besides FrontendURL
 }
 
 // Load reads configuration from environment variables
@@ -38,24 +107,72 @@ func Load() *Config {
 		StockAPIToken:   getEnv("STOCK_API_TOKEN", ""),
 		AlpacaAPIKey:    getEnv("ALPACA_API_KEY", ""),
 		AlpacaAPISecret: getEnv("ALPACA_API_SECRET", ""),
+		AlpacaFeed:      getEnv("ALPACA_FEED", "iex"),
+		AlpacaBaseURL:   getEnv("ALPACA_BASE_URL", DefaultAlpacaBaseURL),
+		LogoBaseURL:     getEnv("LOGO_BASE_URL", DefaultLogoBaseURL),
 
+		StorageBackend: strings.ToLower(getEnv("STORAGE_BACKEND", StorageBackendPostgres)),
 		MaxWorkers:     getEnvInt("MAX_WORKERS", 10),
 		RequestTimeout: getEnvInt("REQUEST_TIMEOUT_SECONDS", 30),
+		RouteTimeouts:  getEnvDurationMap("ROUTE_TIMEOUTS"),
 		CacheEnabled:   getEnvBool("CACHE_ENABLED", true),
+
+		EnrichmentFreshnessHours: getEnvInt("ENRICHMENT_FRESHNESS_HOURS", 24),
+		EnrichmentMaxTickers:     getEnvInt("ENRICHMENT_MAX_TICKERS", DefaultEnrichmentMaxTickers),
+		AuditLogPersist:          getEnvBool("AUDIT_LOG_PERSIST", false),
+		NormalizeScores:          getEnvBool("NORMALIZE_SCORES", false),
+		JSONDecimalPlaces:        getEnvInt("JSON_DECIMAL_PLACES", DefaultJSONDecimalPlaces),
+		MaintenanceMode:          getEnvBool("MAINTENANCE_MODE", false),
+		MaintenanceStateFile:     getEnv("MAINTENANCE_STATE_FILE", ""),
+		IngestionDiff:            getEnvBool("INGESTION_DIFF", false),
+		SnapshotCacheTTLSeconds:  getEnvInt("SNAPSHOT_CACHE_TTL_SECONDS", 60),
+		StreamIntervalSeconds:    getEnvInt("SNAPSHOT_STREAM_INTERVAL_SECONDS", 5),
+		MaxSnapshotStreams:       getEnvInt("MAX_SNAPSHOT_STREAMS", 50),
+		IngestPageDelayMS:        getEnvInt("INGEST_PAGE_DELAY_MS", 0),
+		IngestionMaxRetries:      getEnvInt("INGESTION_MAX_RETRIES", DefaultIngestionMaxRetries),
+		IngestionBackoffBaseMs:   getEnvInt("INGESTION_BACKOFF_BASE_MS", DefaultIngestionBackoffBaseMs),
+		IngestionBudgetSeconds:   getEnvInt("INGESTION_TIME_BUDGET_SECONDS", 0),
+		IngestionBatchSize:       getEnvInt("INGESTION_BATCH_SIZE", 0),
+		IngestionPageTokenMaxLen: getEnvInt("INGESTION_MAX_PAGE_TOKEN_LENGTH", DefaultIngestionMaxPageTokenLength),
+		IngestionSource:          getEnv("INGESTION_SOURCE", ""),
+		RatingsCurrency:          getEnv("RATINGS_CURRENCY", "USD"),
+		AutoEnrichNewTickers:     getEnvBool("AUTO_ENRICH_NEW_TICKERS", false),
+		SourcePriorities:         getEnvIntMap("SOURCE_PRIORITIES"),
+		ReadinessRequireIngest:   getEnvBool("READINESS_REQUIRE_INGESTION", false),
+		HealthCheckCacheSeconds:  getEnvInt("HEALTH_CHECK_CACHE_SECONDS", 5),
+		StrictPagination:         getEnvBool("STRICT_PAGINATION", false),
+		ExportEmptyHeaderOnly:    getEnvBool("EXPORT_EMPTY_HEADER_ONLY", false),
+		UserAPIKeys:              getEnvStringMap("USER_API_KEYS"),
+		APIKey:                   getEnv("API_KEY", ""),
+		FrontendURL:              getEnv("FRONTEND_URL", ""),
+		CORSAllowedOrigins:       getEnvList("CORS_ALLOWED_ORIGINS"),
 	}
 }
 
 // Validate checks if required configuration is present
 func (c *Config) Validate() error {
+	return c.ValidateRequired(true, true)
+}
+
+// ValidateRequired checks the required settings of the selected dependencies, so
+// entry points that never use the database or Alpaca can skip those checks. The
+// database is only required when the storage backend is Postgres.
+func (c *Config) ValidateRequired(database, alpaca bool) error {
+	switch c.StorageBackend {
+	case "", StorageBackendPostgres, StorageBackendMemory:
+	default:
+		return fmt.Errorf("unsupported STORAGE_BACKEND %q: use %q or %q", c.StorageBackend, StorageBackendPostgres, StorageBackendMemory)
+	}
+
 	var missing []string
 
-	if c.DatabaseURL == "" {
+	if database && c.UsesDatabase() && c.DatabaseURL == "" {
 		missing = append(missing, "DATABASE_URL")
 	}
-	if c.AlpacaAPIKey == "" {
+	if alpaca && c.AlpacaAPIKey == "" {
 		missing = append(missing, "ALPACA_API_KEY")
 	}
-	if c.AlpacaAPISecret == "" {
+	if alpaca && c.AlpacaAPISecret == "" {
 		missing = append(missing, "ALPACA_API_SECRET")
 	}
 
@@ -66,6 +183,11 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// UsesDatabase reports whether data is stored in Postgres rather than in memory
+func (c *Config) UsesDatabase() bool {
+	return c.StorageBackend != StorageBackendMemory
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
@@ -76,6 +198,27 @@ func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
 }
 
+// AllowedOrigins returns the configured origins allowed by CORS: FrontendURL,
+// when set, followed by CORSAllowedOrigins
+func (c *Config) AllowedOrigins() []string {
+	var origins []string
+	if c.FrontendURL != "" {
+		origins = append(origins, c.FrontendURL)
+	}
+	return append(origins, c.CORSAllowedOrigins...)
+}
+
+// MaxRequestTimeout returns the longest timeout any route may run for
+func (c *Config) MaxRequestTimeout() time.Duration {
+	longest := time.Duration(c.RequestTimeout) * time.Second
+	for _, timeout := range c.RouteTimeouts {
+		if timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest
+}
+
 // Utility functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -101,3 +244,75 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvList parses a comma-separated list of values, e.g. "a,b,c".
+// Whitespace around entries is trimmed and empty entries are ignored.
+func getEnvList(key string) []string {
+	var result []string
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// getEnvDurationMap parses a comma-separated list of key=duration pairs,
+// e.g. "/api/v1/stocks/:symbol/price=15s,/api/v1/stocks/:symbol/logo=2s".
+// Malformed entries are ignored.
+func getEnvDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+
+	value := os.Getenv(key)
+	if value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, rawDuration, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || name == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(strings.TrimSpace(rawDuration)); err == nil {
+			result[strings.TrimSpace(name)] = duration
+		}
+	}
+
+	return result
+}
+
+// getEnvStringMap parses a comma-separated list of key=value pairs,
+// e.g. "alice=key-1,bob=key-2". Entries with an empty key or value are ignored.
+func getEnvStringMap(key string) map[string]string {
+	result := make(map[string]string)
+
+	value := os.Getenv(key)
+	if value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+		name, val = strings.TrimSpace(name), strings.TrimSpace(val)
+		if !found || name == "" || val == "" {
+			continue
+		}
+		result[name] = val
+	}
+
+	return result
+}
+
+// getEnvIntMap parses a comma-separated list of key=integer pairs,
+// e.g. "stock_api=10,backfill=5". Malformed entries are ignored.
+func getEnvIntMap(key string) map[string]int {
+	result := make(map[string]int)
+
+	for name, raw := range getEnvStringMap(key) {
+		if value, err := strconv.Atoi(raw); err == nil {
+			result[name] = value
+		}
+	}
+
+	return result
+}
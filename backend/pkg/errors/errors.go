@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // AppError represents an application-specific error
@@ -11,6 +12,8 @@ type AppError struct {
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 	Cause   error  `json:"-"`
+	// RetryAfter tells clients how long to back off before retrying; zero when unknown
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -24,6 +27,13 @@ func (e *AppError) Unwrap() error {
 	return e.Cause
 }
 
+// Is reports whether target is an AppError with the same code, so errors.Is
+// matches predefined errors regardless of message, details or cause
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
 // HTTPStatus returns the appropriate HTTP status code for this error
 func (e *AppError) HTTPStatus() int {
 	switch e.Code {
@@ -37,6 +47,14 @@ func (e *AppError) HTTPStatus() int {
 		return http.StatusConflict
 	case ErrCodeUpstreamAPI:
 		return http.StatusBadGateway
+	case ErrCodeTimeout:
+		return http.StatusGatewayTimeout
+	case ErrCodeUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrCodeNotImplemented:
+		return http.StatusNotImplemented
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
 	case ErrCodeDatabase:
 		return http.StatusInternalServerError
 	default:
@@ -46,13 +64,17 @@ func (e *AppError) HTTPStatus() int {
 
 // Error codes
 const (
-	ErrCodeNotFound     = "NOT_FOUND"
-	ErrCodeValidation   = "VALIDATION_ERROR"
-	ErrCodeUnauthorized = "UNAUTHORIZED"
-	ErrCodeConflict     = "CONFLICT"
-	ErrCodeUpstreamAPI  = "UPSTREAM_API_ERROR"
-	ErrCodeDatabase     = "DATABASE_ERROR"
-	ErrCodeInternal     = "INTERNAL_ERROR"
+	ErrCodeNotFound       = "NOT_FOUND"
+	ErrCodeValidation     = "VALIDATION_ERROR"
+	ErrCodeUnauthorized   = "UNAUTHORIZED"
+	ErrCodeConflict       = "CONFLICT"
+	ErrCodeUpstreamAPI    = "UPSTREAM_API_ERROR"
+	ErrCodeDatabase       = "DATABASE_ERROR"
+	ErrCodeInternal       = "INTERNAL_ERROR"
+	ErrCodeTimeout        = "TIMEOUT"
+	ErrCodeUnavailable    = "SERVICE_UNAVAILABLE"
+	ErrCodeNotImplemented = "NOT_IMPLEMENTED"
+	ErrCodeRateLimited    = "RATE_LIMITED"
 )
 
 // Predefined errors
@@ -76,6 +98,41 @@ var (
 		Code:    ErrCodeDatabase,
 		Message: "Database operation failed",
 	}
+
+	ErrUnauthorized = &AppError{
+		Code:    ErrCodeUnauthorized,
+		Message: "Missing or invalid API key",
+	}
+
+	ErrConflict = &AppError{
+		Code:    ErrCodeConflict,
+		Message: "Request conflicts with an operation in progress",
+	}
+
+	ErrRequestTimeout = &AppError{
+		Code:    ErrCodeTimeout,
+		Message: "request timed out",
+	}
+
+	ErrNotImplemented = &AppError{
+		Code:    ErrCodeNotImplemented,
+		Message: "This feature is not implemented yet",
+	}
+
+	ErrServiceUnavailable = &AppError{
+		Code:    ErrCodeUnavailable,
+		Message: "Service temporarily unavailable",
+	}
+
+	ErrRateLimited = &AppError{
+		Code:    ErrCodeRateLimited,
+		Message: "Too many requests",
+	}
+
+	ErrMaintenanceMode = &AppError{
+		Code:    ErrCodeUnavailable,
+		Message: "Service is in maintenance mode; write operations are paused",
+	}
 )
 
 // New creates a new AppError
@@ -95,12 +152,22 @@ func Wrap(err error, code, message string) *AppError {
 	}
 }
 
+// RateLimited returns a RATE_LIMITED error telling clients to retry after the given delay
+func RateLimited(retryAfter time.Duration) *AppError {
+	return &AppError{
+		Code:       ErrCodeRateLimited,
+		Message:    ErrRateLimited.Message,
+		RetryAfter: retryAfter,
+	}
+}
+
 // WithDetails adds details to an existing AppError
 func (e *AppError) WithDetails(details string) *AppError {
 	return &AppError{
-		Code:    e.Code,
-		Message: e.Message,
-		Details: details,
-		Cause:   e.Cause,
+		Code:       e.Code,
+		Message:    e.Message,
+		Details:    details,
+		Cause:      e.Cause,
+		RetryAfter: e.RetryAfter,
 	}
 }
@@ -0,0 +1,142 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppError_HTTPStatus(t *testing.T) {
+	t.Log("Testing AppError HTTPStatus: each code maps to its HTTP status")
+
+	tests := []struct {
+		code   string
+		status int
+	}{
+		{ErrCodeNotFound, http.StatusNotFound},
+		{ErrCodeValidation, http.StatusBadRequest},
+		{ErrCodeUnauthorized, http.StatusUnauthorized},
+		{ErrCodeConflict, http.StatusConflict},
+		{ErrCodeUpstreamAPI, http.StatusBadGateway},
+		{ErrCodeTimeout, http.StatusGatewayTimeout},
+		{ErrCodeUnavailable, http.StatusServiceUnavailable},
+		{ErrCodeNotImplemented, http.StatusNotImplemented},
+		{ErrCodeRateLimited, http.StatusTooManyRequests},
+		{ErrCodeDatabase, http.StatusInternalServerError},
+		{ErrCodeInternal, http.StatusInternalServerError},
+		{"SOMETHING_ELSE", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, New(tt.code, "message").HTTPStatus(), tt.code)
+	}
+}
+
+func TestErrNotImplemented(t *testing.T) {
+	t.Log("Testing ErrNotImplemented: predefined error reports NOT_IMPLEMENTED and 501")
+
+	assert.Equal(t, "NOT_IMPLEMENTED", ErrCodeNotImplemented)
+	assert.Equal(t, ErrCodeNotImplemented, ErrNotImplemented.Code)
+	assert.Equal(t, http.StatusNotImplemented, ErrNotImplemented.HTTPStatus())
+
+	detailed := ErrNotImplemented.WithDetails("enrichment source")
+	assert.Equal(t, ErrCodeNotImplemented, detailed.Code)
+	assert.Equal(t, "enrichment source", detailed.Details)
+	assert.Empty(t, ErrNotImplemented.Details, "WithDetails must not modify the predefined error")
+
+	// The status survives being wrapped by callers
+	var appErr *AppError
+	wrapped := fmt.Errorf("enrich AAPL: %w", detailed)
+	assert.True(t, errors.As(wrapped, &appErr))
+	assert.Equal(t, http.StatusNotImplemented, appErr.HTTPStatus())
+}
+
+func TestErrRateLimited(t *testing.T) {
+	t.Log("Testing ErrRateLimited: predefined and Retry-After variants map to 429")
+
+	assert.Equal(t, "RATE_LIMITED", ErrCodeRateLimited)
+	assert.Equal(t, http.StatusTooManyRequests, ErrRateLimited.HTTPStatus())
+	assert.Zero(t, ErrRateLimited.RetryAfter)
+
+	limited := RateLimited(30 * time.Second)
+	assert.Equal(t, ErrCodeRateLimited, limited.Code)
+	assert.Equal(t, http.StatusTooManyRequests, limited.HTTPStatus())
+	assert.Equal(t, 30*time.Second, limited.RetryAfter)
+
+	// Adding details keeps the retry hint
+	detailed := limited.WithDetails("upstream quota exhausted")
+	assert.Equal(t, 30*time.Second, detailed.RetryAfter)
+	assert.Equal(t, "upstream quota exhausted", detailed.Details)
+}
+
+func TestErrServiceUnavailable(t *testing.T) {
+	t.Log("Testing ErrServiceUnavailable: predefined error reports SERVICE_UNAVAILABLE and 503")
+
+	assert.Equal(t, "SERVICE_UNAVAILABLE", ErrCodeUnavailable)
+	assert.Equal(t, ErrCodeUnavailable, ErrServiceUnavailable.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, ErrServiceUnavailable.HTTPStatus())
+	assert.Equal(t, http.StatusServiceUnavailable, ErrServiceUnavailable.WithDetails("database unreachable").HTTPStatus())
+	assert.Equal(t, http.StatusServiceUnavailable, ErrMaintenanceMode.HTTPStatus())
+}
+
+func TestWithDetails_PreservesCauseChain(t *testing.T) {
+	t.Log("Testing WithDetails: Unwrap, errors.As and errors.Is work through WithDetails and Wrap")
+
+	root := fmt.Errorf("connection refused")
+	wrapped := Wrap(root, ErrCodeDatabase, "failed to query ratings")
+	detailed := wrapped.WithDetails("ticker AAPL")
+
+	assert.Equal(t, root, detailed.Unwrap())
+	assert.True(t, errors.Is(detailed, root))
+
+	var appErr *AppError
+	outer := fmt.Errorf("loading ratings: %w", detailed)
+	assert.True(t, errors.As(outer, &appErr))
+	assert.Equal(t, ErrCodeDatabase, appErr.Code)
+	assert.Equal(t, "ticker AAPL", appErr.Details)
+	assert.True(t, errors.Is(outer, root))
+
+	// WithDetails returns a copy; the predefined singleton is never modified
+	notFound := ErrNotFound.WithDetails("ticker XYZ not found")
+	assert.NotSame(t, ErrNotFound, notFound)
+	assert.Empty(t, ErrNotFound.Details)
+	assert.Nil(t, notFound.Unwrap())
+}
+
+func TestAppError_IsMatchesByCode(t *testing.T) {
+	t.Log("Testing AppError Is: errors.Is matches predefined errors by code")
+
+	assert.True(t, errors.Is(ErrNotFound.WithDetails("ticker XYZ not found"), ErrNotFound))
+	assert.True(t, errors.Is(New(ErrCodeNotFound, "missing"), ErrNotFound))
+	assert.True(t, errors.Is(Wrap(fmt.Errorf("no rows"), ErrCodeDatabase, "query failed"), ErrDatabaseFailure))
+	assert.True(t, errors.Is(fmt.Errorf("outer: %w", ErrValidationFailure.WithDetails("bad page")), ErrValidationFailure))
+
+	assert.False(t, errors.Is(ErrNotFound.WithDetails("ticker XYZ not found"), ErrValidationFailure))
+	assert.False(t, errors.Is(fmt.Errorf("plain error"), ErrNotFound))
+	assert.False(t, ErrNotFound.Is(fmt.Errorf("NOT_FOUND")))
+}
+
+func TestAppError_IsAcrossNestedChains(t *testing.T) {
+	t.Log("Testing AppError Is: codes match at any depth of wrapped and detailed errors")
+
+	// A repository NOT_FOUND wrapped by a service as UPSTREAM_API_ERROR, then by fmt
+	inner := ErrNotFound.WithDetails("enriched data for AAPL not found")
+	service := Wrap(inner, ErrCodeUpstreamAPI, "enrichment failed").WithDetails("ticker AAPL")
+	outer := fmt.Errorf("handler: %w", service)
+
+	assert.True(t, errors.Is(outer, ErrUpstreamAPIFailure))
+	assert.True(t, errors.Is(outer, ErrNotFound))
+	assert.False(t, errors.Is(outer, ErrDatabaseFailure))
+
+	// errors.As still finds the outermost AppError first
+	var appErr *AppError
+	assert.True(t, errors.As(outer, &appErr))
+	assert.Equal(t, ErrCodeUpstreamAPI, appErr.Code)
+
+	// Retry hints do not affect matching
+	assert.True(t, errors.Is(RateLimited(5*time.Second).WithDetails("slow down"), ErrRateLimited))
+}
@@ -0,0 +1,151 @@
+package locale
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultCurrency is the ISO 4217 code assumed for prices when none is configured
+const DefaultCurrency = "USD"
+
+// Locale describes how numbers, currency amounts, and dates are written for a language
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "en-US"
+	Tag string
+
+	decimalSep string
+	groupSep   string
+	// currencyAfter places the currency symbol after the amount, separated by a space
+	currencyAfter bool
+	// percentSpace separates the percent sign from the number with a space
+	percentSpace bool
+	dateLayout   string
+}
+
+// supported lists the locales with formatting rules; the first entry is the default
+var supported = []Locale{
+	{Tag: "en-US", decimalSep: ".", groupSep: ",", dateLayout: "Jan 2, 2006"},
+	{Tag: "en-GB", decimalSep: ".", groupSep: ",", dateLayout: "2 Jan 2006"},
+	{Tag: "de-DE", decimalSep: ",", groupSep: ".", currencyAfter: true, percentSpace: true, dateLayout: "02.01.2006"},
+	{Tag: "es-ES", decimalSep: ",", groupSep: ".", currencyAfter: true, percentSpace: true, dateLayout: "02/01/2006"},
+	{Tag: "fr-FR", decimalSep: ",", groupSep: " ", currencyAfter: true, percentSpace: true, dateLayout: "02/01/2006"},
+	{Tag: "ja-JP", decimalSep: ".", groupSep: ",", dateLayout: "2006/01/02"},
+	{Tag: "pt-BR", decimalSep: ",", groupSep: ".", dateLayout: "02/01/2006"},
+}
+
+// Default is the locale used when a request does not ask for a supported one
+var Default = supported[0]
+
+var matcher = language.NewMatcher(supportedTags())
+
+func supportedTags() []language.Tag {
+	tags := make([]language.Tag, len(supported))
+	for i, l := range supported {
+		tags[i] = language.MustParse(l.Tag)
+	}
+	return tags
+}
+
+// currencySymbols maps ISO 4217 codes to the symbols written in amounts. Other codes
+// are written as the code itself.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"BRL": "R$",
+}
+
+// Parse picks the supported locale that best matches an Accept-Language header value,
+// falling back to Default when the header is empty, malformed, or matches nothing
+func Parse(acceptLanguage string) Locale {
+	if strings.TrimSpace(acceptLanguage) == "" {
+		return Default
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return supported[index]
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the given locale
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, l)
+}
+
+// FromContext returns the locale stored in ctx, or Default if there is none
+func FromContext(ctx context.Context) Locale {
+	if l, ok := ctx.Value(localeKey{}).(Locale); ok {
+		return l
+	}
+	return Default
+}
+
+// FormatNumber writes v rounded to the given number of decimals with the locale's
+// decimal and grouping separators
+func (l Locale) FormatNumber(v float64, decimals int) string {
+	formatted := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(formatted, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.groupSep)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(l.decimalSep)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// FormatCurrency writes an amount with two decimals and the symbol of the ISO 4217
+// currency code, placed where the locale expects it. The amount is not converted.
+func (l Locale) FormatCurrency(amount float64, currency string) string {
+	symbol, ok := currencySymbols[strings.ToUpper(currency)]
+	if !ok {
+		symbol = strings.ToUpper(currency)
+	}
+
+	number := l.FormatNumber(amount, 2)
+	if l.currencyAfter {
+		return number + " " + symbol
+	}
+	if !ok {
+		// Bare currency codes read better separated from the amount
+		return symbol + " " + number
+	}
+	return symbol + number
+}
+
+// FormatPercent writes a value already expressed in percent, e.g. 12.5 for 12.5%
+func (l Locale) FormatPercent(percent float64, decimals int) string {
+	if l.percentSpace {
+		return l.FormatNumber(percent, decimals) + " %"
+	}
+	return l.FormatNumber(percent, decimals) + "%"
+}
+
+// FormatDate writes the calendar date of t in the locale's short date layout
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.dateLayout)
+}
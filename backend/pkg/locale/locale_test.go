@@ -0,0 +1,72 @@
+package locale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Log("Testing Parse: Accept-Language values resolve to the best supported locale")
+	tests := []struct {
+		header string
+		tag    string
+	}{
+		{"", "en-US"},
+		{"de-DE,de;q=0.9,en;q=0.8", "de-DE"},
+		{"fr", "fr-FR"},
+		{"en-GB", "en-GB"},
+		{"da, fr;q=0.5", "fr-FR"},
+		{"zz", "en-US"},
+		{"not a;;language", "en-US"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.tag, Parse(tt.header).Tag, tt.header)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Log("Testing FromContext: defaults to en-US and returns the stored locale")
+	assert.Equal(t, Default, FromContext(context.Background()))
+
+	ctx := WithLocale(context.Background(), Parse("ja"))
+	assert.Equal(t, "ja-JP", FromContext(ctx).Tag)
+}
+
+func TestLocale_Formatting(t *testing.T) {
+	t.Log("Testing Locale formatting: numbers, currency, percentages and dates follow the locale")
+	date := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tag      string
+		number   string
+		currency string
+		percent  string
+		date     string
+	}{
+		{"en-US", "-1,234,567.89", "$1,234.50", "12.5%", "Feb 3, 2025"},
+		{"en-GB", "-1,234,567.89", "$1,234.50", "12.5%", "3 Feb 2025"},
+		{"de-DE", "-1.234.567,89", "1.234,50 $", "12,5 %", "03.02.2025"},
+		{"fr-FR", "-1 234 567,89", "1 234,50 $", "12,5 %", "03/02/2025"},
+		{"ja-JP", "-1,234,567.89", "$1,234.50", "12.5%", "2025/02/03"},
+	}
+
+	for _, tt := range tests {
+		l := Parse(tt.tag)
+		assert.Equal(t, tt.number, l.FormatNumber(-1234567.891, 2), tt.tag)
+		assert.Equal(t, tt.currency, l.FormatCurrency(1234.5, "USD"), tt.tag)
+		assert.Equal(t, tt.percent, l.FormatPercent(12.5, 1), tt.tag)
+		assert.Equal(t, tt.date, l.FormatDate(date), tt.tag)
+	}
+}
+
+func TestLocale_FormatEdgeCases(t *testing.T) {
+	t.Log("Testing Locale formatting: small values, negative zero and unknown currencies")
+	assert.Equal(t, "999", Default.FormatNumber(999, 0))
+	assert.Equal(t, "0.00", Default.FormatNumber(-0.001, 2))
+	assert.Equal(t, "CHF 12.00", Default.FormatCurrency(12, "chf"))
+	assert.Equal(t, "12,00 CHF", Parse("de").FormatCurrency(12, "CHF"))
+	assert.Equal(t, "€9.99", Default.FormatCurrency(9.99, "EUR"))
+}
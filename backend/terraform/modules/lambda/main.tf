@@ -106,6 +106,14 @@ locals {
       memory_size = 256
       description = "Scheduled tasks handler"
     }
+    
+    enrichment = {
+      handler     = "bootstrap"
+      runtime     = "provided.al2"
+      timeout     = 300
+      memory_size = 512
+      description = "Scheduled stock data enrichment"
+    }
   }
   
   # Environment variables for all Lambda functions
@@ -220,6 +228,31 @@ resource "aws_lambda_permission" "allow_eventbridge" {
   source_arn    = aws_cloudwatch_event_rule.ingestion_schedule.arn
 }
 
+# EventBridge rule for scheduled enrichment
+resource "aws_cloudwatch_event_rule" "enrichment_schedule" {
+  name                = "${var.project_name}-${var.environment}-enrichment-schedule"
+  description         = "Trigger enrichment Lambda function"
+  schedule_expression = "rate(1 day)"
+  
+  tags = var.common_tags
+}
+
+# EventBridge target for enrichment
+resource "aws_cloudwatch_event_target" "enrichment_target" {
+  rule      = aws_cloudwatch_event_rule.enrichment_schedule.name
+  target_id = "EnrichmentLambdaTarget"
+  arn       = aws_lambda_function.functions["enrichment"].arn
+}
+
+# Lambda permission for EventBridge enrichment schedule
+resource "aws_lambda_permission" "allow_eventbridge_enrichment" {
+  statement_id  = "AllowExecutionFromEventBridgeEnrichment"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.functions["enrichment"].function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.enrichment_schedule.arn
+}
+
 # Lambda layer for common dependencies
 resource "aws_lambda_layer_version" "common" {
   filename   = "${path.module}/layer-placeholder.zip"